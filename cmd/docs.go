@@ -185,6 +185,10 @@ func processDocFile(filepath string) (string, error) {
 
 		// Add line to new content with escaped angle brackets
 		escapedLine := strings.ReplaceAll(strings.ReplaceAll(line, "<", "\\<"), ">", "\\>")
+		if !inCodeBlock {
+			// braces of template examples are expressions otherwise
+			escapedLine = strings.ReplaceAll(strings.ReplaceAll(escapedLine, "{", "\\{"), "}", "\\}")
+		}
 
 		// Remove link to the autocomplete command
 		if !strings.HasPrefix(escapedLine, "### SEE ALSO") {
@@ -0,0 +1,107 @@
+/**
+ * Copyright (c) 2013-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+ * located at https://docs.snowplow.io/limited-use-license-1.0
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package dp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/snowplow/snowplow-cli/internal/codegen"
+	snplog "github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/release"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var codegenCmd = &cobra.Command{
+	Use:   "codegen [paths...]",
+	Short: "Generate tracking code from event specifications",
+	Args:  cobra.ArbitraryArgs,
+	Long: `Generates one tracking function per event specification found in the local data products.
+
+Each function takes the event payload and exactly the entities the event specification tracks.
+Entity cardinalities are encoded in the parameter types, so attaching too few or too many entities is a type error.
+Functions are annotated with the event specification triggers and the app ids of the source applications.
+
+Payload types are derived from the local data structures when available, otherwise from the schema defined on the event specification.`,
+	Example: `  $ snowplow-cli dp codegen --output ./src/tracking.ts
+  $ snowplow-cli dp codegen ./data-products --data-structures ./data-structures`,
+	Run: func(cmd *cobra.Command, args []string) {
+		language, _ := cmd.Flags().GetString("language")
+		output, _ := cmd.Flags().GetString("output")
+		dsDirectory, _ := cmd.Flags().GetString("data-structures")
+
+		if language != "typescript" {
+			snplog.LogFatal(errors.New("unsupported language. Was not typescript"))
+		}
+
+		searchPaths := []string{}
+
+		if len(args) == 0 {
+			searchPaths = append(searchPaths, util.DataProductsFolder)
+			slog.Debug("codegen", "msg", fmt.Sprintf("no path provided, using default (./%s)", util.DataProductsFolder))
+		}
+
+		searchPaths = append(searchPaths, args...)
+
+		vars, err := util.TemplateVarsFromCmd(cmd)
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+
+		files, _, err := util.MaybeResourcesfromPaths(searchPaths, vars)
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+
+		local, err := release.ReadLocalDataProducts(context.Background(), files)
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+
+		dataStructures := map[string]model.DataStructure{}
+		if _, err := os.Stat(dsDirectory); err == nil {
+			dataStructures, _, err = util.DataStructuresFromPaths([]string{dsDirectory})
+			if err != nil {
+				snplog.LogFatal(err)
+			}
+		} else {
+			slog.Debug("codegen", "msg", "data structures directory not found, using event specification schemas", "path", dsDirectory)
+		}
+
+		plan := codegen.BuildTrackingPlan(local, dataStructures)
+		source := codegen.RenderTypescript(plan)
+
+		if output == "" {
+			fmt.Print(source)
+			return
+		}
+
+		err = os.WriteFile(output, []byte(source), 0644)
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+
+		slog.Info("codegen", "msg", "wrote", "file", output, "functions", len(plan.Functions))
+	},
+}
+
+func init() {
+	DataProductsCmd.AddCommand(codegenCmd)
+
+	codegenCmd.Flags().String("language", "typescript", "Language of the generated code (typescript)")
+	codegenCmd.Flags().StringP("output", "o", "", "File to write the generated code to. Prints to stdout when not set")
+	codegenCmd.Flags().String("data-structures", util.DataStructuresFolder, "Directory of local data structures used for payload types")
+}
@@ -17,8 +17,10 @@ import (
 	"os"
 
 	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/lint"
 	snplog "github.com/snowplow/snowplow-cli/internal/logging"
 	"github.com/snowplow/snowplow-cli/internal/release"
+	"github.com/snowplow/snowplow-cli/internal/state"
 	"github.com/snowplow/snowplow-cli/internal/util"
 	"github.com/snowplow/snowplow-cli/internal/validation"
 	"github.com/spf13/cobra"
@@ -35,6 +37,15 @@ func runDpWorkflow(cmd *cobra.Command, args []string, action dpAction) {
 	ghOut, _ := cmd.Flags().GetBool("gh-annotate")
 	managedFrom, _ := cmd.Flags().GetString("managed-from")
 	concurrentReq, _ := cmd.Flags().GetInt("concurrency")
+	only, _ := cmd.Flags().GetStringArray("only")
+	exclude, _ := cmd.Flags().GetStringArray("exclude")
+	changedSince, _ := cmd.Flags().GetString("changed-since")
+	overlay, _ := cmd.Flags().GetString("overlay")
+
+	filter, err := release.ParseFilter(only, exclude)
+	if err != nil {
+		snplog.LogFatal(err)
+	}
 
 	if concurrentReq > 10 {
 		concurrentReq = 10
@@ -55,11 +66,31 @@ func runDpWorkflow(cmd *cobra.Command, args []string, action dpAction) {
 
 	searchPaths = append(searchPaths, args...)
 
-	files, err := util.MaybeResourcesfromPaths(searchPaths)
+	vars, err := util.TemplateVarsFromCmd(cmd)
+	if err != nil {
+		snplog.LogFatal(err)
+	}
+
+	files, positions, err := util.MaybeResourcesfromPaths(searchPaths, vars)
 	if err != nil {
 		snplog.LogFatal(err)
 	}
 
+	var patches map[string][]string
+	if overlay != "" {
+		patches, err = util.ApplyOverlay(files, util.OverlayDir(overlay), vars)
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+	}
+
+	if changedSince != "" {
+		filter.Files, err = release.ChangedSince(files, changedSince, patches)
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+	}
+
 	basePath, err := os.Getwd()
 	if err != nil {
 		snplog.LogFatal(err)
@@ -72,14 +103,32 @@ func runDpWorkflow(cmd *cobra.Command, args []string, action dpAction) {
 		snplog.LogFatal(err)
 	}
 
-	changes, err := release.FindChanges(cnx, c, files)
+	changes, err := release.FindChanges(cnx, c, files, filter)
 	if err != nil {
 		snplog.LogFatal(err)
 	}
 
 	release.LockChanged(changes, managedFrom)
 
-	err = validation.Validate(cnx, c, files, searchPaths, basePath, ghOut, false, changes.IdToFileName, concurrentReq)
+	st, err := state.Load(state.Dir)
+	if err != nil {
+		snplog.LogFatal(err)
+	}
+	if err := release.WarnRemoteChanges(changes, st); err != nil {
+		snplog.LogFatal(err)
+	}
+
+	lintConfig, err := validation.LintConfigFromCmd(cmd)
+	if err != nil {
+		snplog.LogFatal(err)
+	}
+
+	err = validation.Validate(cnx, c, files, positions, searchPaths, changes.IdToFileName, filter.Files, validation.DataProductsOptions{
+		BasePath:    basePath,
+		GhOut:       ghOut,
+		Concurrency: concurrentReq,
+		LintConfig:  lintConfig,
+	})
 	if err != nil {
 		snplog.LogFatal(err)
 	}
@@ -88,10 +137,39 @@ func runDpWorkflow(cmd *cobra.Command, args []string, action dpAction) {
 	if err != nil {
 		snplog.LogFatal(err)
 	}
+
+	if !dryRun {
+		err = release.RecordState(cnx, c, changes, st)
+		if err == nil {
+			err = st.Save(state.Dir)
+		}
+		if err != nil {
+			slog.Warn("sync", "msg", "could not record sync state", "error", err)
+		}
+
+		// the synced files as written, without overlay patches, are the
+		// base of later dp pull --merge
+		for f, r := range files {
+			if changes.Skipped(f) {
+				continue
+			}
+			if name, ok := r["resourceName"].(string); ok {
+				if err := util.RecordFileBase(state.Dir, f, name); err != nil {
+					slog.Warn("sync", "msg", "could not record merge base", "file", f, "base", state.BasePath(state.Dir, name), "error", err)
+				}
+			}
+		}
+	}
 }
 
 func addCommonDpFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().Bool("gh-annotate", false, "Output suitable for github workflow annotation (ignores -s)")
 	cmd.PersistentFlags().BoolP("dry-run", "d", false, "Only print planned changes without performing them")
 	cmd.PersistentFlags().IntP("concurrency", "c", 3, "The number of validation requests to perform at once (maximum 10)")
+	cmd.PersistentFlags().StringArray("only", []string{}, "Only apply changes to the selected resources, as kind=pattern where kind is one of dp, domain, owner, es, sa (eg. --only dp=checkout or --only domain=payments)")
+	cmd.PersistentFlags().StringArray("exclude", []string{}, "Leave out changes to the selected resources, same syntax as --only")
+	cmd.PersistentFlags().String("changed-since", "", "Only apply changes from files changed in Git since this ref and the data products depending on them (eg. --changed-since origin/main)")
+	cmd.PersistentFlags().String("overlay", "", "Merge the patch files of this overlay, a directory under ./overlays or a path, into the resources before applying changes")
+	cmd.PersistentFlags().String("lint-config", lint.ConfigFile, "Lint rules configuration file")
+	cmd.PersistentFlags().String("policies", "", "Directory of policy files, overrides policies of the lint config")
 }
@@ -16,6 +16,7 @@ import (
 
 	"github.com/snowplow/snowplow-cli/internal/config"
 	snplog "github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/util"
 	"github.com/spf13/cobra"
 )
 
@@ -40,4 +41,5 @@ var DataProductsCmd = &cobra.Command{
 
 func init() {
 	config.InitConsoleFlags(DataProductsCmd)
+	util.AddTemplateFlags(DataProductsCmd)
 }
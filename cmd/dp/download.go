@@ -16,6 +16,7 @@ import (
 	"github.com/snowplow/snowplow-cli/internal/console"
 	"github.com/snowplow/snowplow-cli/internal/download"
 	snplog "github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/state"
 	"github.com/snowplow/snowplow-cli/internal/util"
 	"github.com/spf13/cobra"
 )
@@ -26,7 +27,11 @@ var downloadCommand = &cobra.Command{
 	Args:  cobra.MaximumNArgs(1),
 	Long: `Downloads the latest versions of all data products, event specs and source apps from Snowplow Console.
 
-If no directory is provided then defaults to 'data-products' in the current directory. Source apps are stored in the nested 'source-apps' directory`,
+If no directory is provided then defaults to 'data-products' in the current directory. Source apps are stored in the nested 'source-apps' directory.
+
+Resources already in the directory are found by resourceName wherever they live.
+Only their changed fields are updated, keeping comments, key order and layout,
+and files of unchanged resources are not rewritten.`,
 	Example: `  $ snowplow-cli dp download
   $ snowplow-cli dp download ./my-data-products`,
 	Run: func(cmd *cobra.Command, args []string) {
@@ -43,7 +48,12 @@ If no directory is provided then defaults to 'data-products' in the current dire
 			dataProductsFolder = args[0]
 		}
 
-		files := util.Files{DataProductsLocation: dataProductsFolder, SourceAppsLocation: util.SourceAppsFolder, ExtentionPreference: format, ImagesLocation: util.ImagesFolder}
+		vars, err := util.TemplateVarsFromCmd(cmd)
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+
+		files := util.Files{DataProductsLocation: dataProductsFolder, SourceAppsLocation: util.SourceAppsFolder, ExtentionPreference: format, ImagesLocation: util.ImagesFolder, StateDir: state.Dir, TemplateVars: vars}
 		cnx := context.Background()
 
 		c, err := console.NewApiClient(cnx, host, apiKeyId, apiKeySecret, org)
@@ -0,0 +1,197 @@
+/**
+ * Copyright (c) 2013-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+ * located at https://docs.snowplow.io/limited-use-license-1.0
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package dp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/snowplow/snowplow-cli/internal/config"
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/download"
+	snplog "github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/release"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var promoteCommand = &cobra.Command{
+	Use:   "promote",
+	Short: "Promote data products, event specs and source apps from one organization to another",
+	Args:  cobra.NoArgs,
+	Long: `Copies data products, event specs and source apps from the organization of one profile to the organization of another, eg: from staging to production.
+
+Profiles are set in the profiles section of the config file (snowplow.yml):
+
+  profiles:
+    staging:
+      org-id: <org id>
+      api-key-id: <api key id>
+      api-key: <api key>
+    prod:
+      org-id: <org id>
+      api-key-id: <api key id>
+      api-key: <api key>
+
+or with SNOWPLOW_CONSOLE_PROFILE_<PROFILE>_<NAME> environment variables, eg: SNOWPLOW_CONSOLE_PROFILE_PROD_API_KEY.
+The host defaults to the --host default.
+
+Resources of the source organization are matched with the target one by resource name, then by name,
+event specs by name within their data product. Trigger images are uploaded to the target organization.
+Event specs of a promoted data product that are missing in the source organization are deleted, as with 'sync'.
+Every data structure referenced by a promoted event spec or source app must be deployed in the target organization.
+
+Use --only and --exclude, as with 'sync', to promote some resources only, and --overlay
+to merge the patch files of an overlay into the promoted resources, eg. to set the app ids
+of the target organization. Patch files name the resources of the source organization.`,
+	Example: `  $ snowplow-cli dp promote --from-profile staging --to-profile prod --dry-run
+  $ snowplow-cli dp promote --from-profile staging --to-profile prod --only domain=payments
+  $ snowplow-cli dp promote --from-profile staging --to-profile prod --release
+  $ snowplow-cli dp promote --from-profile staging --to-profile prod --overlay prod`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := snplog.InitLogging(cmd); err != nil {
+			return err
+		}
+		// credentials come from the profiles, the default ones are not needed
+		if err := config.InitConsoleConfigForSetup(cmd); err != nil {
+			slog.Error("config failure", "error", err)
+			os.Exit(1)
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		fromProfile, _ := cmd.Flags().GetString("from-profile")
+		toProfile, _ := cmd.Flags().GetString("to-profile")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		isRelease, _ := cmd.Flags().GetBool("release")
+		managedFrom, _ := cmd.Flags().GetString("managed-from")
+		only, _ := cmd.Flags().GetStringArray("only")
+		exclude, _ := cmd.Flags().GetStringArray("exclude")
+		overlay, _ := cmd.Flags().GetString("overlay")
+
+		if fromProfile == toProfile {
+			snplog.LogFatal(errors.New("--from-profile and --to-profile must be different profiles"))
+		}
+
+		filter, err := release.ParseFilter(only, exclude)
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+
+		vars, err := util.TemplateVarsFromCmd(cmd)
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+
+		from, err := config.LoadProfile(cmd, fromProfile)
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+		to, err := config.LoadProfile(cmd, toProfile)
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+
+		cnx := context.Background()
+
+		source, err := console.NewApiClient(cnx, from.Host, from.ApiKeyId, from.ApiKey, from.OrgId)
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+		target, err := console.NewApiClient(cnx, to.Host, to.ApiKeyId, to.ApiKey, to.OrgId)
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+
+		dir, err := os.MkdirTemp("", "snowplow-promote-")
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+
+		slog.Info("promote", "msg", "promoting", "from", fromProfile, "to", toProfile)
+		err = promote(cnx, source, target, dir, filter, overlay, vars, managedFrom, dryRun, isRelease)
+		if rmErr := os.RemoveAll(dir); rmErr != nil {
+			slog.Debug("promote", "msg", "could not remove temporary directory", "dir", dir, "error", rmErr)
+		}
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+	},
+}
+
+// promote downloads the resources of source into dir, points them at the
+// matching resources of target and syncs them there. A non empty overlay is
+// merged into the downloaded resources, its templates rendered with vars
+func promote(cnx context.Context, source *console.ApiClient, target *console.ApiClient, dir string, filter release.Filter, overlay string, vars map[string]any, managedFrom string, dryRun bool, isRelease bool) error {
+	files := util.Files{DataProductsLocation: dir, SourceAppsLocation: util.SourceAppsFolder, ExtentionPreference: "yaml", ImagesLocation: util.ImagesFolder}
+	if err := download.DownloadDataProductsAndRelatedResources(files, cnx, source, true); err != nil {
+		return err
+	}
+
+	resources, _, err := util.MaybeResourcesfromPaths([]string{dir}, nil)
+	if err != nil {
+		return err
+	}
+	if overlay != "" {
+		if _, err := util.ApplyOverlay(resources, util.OverlayDir(overlay), vars); err != nil {
+			return err
+		}
+	}
+
+	targetResources, err := console.GetDataProductsAndRelatedResources(cnx, target)
+	if err != nil {
+		return err
+	}
+	release.RemapIds(resources, *targetResources)
+
+	changes, err := release.FindChanges(cnx, target, resources, filter)
+	if err != nil {
+		return err
+	}
+	release.LockChanged(changes, managedFrom)
+
+	schemaResolver, err := console.NewSchemaDeployChecker(cnx, target)
+	if err != nil {
+		return err
+	}
+	missing, err := changes.MissingDataStructures(schemaResolver)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		for _, uri := range missing {
+			slog.Error("promote", "msg", "data structure not deployed in the target organization", "uri", uri)
+		}
+		return fmt.Errorf("%d data structures are not deployed in the target organization, publish them there first", len(missing))
+	}
+
+	if isRelease {
+		return release.Release(cnx, target, changes, dryRun)
+	}
+	return release.Sync(cnx, target, changes, dryRun, false)
+}
+
+func init() {
+	DataProductsCmd.AddCommand(promoteCommand)
+
+	promoteCommand.Flags().String("from-profile", "", "Profile of the organization to promote from")
+	promoteCommand.Flags().String("to-profile", "", "Profile of the organization to promote to")
+	promoteCommand.Flags().BoolP("dry-run", "d", false, "Only print planned changes without performing them")
+	promoteCommand.Flags().Bool("release", false, "Also release the promoted event specs, as with 'release'")
+	promoteCommand.Flags().StringArray("only", []string{}, "Only promote the selected resources, as kind=pattern where kind is one of dp, domain, owner, es, sa")
+	promoteCommand.Flags().StringArray("exclude", []string{}, "Leave out the selected resources, same syntax as --only")
+	promoteCommand.Flags().String("overlay", "", "Merge the patch files of this overlay, a directory under ./overlays or a path, into the promoted resources")
+	_ = promoteCommand.MarkFlagRequired("from-profile")
+	_ = promoteCommand.MarkFlagRequired("to-profile")
+}
@@ -0,0 +1,114 @@
+/**
+ * Copyright (c) 2013-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+ * located at https://docs.snowplow.io/limited-use-license-1.0
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package dp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/download"
+	snplog "github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/state"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var pullCommand = &cobra.Command{
+	Use:   "pull {directory ./data-products}",
+	Short: "Pull changes to data products, event specs and source apps from Snowplow Console",
+	Args:  cobra.MaximumNArgs(1),
+	Long: `Pulls the latest versions of all data products, event specs and source apps from Snowplow Console into the local files.
+
+Without --merge pull behaves like download, the files take the Console version.
+
+With --merge the Console version is merged with the local files, using what was last
+synced by dp download, dp pull, dp sync or dp release as the common base (kept in .snowplow/):
+  - fields changed only in Console or only locally take the changed value
+  - event specifications, source applications and triggers are merged item by item
+  - fields changed differently on both sides are conflicts, the local value is kept
+
+Conflicts are reported and the command fails until they are resolved. A conflicting
+resource keeps its previous base, so the conflict is reported again on the next pull.
+Use --conflict-report to write the conflicts, with the base, local and remote values,
+to a yaml file.`,
+	Example: `  $ snowplow-cli dp pull --merge
+  $ snowplow-cli dp pull --merge --conflict-report conflicts.yaml ./my-data-products`,
+	Run: func(cmd *cobra.Command, args []string) {
+		apiKeyId, _ := cmd.Flags().GetString("api-key-id")
+		apiKeySecret, _ := cmd.Flags().GetString("api-key")
+		host, _ := cmd.Flags().GetString("host")
+		org, _ := cmd.Flags().GetString("org-id")
+		format, _ := cmd.Flags().GetString("output-format")
+		plain, _ := cmd.Flags().GetBool("plain")
+		merge, _ := cmd.Flags().GetBool("merge")
+		conflictReport, _ := cmd.Flags().GetString("conflict-report")
+
+		dataProductsFolder := util.DataProductsFolder
+
+		if len(args) != 0 {
+			dataProductsFolder = args[0]
+		}
+
+		vars, err := util.TemplateVarsFromCmd(cmd)
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+
+		files := util.Files{DataProductsLocation: dataProductsFolder, SourceAppsLocation: util.SourceAppsFolder, ExtentionPreference: format, ImagesLocation: util.ImagesFolder, StateDir: state.Dir, TemplateVars: vars}
+		if merge {
+			files.Merge = &util.MergeReport{}
+		}
+		cnx := context.Background()
+
+		c, err := console.NewApiClient(cnx, host, apiKeyId, apiKeySecret, org)
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+
+		err = download.DownloadDataProductsAndRelatedResources(files, cnx, c, plain)
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+
+		if files.Merge == nil || len(files.Merge.Conflicts) == 0 {
+			return
+		}
+
+		for _, conflict := range files.Merge.Conflicts {
+			slog.Warn("pull", "msg", "conflict", "file", conflict.File, "path", conflict.Path, "local", conflict.Local, "remote", conflict.Remote)
+		}
+
+		if conflictReport != "" {
+			b, err := yaml.Marshal(files.Merge.Conflicts)
+			if err != nil {
+				snplog.LogFatal(err)
+			}
+			if err := os.WriteFile(conflictReport, b, 0644); err != nil {
+				snplog.LogFatal(err)
+			}
+		}
+
+		snplog.LogFatal(fmt.Errorf("%d conflicts between Snowplow Console and the local files, the local values were kept", len(files.Merge.Conflicts)))
+	},
+}
+
+func init() {
+	DataProductsCmd.AddCommand(pullCommand)
+
+	pullCommand.PersistentFlags().StringP("output-format", "f", "yaml", "Format of new files to write. json or yaml are supported")
+	pullCommand.PersistentFlags().Bool("plain", false, "Don't include any comments in yaml files")
+	pullCommand.PersistentFlags().Bool("merge", false, "Merge Console changes with local changes, against the last synced version")
+	pullCommand.PersistentFlags().String("conflict-report", "", "File to write merge conflicts to, as yaml")
+}
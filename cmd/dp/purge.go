@@ -63,7 +63,12 @@ If no directory is provided then defaults to 'data-products' in the current dire
 
 		searchPaths = append(searchPaths, args...)
 
-		files, err := util.MaybeResourcesfromPaths(searchPaths)
+		vars, err := util.TemplateVarsFromCmd(cmd)
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+
+		files, _, err := util.MaybeResourcesfromPaths(searchPaths, vars)
 		if err != nil {
 			snplog.LogFatal(err)
 		}
@@ -27,9 +27,20 @@ This command runs 'sync' first, then releases the event specs.
 Releasing sets the event spec status to 'published' and pushes them to the pipeline, enabling event spec inference.
 Only event specs that exist locally will be released. Each event spec must have an event defined, and all referenced events and entities must be published to the production environment.
 
+Use --only and --exclude to limit the changes to some resources, eg. to release a single data product from a shared repository.
+Selectors are written kind=pattern, where kind is one of dp (data product), domain, owner, es (event spec) or sa (source app), and pattern matches names or resource names with shell style wildcards.
+Event specs and source apps are selected through the data products they belong to. Source apps and data products that a selected change needs to be created are always included.
+With --changed-since <ref> only changes from files changed in Git since ref are applied, together with the data products referencing a changed source app or trigger image. Only these files are validated.
+The lint rules and policies of --lint-config, .snowplow-lint.yml by default, and --policies are checked as with 'validate'.
+Use --overlay <name> to merge the patch files of an overlay into the resources first, as with 'sync'.
+
 If no directory is provided then defaults to 'data-products' in the current directory. Source apps are stored in the nested 'source-apps' directory`,
 	Example: `  $ snowplow-cli dp release
-  $ snowplow-cli dp release ./my-data-products`,
+  $ snowplow-cli dp release ./my-data-products
+  $ snowplow-cli dp release --only dp=checkout
+  $ snowplow-cli dp release --only domain=payments --exclude es="legacy*"
+  $ snowplow-cli dp release --changed-since origin/main
+  $ snowplow-cli dp release --overlay prod`,
 	Run: func(cmd *cobra.Command, args []string) {
 		runDpWorkflow(cmd, args, func(cnx context.Context, c *console.ApiClient, changes *release.DataProductChangeSet, dryRun bool) error {
 			return release.Release(cnx, c, changes, dryRun)
@@ -28,9 +28,43 @@ This command syncs local files with Snowplow Console. Data products, event specs
 Data products and source apps that exist in Snowplow Console are updated in place. Structural changes to event specs (name, event, entities) will instead create a new draft version of the event spec.
 Use 'release' to also release event specs, which changes the status in Snowplow Console to "published" and enables event spec inference.
 
+Use --only and --exclude to limit the changes to some resources, eg. to sync a single data product from a shared repository.
+Selectors are written kind=pattern, where kind is one of dp (data product), domain, owner, es (event spec) or sa (source app), and pattern matches names or resource names with shell style wildcards.
+Event specs and source apps are selected through the data products they belong to. Source apps and data products that a selected change needs to be created are always included.
+With --changed-since <ref> only changes from files changed in Git since ref are applied, together with the data products referencing a changed source app or trigger image. Only these files are validated.
+The lint rules and policies of --lint-config, .snowplow-lint.yml by default, and --policies are checked as with 'validate'.
+
+Use --overlay <name> to sync the same data products to several environments, eg. organizations that only differ in app ids and trigger URLs.
+The patch files of the overlay, in ./overlays/<name> or at the given path, are merged into the resources with the same resourceType and resourceName before syncing:
+
+  apiVersion: v1
+  resourceType: source-application
+  resourceName: <resource name of the source app>
+  data:
+    appIds: [prod-web]
+
+Maps are merged and other values replaced, a null removes a key. Event specs are merged by resourceName and triggers by id, other lists are replaced.
+
+Files ending with .tmpl, eg. checkout.yaml.tmpl, are Go templates (https://pkg.go.dev/text/template) rendered before being read.
+Variables come from vars.yml, or the file set with --vars-file, and --var name=value. Use range to expand one event spec into many,
+and uuid to generate resource names that stay the same between runs from the given inputs:
+
+  eventSpecifications:
+  {{- range .markets }}
+    - resourceName: {{ uuid "checkout" . }}
+      name: Checkout {{ upper . }}
+  {{- end }}
+
+Resources generated by templates are not written by 'download' and 'pull'. 'ds publish', 'plan docs' and 'graph' take the same variable flags.
+
 If no directory is provided then defaults to 'data-products' in the current directory. Source apps are stored in the nested 'source-apps' directory`,
 	Example: `  $ snowplow-cli dp sync
-  $ snowplow-cli dp sync ./my-data-products`,
+  $ snowplow-cli dp sync ./my-data-products
+  $ snowplow-cli dp sync --only dp=checkout
+  $ snowplow-cli dp sync --only domain=payments --exclude es="legacy*"
+  $ snowplow-cli dp sync --changed-since origin/main
+  $ snowplow-cli dp sync --overlay prod
+  $ snowplow-cli dp sync --var market=fr`,
 	Run: func(cmd *cobra.Command, args []string) {
 		runDpWorkflow(cmd, args, func(cnx context.Context, c *console.ApiClient, changes *release.DataProductChangeSet, dryRun bool) error {
 			return release.Sync(cnx, c, changes, dryRun, false)
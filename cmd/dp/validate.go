@@ -14,7 +14,9 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"time"
 
+	"github.com/snowplow/snowplow-cli/internal/lint"
 	snplog "github.com/snowplow/snowplow-cli/internal/logging"
 	"github.com/snowplow/snowplow-cli/internal/util"
 	"github.com/snowplow/snowplow-cli/internal/validation"
@@ -25,9 +27,55 @@ var validateCmd = &cobra.Command{
 	Use:   "validate [paths...]",
 	Short: "Validate data products and source applications with Snowplow Console",
 	Args:  cobra.ArbitraryArgs,
-	Long:  `Sends all data products and source applications from <path> for validation by Snowplow Console.`,
+	Long: `Sends all data products and source applications from <path> for validation by Snowplow Console.
+
+Governance rules are checked when enabled in .snowplow-lint.yml with a severity (info|warning|error):
+  dp-owner-required      Data products have an owner
+  dp-domain-allowed      Data products have a domain from governance.domains
+  dp-description-length  Data products and event specifications have a description of at least governance.minDescriptionLength characters
+  es-trigger-image       Event specifications have at least one trigger with an image
+  es-unique-name         Event specification names are unique across data products
+  sa-app-ids-required    Source applications declare at least one app id
+  sa-description-length  Source applications have a description of at least governance.minDescriptionLength characters
+
+  rules:
+    dp-owner-required: error
+    dp-domain-allowed: error
+  governance:
+    domains: [marketing, checkout]
+    minDescriptionLength: 20
+
+The same rules apply to dp sync and dp release.
+
+Policies are files with a CEL expression (https://cel.dev) evaluated against every
+resource, the CEL string extensions are available. The top level keys of the resource
+file, eg: data, are in scope as well as resource for the whole file. A resource for which the expression is false violates the policy.
+Set the policy directory with --policies or policies: in .snowplow-lint.yml.
+
+  name: event-specs-described
+  resources: [data-product]
+  severity: error
+  expression: data.eventSpecifications.all(es, has(es.description) && size(es.description) > 0)
+  message: every event specification needs a description
+
+With --changed-since <ref> only the files changed in Git since ref, uncommitted and
+untracked changes included, are validated, along with the data products referencing
+a changed source application or trigger image. Compatibility checks of other files
+are skipped, even with --full.
+
+With --overlay <name> the patch files of an overlay are merged into the resources
+before validating them, as with 'sync'.
+
+With --watch the files are validated again whenever they change. Only the changed
+files, and the data products referencing a changed source application, are
+validated again. With --overlay the overlay patch files are watched too. Compatibility
+checks are cached between runs and the report is printed again in place, until
+interrupted with ctrl-c.`,
 	Example: `  $ snowplow-cli dp validate ./data-products ./source-applications
-  $ snowplow-cli dp validate ./src`,
+  $ snowplow-cli dp validate ./src
+  $ snowplow-cli dp validate --watch ./data-products
+  $ snowplow-cli dp validate --changed-since origin/main
+  $ snowplow-cli dp validate --overlay prod`,
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := cmd.Context()
 
@@ -59,4 +107,12 @@ func init() {
 	validateCmd.PersistentFlags().Bool("gh-annotate", false, "Output suitable for github workflow annotation (ignores -s)")
 	validateCmd.PersistentFlags().Bool("full", false, "Perform compatibility check on all files, not only the ones that were changed")
 	validateCmd.PersistentFlags().IntP("concurrency", "c", 3, "The number of validation requests to perform at once (maximum 10)")
+	validateCmd.PersistentFlags().String("lint-config", lint.ConfigFile, "Lint rules configuration file")
+	validateCmd.PersistentFlags().String("policies", "", "Directory of policy files, overrides policies of the lint config")
+	validateCmd.PersistentFlags().String("report-format", "", "Write a validation report (sarif|junit|gitlab-codequality)")
+	validateCmd.PersistentFlags().String("changed-since", "", "Only validate files changed in Git since this ref and the data products depending on them")
+	validateCmd.PersistentFlags().String("overlay", "", "Merge the patch files of this overlay, a directory under ./overlays or a path, into the resources before validating")
+	validateCmd.PersistentFlags().Bool("watch", false, "Validate again whenever files under the paths change")
+	validateCmd.PersistentFlags().Duration("watch-interval", time.Second, "How often to check for changes with --watch")
+	validateCmd.PersistentFlags().String("report-file", "", "File to write the validation report to, - for stdout (default snowplow-cli.sarif|snowplow-cli-junit.xml|gl-code-quality-report.json)")
 }
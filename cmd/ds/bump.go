@@ -0,0 +1,133 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package ds
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/snowplow/snowplow-cli/internal/amend"
+	changesPkg "github.com/snowplow/snowplow-cli/internal/changes"
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"github.com/snowplow/snowplow-cli/internal/validation"
+	"github.com/spf13/cobra"
+)
+
+var bumpCmd = &cobra.Command{
+	Use:   "bump [paths...] default: [./data-structures]",
+	Short: "Bump the version of changed data structures",
+	Args:  cobra.ArbitraryArgs,
+	Long: `Rewrites self.version of data structures which changed compared to the version deployed to your development environment
+
+With --auto the required MODEL-REVISION-ADDITION bump is computed from the schema changes
+by the schema migration service, which needs the organization to have destinations.
+With --to the given part of the deployed version is incremented.
+
+Only the version value is rewritten, comments and key order are preserved. Of several
+local versions of a data structure only the highest is bumped. In the vendor/name/version
+layout the new version is written to a new file next to the previous one.`,
+	Example: `  $ snowplow-cli ds bump --auto
+  $ snowplow-cli ds bump --to addition ./data-structures/com.acme
+  $ snowplow-cli ds bump --auto --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		apiKeyId, _ := cmd.Flags().GetString("api-key-id")
+		apiKeySecret, _ := cmd.Flags().GetString("api-key")
+		host, _ := cmd.Flags().GetString("host")
+		org, _ := cmd.Flags().GetString("org-id")
+		auto, _ := cmd.Flags().GetBool("auto")
+		to, _ := cmd.Flags().GetString("to")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if auto == (to != "") {
+			logging.LogFatal(errors.New("exactly one of --auto or --to is required"))
+		}
+
+		dataStructureFolders := []string{util.DataStructuresFolder}
+		if len(args) > 0 {
+			dataStructureFolders = args
+		}
+
+		dataStructuresLocal, _, err := util.DataStructuresFromPaths(dataStructureFolders)
+		if err != nil {
+			logging.LogFatal(err)
+		}
+
+		errs := validation.ValidateLocalDs(dataStructuresLocal)
+		if len(errs) > 0 {
+			logging.LogFatalMultiple(errs)
+		}
+
+		cnx := context.Background()
+
+		c, err := console.NewApiClient(cnx, host, apiKeyId, apiKeySecret, org)
+		if err != nil {
+			logging.LogFatal(err)
+		}
+
+		remotesListing, err := console.GetDataStructureListing(cnx, c)
+		if err != nil {
+			logging.LogFatal(err)
+		}
+
+		changes, err := changesPkg.GetChanges(dataStructuresLocal, remotesListing, "DEV")
+		if err != nil {
+			logging.LogFatal(err)
+		}
+
+		var bumps []changesPkg.Bump
+		if auto {
+			bumps, err = changesPkg.AutoBumps(cnx, c, changes)
+		} else {
+			upgradeType, typeErr := changesPkg.UpgradeType(to)
+			if typeErr != nil {
+				logging.LogFatal(typeErr)
+			}
+			bumps, err = changesPkg.ExplicitBumps(changes, upgradeType)
+		}
+		if err != nil {
+			logging.LogFatal(err)
+		}
+
+		if len(bumps) == 0 {
+			slog.Info("bump", "msg", "no data structures need a new version")
+			return
+		}
+
+		for _, b := range bumps {
+			attrs := []any{"file", b.FileName, "uri", b.Uri, "deployed", b.RemoteVersion, "from", b.LocalVersion, "to", b.NewVersion}
+			if b.NewFileName != b.FileName {
+				attrs = append(attrs, "new file", b.NewFileName)
+			}
+			slog.Info("bump", attrs...)
+			if dryRun {
+				continue
+			}
+			if err := amend.SetDataStructureVersionInFile(b.FileName, b.NewFileName, b.NewVersion); err != nil {
+				logging.LogFatal(err)
+			}
+		}
+
+		if dryRun {
+			slog.Info("dry run, not rewriting files")
+		}
+	},
+}
+
+func init() {
+	DataStructuresCmd.AddCommand(bumpCmd)
+
+	bumpCmd.Flags().Bool("auto", false, "Compute the required version from the schema changes")
+	bumpCmd.Flags().String("to", "", "Increment the given part of the deployed version (major|revision|addition)")
+	bumpCmd.Flags().BoolP("dry-run", "d", false, "Only print planned version changes without rewriting files")
+}
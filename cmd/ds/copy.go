@@ -0,0 +1,192 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package ds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	changesPkg "github.com/snowplow/snowplow-cli/internal/changes"
+	"github.com/snowplow/snowplow-cli/internal/config"
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/spf13/cobra"
+)
+
+var copyCmd = &cobra.Command{
+	Use:   "copy",
+	Short: "Copy data structures from one organization to another",
+	Args:  cobra.NoArgs,
+	Long: `Copies every version of data structures from the development environment of the organization
+of one profile to the organization of another, eg: from staging to production.
+
+Profiles are set in the profiles section of the config file (snowplow.yml), as with 'dp promote':
+
+  profiles:
+    staging:
+      org-id: <org id>
+      api-key-id: <api key id>
+      api-key: <api key>
+
+or with SNOWPLOW_CONSOLE_PROFILE_<PROFILE>_<NAME> environment variables.
+
+Versions missing in the target organization are published to its development environment
+in ascending order, and to its production environment too with --prod. The 'meta' section
+is copied along. Versions the target organization has with a different content, or that are
+older than its deployed version, are conflicts: they are reported and nothing is copied.
+
+Use --match and --exclude to only copy some data structures, by vendor/name/format/version
+prefix or glob.`,
+	Example: `  $ snowplow-cli ds copy --from-profile staging --to-profile prod --dry-run
+  $ snowplow-cli ds copy --from-profile staging --to-profile prod --match com.example/checkout_
+  $ snowplow-cli ds copy --from-profile staging --to-profile prod --prod`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := logging.InitLogging(cmd); err != nil {
+			return err
+		}
+		// credentials come from the profiles, the default ones are not needed
+		if err := config.InitConsoleConfigForSetup(cmd); err != nil {
+			slog.Error("config failure", "error", err)
+			os.Exit(1)
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		fromProfile, _ := cmd.Flags().GetString("from-profile")
+		toProfile, _ := cmd.Flags().GetString("to-profile")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		toProd, _ := cmd.Flags().GetBool("prod")
+		includeLegacy, _ := cmd.Flags().GetBool("include-legacy")
+		managedFrom, _ := cmd.Flags().GetString("managed-from")
+		match, _ := cmd.Flags().GetStringArray("match")
+		exclude, _ := cmd.Flags().GetStringArray("exclude")
+
+		ctx := cmd.Context()
+
+		if fromProfile == toProfile {
+			logging.LogFatal(errors.New("--from-profile and --to-profile must be different profiles"))
+		}
+
+		filter, err := changesPkg.NewFilter(match, exclude, "")
+		if err != nil {
+			logging.LogFatal(err)
+		}
+
+		from, err := config.LoadProfile(cmd, fromProfile)
+		if err != nil {
+			logging.LogFatal(err)
+		}
+		to, err := config.LoadProfile(cmd, toProfile)
+		if err != nil {
+			logging.LogFatal(err)
+		}
+
+		cnx := context.Background()
+
+		source, err := console.NewApiClient(cnx, from.Host, from.ApiKeyId, from.ApiKey, from.OrgId)
+		if err != nil {
+			logging.LogFatal(err)
+		}
+		target, err := console.NewApiClient(cnx, to.Host, to.ApiKeyId, to.ApiKey, to.OrgId)
+		if err != nil {
+			logging.LogFatal(err)
+		}
+
+		all, err := console.GetAllDataStructureVersions(cnx, source, nil, includeLegacy)
+		if err != nil {
+			logging.LogFatal(err)
+		}
+		var sources []model.DataStructure
+		for _, ds := range all {
+			selected, err := filter.Selects("", ds)
+			if err != nil {
+				logging.LogFatal(err)
+			}
+			if selected {
+				sources = append(sources, ds)
+			}
+		}
+
+		slog.Info("copying data structures", "from", fromProfile, "to", toProfile, "count", len(sources))
+
+		envs := []console.DataStructureEnv{console.DEV}
+		if toProd {
+			envs = append(envs, console.PROD)
+		}
+		for _, env := range envs {
+			err = copyDataStructures(ctx, target, sources, env, managedFrom, dryRun)
+			if err != nil {
+				logging.LogFatal(err)
+			}
+			if dryRun && env == console.DEV && toProd {
+				slog.Info("dry run, production changes depend on the development ones and are not computed")
+				break
+			}
+		}
+		slog.Info("all done!")
+	},
+}
+
+// copyDataStructures publishes the versions of sources the target misses in
+// env, failing on conflicts before any change
+func copyDataStructures(ctx context.Context, target *console.ApiClient, sources []model.DataStructure, env console.DataStructureEnv, managedFrom string, dryRun bool) error {
+	listing, err := console.GetDataStructureListing(ctx, target)
+	if err != nil {
+		return err
+	}
+	targetVersions, err := console.GetAllDataStructureVersions(ctx, target, nil, true)
+	if err != nil {
+		return err
+	}
+
+	changes, conflicts, err := changesPkg.CopyChanges(sources, targetVersions, listing, env)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		for _, c := range conflicts {
+			slog.Error("copy", "msg", "version conflict", "env", env, "uri", c.Uri, "reason", c.Reason)
+		}
+		return fmt.Errorf("%d data structure versions conflict with the target organization", len(conflicts))
+	}
+
+	err = changesPkg.PrintChangeset(ctx, changes)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		slog.Info("dry run, not performing changes", "env", env)
+		return nil
+	}
+	if env == console.PROD {
+		return changesPkg.PerformChangesProd(ctx, target, changes, managedFrom)
+	}
+	return changesPkg.PerformChangesDev(ctx, target, changes, managedFrom)
+}
+
+func init() {
+	DataStructuresCmd.AddCommand(copyCmd)
+
+	copyCmd.Flags().String("from-profile", "", "Profile of the organization to copy from")
+	copyCmd.Flags().String("to-profile", "", "Profile of the organization to copy to")
+	copyCmd.Flags().BoolP("dry-run", "d", false, "Only print planned changes without performing them")
+	copyCmd.Flags().Bool("prod", false, "Also publish the copied versions to the production environment of the target")
+	copyCmd.Flags().Bool("include-legacy", false, "Include legacy data structures with empty schemaType (will be set to 'entity')")
+	copyCmd.Flags().StringArray("match", []string{}, "Only data structures matching vendor/name/format/version, a prefix or a glob (eg. --match com.example/event_name or --match 'com.example.*')")
+	copyCmd.Flags().StringArray("exclude", []string{}, "Leave out data structures matching, same syntax as --match")
+	_ = copyCmd.MarkFlagRequired("from-profile")
+	_ = copyCmd.MarkFlagRequired("to-profile")
+}
@@ -42,3 +42,9 @@ var DataStructuresCmd = &cobra.Command{
 func init() {
 	config.InitConsoleFlags(DataStructuresCmd)
 }
+
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringArray("match", []string{}, "Only data structures matching vendor/name/format/version, a prefix or a glob (eg. --match com.example/event_name or --match 'com.example.*')")
+	cmd.PersistentFlags().StringArray("exclude", []string{}, "Leave out data structures matching, same syntax as --match")
+	cmd.PersistentFlags().String("changed-since", "", "Only data structures in files changed in Git since this ref, uncommitted changes included (eg. --changed-since origin/main)")
+}
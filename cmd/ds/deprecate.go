@@ -0,0 +1,156 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package ds
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/snowplow/snowplow-cli/internal/amend"
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var deprecateCmd = &cobra.Command{
+	Use:   "deprecate <uri>",
+	Short: "Mark a data structure as deprecated",
+	Args:  cobra.ExactArgs(1),
+	Long: `Mark a data structure as deprecated and record the data structure superseding it
+
+Deprecation applies to the data structure as a whole. When --replaced-by points to
+another version of the same data structure only the versions before it are considered deprecated.
+
+The deprecated, replacedBy and sunsetDate metadata is written to the local data structure
+files when they are found and published to the console. Validation of data products and
+source applications warns about references to deprecated data structures.`,
+	Example: `  $ snowplow-cli ds deprecate iglu:com.acme/checkout/jsonschema/1-0-0 --replaced-by iglu:com.acme/checkout_started/jsonschema/1-0-0
+  $ snowplow-cli ds deprecate iglu:com.acme/checkout/jsonschema/1-0-0 --replaced-by iglu:com.acme/checkout/jsonschema/2-0-0 --sunset-date 2027-01-01
+  $ snowplow-cli ds deprecate iglu:com.acme/checkout/jsonschema/1-0-0 --data-structures ./my-data-structures --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		apiKeyId, _ := cmd.Flags().GetString("api-key-id")
+		apiKeySecret, _ := cmd.Flags().GetString("api-key")
+		host, _ := cmd.Flags().GetString("host")
+		org, _ := cmd.Flags().GetString("org-id")
+		managedFrom, _ := cmd.Flags().GetString("managed-from")
+		replacedBy, _ := cmd.Flags().GetString("replaced-by")
+		sunsetDate, _ := cmd.Flags().GetString("sunset-date")
+		dsDirectory, _ := cmd.Flags().GetString("data-structures")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		self, err := model.ParseIgluUri(args[0])
+		if err != nil {
+			logging.LogFatal(err)
+		}
+		if replacedBy != "" {
+			if _, err := model.ParseIgluUri(replacedBy); err != nil {
+				logging.LogFatal(fmt.Errorf("--replaced-by: %w", err))
+			}
+		}
+		if sunsetDate != "" {
+			if _, err := time.Parse(time.DateOnly, sunsetDate); err != nil {
+				logging.LogFatal(fmt.Errorf("--sunset-date must be a date in the format YYYY-MM-DD, got: %s", sunsetDate))
+			}
+		}
+
+		cnx := context.Background()
+
+		c, err := console.NewApiClient(cnx, host, apiKeyId, apiKeySecret, org)
+		if err != nil {
+			logging.LogFatal(err)
+		}
+
+		remotesListing, err := console.GetDataStructureListing(cnx, c)
+		if err != nil {
+			logging.LogFatal(err)
+		}
+
+		var remote *console.ListResponse
+		for i, r := range remotesListing {
+			if r.Vendor == self.Vendor && r.Name == self.Name && r.Format == self.Format {
+				remote = &remotesListing[i]
+				break
+			}
+		}
+		if remote == nil {
+			logging.LogFatal(fmt.Errorf("data structure %s not found, publish it before deprecating", args[0]))
+		}
+
+		ds := model.DataStructure{
+			ApiVersion:   "v1",
+			ResourceType: "data-structure",
+			Meta:         remote.Meta,
+			Data:         map[string]any{"self": map[string]any{"vendor": self.Vendor, "name": self.Name, "format": self.Format, "version": self.Version}},
+		}
+		deprecated := true
+		ds.Meta.Deprecated = &deprecated
+		ds.Meta.ReplacedBy = replacedBy
+		ds.Meta.SunsetDate = sunsetDate
+
+		var files []string
+		if _, err := os.Stat(dsDirectory); err == nil {
+			dataStructuresLocal, _, err := util.DataStructuresFromPaths([]string{dsDirectory})
+			if err != nil {
+				logging.LogFatal(err)
+			}
+			for f, local := range dataStructuresLocal {
+				data, err := local.ParseData()
+				if err != nil {
+					logging.LogFatal(err)
+				}
+				if data.Self.Vendor == self.Vendor && data.Self.Name == self.Name && data.Self.Format == self.Format {
+					files = append(files, f)
+				}
+			}
+			sort.Strings(files)
+		}
+
+		if len(files) == 0 {
+			slog.Warn("deprecate", "msg", "no local file found for data structure, only updating the console", "uri", args[0])
+		}
+
+		for _, f := range files {
+			slog.Info("deprecate", "file", f, "replacedBy", replacedBy, "sunsetDate", sunsetDate)
+			if dryRun {
+				continue
+			}
+			if err := amend.SetDataStructureDeprecationInFile(f, ds.Meta); err != nil {
+				logging.LogFatal(err)
+			}
+		}
+
+		if dryRun {
+			slog.Info("deprecate", "msg", "dry run, not rewriting files or publishing metadata", "uri", args[0])
+			return
+		}
+
+		if err := console.MetadateUpdate(cnx, c, &ds, managedFrom); err != nil {
+			logging.LogFatal(err)
+		}
+
+		slog.Info("deprecate", "msg", "published deprecation", "uri", args[0])
+	},
+}
+
+func init() {
+	DataStructuresCmd.AddCommand(deprecateCmd)
+
+	deprecateCmd.Flags().String("replaced-by", "", "Iglu uri of the data structure superseding this one")
+	deprecateCmd.Flags().String("sunset-date", "", "Date after which the data structure will be retired (YYYY-MM-DD)")
+	deprecateCmd.Flags().String("data-structures", util.DataStructuresFolder, "Directory of local data structure files to update")
+	deprecateCmd.Flags().BoolP("dry-run", "d", false, "Only print planned changes without performing them")
+}
@@ -12,10 +12,13 @@ package ds
 
 import (
 	"context"
+	"log/slog"
 
+	"github.com/snowplow/snowplow-cli/internal/changes"
 	"github.com/snowplow/snowplow-cli/internal/console"
 	"github.com/snowplow/snowplow-cli/internal/download"
 	snplog "github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/state"
 	"github.com/snowplow/snowplow-cli/internal/util"
 	"github.com/spf13/cobra"
 )
@@ -30,7 +33,15 @@ Will retrieve schema contents from your development environment.
 If no directory is provided then defaults to 'data-structures' in the current directory.
 
 By default, data structures with empty schemaType (legacy format) are skipped.
-Use --include-legacy to include them (they will be set to 'entity' schemaType).`,
+Use --include-legacy to include them (they will be set to 'entity' schemaType).
+
+Use --all-versions to download every published version side by side as
+<vendor>/<name>/<version> files. Publishing understands this layout and
+publishes versions missing remotely in ascending order.
+
+Data structures already in the directory are found by vendor and name wherever
+they live. Only their changed fields are updated, keeping comments, key order and
+layout, and files of unchanged data structures are not rewritten.`,
 	Example: `  $ snowplow-cli ds download
 
   Download data structures matching com.example/event_name* or com.example.subdomain*
@@ -40,7 +51,10 @@ Use --include-legacy to include them (they will be set to 'entity' schemaType).`
   $ snowplow-cli ds download --output-format json ./my-data-structures
 
   Include legacy data structures with empty schemaType
-  $ snowplow-cli ds download --include-legacy`,
+  $ snowplow-cli ds download --include-legacy
+
+  Download every version of each data structure
+  $ snowplow-cli ds download --all-versions`,
 	Run: func(cmd *cobra.Command, args []string) {
 		dataStructuresFolder := util.DataStructuresFolder
 		if len(args) > 0 {
@@ -50,7 +64,8 @@ Use --include-legacy to include them (they will be set to 'entity' schemaType).`
 		match, _ := cmd.Flags().GetStringArray("match")
 		includeLegacy, _ := cmd.Flags().GetBool("include-legacy")
 		plain, _ := cmd.Flags().GetBool("plain")
-		files := util.Files{DataStructuresLocation: dataStructuresFolder, ExtentionPreference: format}
+		allVersions, _ := cmd.Flags().GetBool("all-versions")
+		files := util.Files{DataStructuresLocation: dataStructuresFolder, ExtentionPreference: format, VersionedDataStructures: allVersions}
 
 		includeDrafts, _ := cmd.Flags().GetBool("include-drafts")
 
@@ -66,7 +81,12 @@ Use --include-legacy to include them (they will be set to 'entity' schemaType).`
 			snplog.LogFatalMsg("client creation fail", err)
 		}
 
-		allDss, err := download.GetDataStructuresWithOptions(cnx, c, includeDrafts, match, includeLegacy)
+		getDataStructures := download.GetDataStructuresWithOptions
+		if allVersions {
+			getDataStructures = download.GetAllDataStructureVersionsWithOptions
+		}
+
+		allDss, err := getDataStructures(cnx, c, includeDrafts, match, includeLegacy)
 		if err != nil {
 			snplog.LogFatalMsg("data structure fetch failed", err)
 		}
@@ -75,6 +95,11 @@ Use --include-legacy to include them (they will be set to 'entity' schemaType).`
 		if err != nil {
 			snplog.LogFatal(err)
 		}
+
+		err = recordDownloaded(cnx, c, dataStructuresFolder)
+		if err != nil {
+			slog.Warn("download", "msg", "could not record sync state", "error", err)
+		}
 	},
 }
 
@@ -86,4 +111,25 @@ func init() {
 	downloadCmd.PersistentFlags().StringArrayP("match", "", []string{}, "Match for specific data structure to download (eg. --match com.example/event_name or --match com.example)")
 	downloadCmd.PersistentFlags().Bool("include-legacy", false, "Include legacy data structures with empty schemaType (will be set to 'entity')")
 	downloadCmd.PersistentFlags().Bool("plain", false, "Don't include any comments in yaml files")
+	downloadCmd.PersistentFlags().Bool("all-versions", false, "Download every version of each data structure as <vendor>/<name>/<version> files")
+}
+
+// recordDownloaded keeps the downloaded versions in the state file
+func recordDownloaded(cnx context.Context, c *console.ApiClient, dataStructuresFolder string) error {
+	locals, _, err := util.DataStructuresFromPaths([]string{dataStructuresFolder})
+	if err != nil {
+		return err
+	}
+	listing, err := console.GetDataStructureListing(cnx, c)
+	if err != nil {
+		return err
+	}
+	st, err := state.Load(state.Dir)
+	if err != nil {
+		return err
+	}
+	if err := changes.RecordDataStructures(locals, listing, console.DEV, st); err != nil {
+		return err
+	}
+	return st.Save(state.Dir)
 }
@@ -0,0 +1,116 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package ds
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"github.com/snowplow/snowplow-cli/internal/validation"
+	"github.com/snowplow/snowplow-cli/internal/warehouse"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export data structures to other formats",
+	Long: `Export data structures to other formats
+
+Generate warehouse definitions from local data structures.
+`,
+}
+
+var exportSqlCmd = &cobra.Command{
+	Use:   "sql [paths...] default: [./data-structures]",
+	Short: "Generate warehouse DDL and dbt models from data structures",
+	Args:  cobra.ArbitraryArgs,
+	Long: `Generate warehouse DDL from local data structures following the Snowplow loader conventions
+
+For snowflake, bigquery and databricks events are loaded into unstruct_event_<vendor>_<name>_<major>
+columns and entities into contexts_<vendor>_<name>_<major> columns of the events table.
+For redshift and postgres every data structure is loaded into its own <vendor>_<name>_<major> table.
+With several local versions of a major version, the latest one is used.
+
+Use --dbt to also write a dbt sources.yml and one staging model per data structure
+which flattens the data structure properties into individual columns.`,
+	Example: `  $ snowplow-cli ds export sql --dialect snowflake
+  $ snowplow-cli ds export sql --dialect bigquery --schema atomic --output ./ddl.sql
+  $ snowplow-cli ds export sql --dialect redshift --dbt ./models/snowplow ./my-data-structures`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dialectName, _ := cmd.Flags().GetString("dialect")
+		schema, _ := cmd.Flags().GetString("schema")
+		table, _ := cmd.Flags().GetString("table")
+		output, _ := cmd.Flags().GetString("output")
+		dbtDir, _ := cmd.Flags().GetString("dbt")
+
+		dialect, err := warehouse.ParseDialect(dialectName)
+		if err != nil {
+			logging.LogFatal(err)
+		}
+
+		dataStructureFolders := []string{util.DataStructuresFolder}
+		if len(args) > 0 {
+			dataStructureFolders = args
+		}
+
+		dataStructuresLocal, _, err := util.DataStructuresFromPaths(dataStructureFolders)
+		if err != nil {
+			logging.LogFatal(err)
+		}
+
+		errs := validation.ValidateLocalDs(dataStructuresLocal)
+		if len(errs) > 0 {
+			logging.LogFatalMultiple(errs)
+		}
+
+		structures, err := warehouse.StructuresFromLocal(dataStructuresLocal)
+		if err != nil {
+			logging.LogFatal(err)
+		}
+
+		target := warehouse.Target{Dialect: dialect, Schema: schema, Table: table}
+		ddl := warehouse.DDL(target, structures)
+
+		if output == "" {
+			fmt.Print(ddl)
+		} else {
+			err = os.WriteFile(output, []byte(ddl), 0644)
+			if err != nil {
+				logging.LogFatal(err)
+			}
+			slog.Info("export", "msg", "wrote", "file", output, "data structures", len(structures))
+		}
+
+		if dbtDir != "" {
+			err = warehouse.WriteDbtProject(dbtDir, target, structures)
+			if err != nil {
+				logging.LogFatal(err)
+			}
+			slog.Info("export", "msg", "wrote dbt sources and staging models", "directory", dbtDir, "models", len(structures))
+		}
+	},
+}
+
+func init() {
+	DataStructuresCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportSqlCmd)
+
+	exportSqlCmd.Flags().String("dialect", "", "Warehouse dialect (snowflake|bigquery|databricks|redshift|postgres)")
+	exportSqlCmd.Flags().String("schema", "atomic", "Warehouse schema containing the events table")
+	exportSqlCmd.Flags().String("table", "events", "Name of the events table")
+	exportSqlCmd.Flags().StringP("output", "o", "", "File to write the DDL to. Prints to stdout when not set")
+	exportSqlCmd.Flags().String("dbt", "", "Directory to write dbt sources.yml and staging models to")
+
+	_ = exportSqlCmd.MarkFlagRequired("dialect")
+}
@@ -14,10 +14,16 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"os"
+	"path/filepath"
 
+	"github.com/snowplow/snowplow-cli/internal/amend"
 	changesPkg "github.com/snowplow/snowplow-cli/internal/changes"
 	"github.com/snowplow/snowplow-cli/internal/console"
 	"github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/release"
+	"github.com/snowplow/snowplow-cli/internal/state"
 	"github.com/snowplow/snowplow-cli/internal/util"
 	"github.com/snowplow/snowplow-cli/internal/validation"
 	"github.com/spf13/cobra"
@@ -41,10 +47,19 @@ var devCmd = &cobra.Command{
 
 The 'meta' section of a data structure is not versioned within Snowplow Console.
 Changes to it will be published by this command.
+
+When publishing a new version, local and remote event specifications still referencing
+a previous version are listed. Use --bump-references to update the local ones once the new
+versions are published. References to a previous model (major version) are only listed.
+
+Use --match and --exclude to only publish some data structures, by vendor/name/format/version
+prefix or glob, and --changed-since to only publish the ones in files changed in Git since a ref.
 	`,
 	Example: `  $ snowplow-cli ds publish dev
   $ snowplow-cli ds publish dev --dry-run
-  $ snowplow-cli ds publish dev --dry-run ./my-data-structures ./my-other-data-structures`,
+  $ snowplow-cli ds publish dev --dry-run ./my-data-structures ./my-other-data-structures
+  $ snowplow-cli ds publish dev --match com.example/checkout_
+  $ snowplow-cli ds publish dev --changed-since origin/main`,
 
 	Run: func(cmd *cobra.Command, args []string) {
 		apiKeyId, _ := cmd.Flags().GetString("api-key-id")
@@ -54,15 +69,22 @@ Changes to it will be published by this command.
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
 		ghOut, _ := cmd.Flags().GetBool("gh-annotate")
 		managedFrom, _ := cmd.Flags().GetString("managed-from")
+		dpDirectory, _ := cmd.Flags().GetString("data-products")
+		bumpReferences, _ := cmd.Flags().GetBool("bump-references")
 
 		ctx := cmd.Context()
 
+		filter, err := validation.DsFilterFromCmd(cmd)
+		if err != nil {
+			logging.LogFatal(err)
+		}
+
 		dataStructureFolders := []string{util.DataStructuresFolder}
 		if len(args) > 0 {
 			dataStructureFolders = args
 		}
 
-		dataStructuresLocal, err := util.DataStructuresFromPaths(dataStructureFolders)
+		dataStructuresLocal, positions, err := util.DataStructuresFromPaths(dataStructureFolders)
 
 		if err != nil {
 			logging.LogFatal(err)
@@ -91,21 +113,44 @@ Changes to it will be published by this command.
 		if err != nil {
 			logging.LogFatal(err)
 		}
+		changes, err = filter.Apply(changes)
+		if err != nil {
+			logging.LogFatal(err)
+		}
+
+		st, err := state.Load(state.Dir)
+		if err != nil {
+			logging.LogFatal(err)
+		}
+		err = changesPkg.WarnRemoteChanges(changes, remotesListing, "DEV", st)
+		if err != nil {
+			logging.LogFatal(err)
+		}
 
 		err = changesPkg.PrintChangeset(ctx, changes)
 		if err != nil {
 			logging.LogFatal(err)
 		}
 
+		vars, err := util.TemplateVarsFromCmd(cmd)
+		if err != nil {
+			logging.LogFatal(err)
+		}
+
+		staleRefs, err := checkStaleReferences(ctx, c, changes, dpDirectory, vars, bumpReferences)
+		if err != nil {
+			logging.LogFatal(err)
+		}
+
 		vr, err := validation.ValidateChanges(cnx, c, changes)
 		if err != nil {
 			logging.LogFatal(err)
 		}
 
-		vr.Slog(ctx)
+		vr.Slog(ctx, positions)
 
 		if ghOut {
-			vr.GithubAnnotate()
+			vr.GithubAnnotate(positions)
 		}
 
 		if !vr.Valid {
@@ -119,6 +164,15 @@ Changes to it will be published by this command.
 			if err != nil {
 				logging.LogFatal(err)
 			}
+			recordPublished(cnx, c, filter, dataStructuresLocal, "DEV", st)
+		}
+		if bumpReferences {
+			err = bumpStaleReferences(staleRefs, dryRun)
+			if err != nil {
+				logging.LogFatal(err)
+			}
+		}
+		if !dryRun {
 			slog.Info("all done!")
 		}
 	},
@@ -132,11 +186,14 @@ var prodCmd = &cobra.Command{
 
 Data structures found on <path...> which are deployed to your development
 environment will be published to your production environment.
+
+Use --match, --exclude and --changed-since to only publish some of them, as with 'publish dev'.
 	`,
 	Example: `
 	$ snowplow-cli ds publish prod
 	$ snowplow-cli ds publish prod --dry-run
 	$ snowplow-cli ds publish prod --dry-run ./my-data-structures ./my-other-data-structures
+	$ snowplow-cli ds publish prod --match com.example/checkout_
 	`,
 	Run: func(cmd *cobra.Command, args []string) {
 		apiKeyId, _ := cmd.Flags().GetString("api-key-id")
@@ -145,15 +202,22 @@ environment will be published to your production environment.
 		org, _ := cmd.Flags().GetString("org-id")
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
 		managedFrom, _ := cmd.Flags().GetString("managed-from")
+		dpDirectory, _ := cmd.Flags().GetString("data-products")
+		bumpReferences, _ := cmd.Flags().GetBool("bump-references")
 
 		ctx := cmd.Context()
 
+		filter, err := validation.DsFilterFromCmd(cmd)
+		if err != nil {
+			logging.LogFatal(err)
+		}
+
 		dataStructureFolders := []string{util.DataStructuresFolder}
 		if len(args) > 0 {
 			dataStructureFolders = args
 		}
 
-		dataStructuresLocal, err := util.DataStructuresFromPaths(dataStructureFolders)
+		dataStructuresLocal, _, err := util.DataStructuresFromPaths(dataStructureFolders)
 		if err != nil {
 			logging.LogFatal(err)
 		}
@@ -181,11 +245,34 @@ environment will be published to your production environment.
 		if err != nil {
 			logging.LogFatal(err)
 		}
+		changes, err = filter.Apply(changes)
+		if err != nil {
+			logging.LogFatal(err)
+		}
+
+		st, err := state.Load(state.Dir)
+		if err != nil {
+			logging.LogFatal(err)
+		}
+		err = changesPkg.WarnRemoteChanges(changes, remotesListing, "PROD", st)
+		if err != nil {
+			logging.LogFatal(err)
+		}
 
 		err = changesPkg.PrintChangeset(ctx, changes)
 		if err != nil {
 			logging.LogFatal(err)
 		}
+
+		vars, err := util.TemplateVarsFromCmd(cmd)
+		if err != nil {
+			logging.LogFatal(err)
+		}
+
+		staleRefs, err := checkStaleReferences(ctx, c, changes, dpDirectory, vars, bumpReferences)
+		if err != nil {
+			logging.LogFatal(err)
+		}
 		if dryRun {
 			slog.Info("dry run, not performing changes")
 			err = changesPkg.ValidateChangesProd(cnx, c, changes, managedFrom)
@@ -195,6 +282,15 @@ environment will be published to your production environment.
 		if err != nil {
 			logging.LogFatal(err)
 		}
+		if !dryRun {
+			recordPublished(cnx, c, filter, dataStructuresLocal, "PROD", st)
+		}
+		if bumpReferences {
+			err = bumpStaleReferences(staleRefs, dryRun)
+			if err != nil {
+				logging.LogFatal(err)
+			}
+		}
 		slog.Info("all done!")
 	},
 }
@@ -208,4 +304,103 @@ func init() {
 	prodCmd.PersistentFlags().BoolP("dry-run", "d", false, "Only print planned changes without performing them")
 
 	devCmd.PersistentFlags().Bool("gh-annotate", false, "Output suitable for github workflow annotation (ignores -s)")
+
+	for _, c := range []*cobra.Command{devCmd, prodCmd} {
+		c.PersistentFlags().String("data-products", util.DataProductsFolder, "Directory of local data products to check for references to previous versions")
+		c.PersistentFlags().Bool("bump-references", false, "Rewrite local event specification references to previous versions of published data structures")
+		util.AddTemplateFlags(c)
+		addFilterFlags(c)
+	}
+}
+
+// recordPublished keeps the published versions in the state file, a
+// failure is only reported as the publish itself succeeded
+func recordPublished(cnx context.Context, c *console.ApiClient, filter changesPkg.Filter, locals map[string]model.DataStructure, env console.DataStructureEnv, st *state.State) {
+	listing, err := console.GetDataStructureListing(cnx, c)
+	if err == nil {
+		locals, err = filter.Locals(locals)
+	}
+	if err == nil {
+		err = changesPkg.RecordDataStructures(locals, listing, env, st)
+	}
+	if err == nil {
+		err = st.Save(state.Dir)
+	}
+	if err != nil {
+		slog.Warn("publish", "msg", "could not record sync state", "error", err)
+	}
+}
+
+// checkStaleReferences warns about event specifications still pinning the
+// previous version of data structures getting a new version, the templated
+// data products rendered with vars. The local ones are only bumped once the
+// new versions are published, see bumpStaleReferences
+func checkStaleReferences(ctx context.Context, c *console.ApiClient, changes changesPkg.Changes, dpDirectory string, vars map[string]any, bump bool) ([]changesPkg.StaleReference, error) {
+	if len(changes.ToUpdateNewVersion) == 0 {
+		return nil, nil
+	}
+
+	var local *release.LocalFilesRefsResolved
+	if _, err := os.Stat(dpDirectory); err == nil {
+		files, _, err := util.MaybeResourcesfromPaths([]string{dpDirectory}, vars)
+		if err != nil {
+			return nil, err
+		}
+		local, err = release.ReadLocalDataProducts(ctx, files)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		slog.Debug("publish", "msg", "data products directory not found, only checking remote event specifications", "path", dpDirectory)
+	}
+
+	remote, err := console.GetDataProductsAndRelatedResources(ctx, c)
+	if err != nil {
+		slog.Warn("publish", "msg", "could not fetch remote event specifications, only checking local ones", "error", err)
+	}
+
+	refs, err := changesPkg.FindStaleReferences(changes, local, remote)
+	if err != nil {
+		return nil, err
+	}
+	if len(refs) == 0 {
+		return nil, nil
+	}
+
+	changesPkg.PrintStaleReferences(ctx, refs)
+
+	if !bump {
+		slog.Info("publish", "msg", "use --bump-references to update local event specifications to the new versions")
+	}
+	for _, r := range refs {
+		if r.Breaking && r.FileName != "" {
+			slog.Info("publish", "msg", "new model versions are not bumped automatically, update the references by hand", "file", r.FileName, "pinned", r.OldUri, "new", r.NewUri)
+		}
+	}
+
+	return refs, nil
+}
+
+// bumpStaleReferences points the local stale references at the published
+// versions, references across a new model and templates are left alone
+func bumpStaleReferences(refs []changesPkg.StaleReference, dryRun bool) error {
+	for fileName, uris := range changesPkg.ReferenceBumps(refs) {
+		if filepath.Ext(fileName) == util.TemplateExt {
+			slog.Info("publish", "msg", "templates are not bumped automatically, update the references by hand", "file", fileName)
+			continue
+		}
+		if dryRun {
+			slog.Info("dry run, not bumping references", "file", fileName)
+			continue
+		}
+		changed, err := amend.ReplaceSourceUrisInFile(fileName, uris)
+		if err != nil {
+			return err
+		}
+		if changed {
+			slog.Info("bumped references", "file", fileName)
+		}
+	}
+
+	return nil
 }
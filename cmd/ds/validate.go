@@ -12,7 +12,9 @@ package ds
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/snowplow/snowplow-cli/internal/lint"
 	snplog "github.com/snowplow/snowplow-cli/internal/logging"
 	"github.com/snowplow/snowplow-cli/internal/validation"
 	"github.com/spf13/cobra"
@@ -22,9 +24,47 @@ var validateCmd = &cobra.Command{
 	Use:   "validate [paths...] default: [./data-structures]",
 	Short: "Validate data structures with Snowplow Console",
 	Args:  cobra.ArbitraryArgs,
-	Long:  `Sends all data structures from <path> for validation by Snowplow Console.`,
+	Long: `Sends all data structures from <path> for validation by Snowplow Console.
+
+Data structures are also checked against the built in lint rules:
+  property-snake-case       Property names are snake_case
+  property-description      Every property has a description
+  string-max-length         String properties have a maxLength, unless restricted by enum or const
+  no-additional-properties  Objects do not allow additionalProperties: true
+
+Rules are enabled as warnings by default. Set their severity (off|info|warning|error)
+in .snowplow-lint.yml, a rule reported as error fails the validation.
+
+  rules:
+    property-snake-case: error
+    string-max-length: off
+
+In yaml files a "# snowplow-lint-disable [rule ids...]" comment disables rules for the
+commented property. On a top level key it disables them for the whole file.
+
+Policies are files with a CEL expression (https://cel.dev) evaluated against every
+resource, the CEL string extensions are available. The top level keys of the resource
+file, eg: data, are in scope as well as resource for the whole file. A resource for which the expression is false violates the policy.
+Set the policy directory with --policies or policies: in .snowplow-lint.yml.
+
+  name: owner-in-custom-data
+  resources: [data-structure]
+  severity: error
+  expression: has(meta.customData.owner)
+  message: customData.owner is required
+
+Use --match and --exclude to select data structures by vendor/name/format/version,
+with a prefix or a glob matching whole segments, and --changed-since to only select
+data structures in files changed in Git since a ref.
+
+With --watch the data structures are validated again whenever their files change.
+Only the changed data structures are sent to Snowplow Console and the report is
+printed again in place, until interrupted with ctrl-c.`,
 	Example: `  $ snowplow-cli ds validate
-  $ snowplow-cli ds validate ./my-data-structures ./my-other-data-structures`,
+  $ snowplow-cli ds validate ./my-data-structures ./my-other-data-structures
+  $ snowplow-cli ds validate --watch
+  $ snowplow-cli ds validate --match com.example --exclude 'com.example/legacy_*'
+  $ snowplow-cli ds validate --changed-since origin/main`,
 	Run: func(cmd *cobra.Command, args []string) {
 		err := validation.ValidateDataStructuresFromCmd(cmd.Context(), cmd, args)
 		if err != nil {
@@ -37,4 +77,11 @@ func init() {
 	DataStructuresCmd.AddCommand(validateCmd)
 
 	validateCmd.PersistentFlags().Bool("gh-annotate", false, "Output suitable for github workflow annotation (ignores -s)")
+	validateCmd.PersistentFlags().String("lint-config", lint.ConfigFile, "Lint rules configuration file")
+	validateCmd.PersistentFlags().String("policies", "", "Directory of policy files, overrides policies of the lint config")
+	validateCmd.PersistentFlags().String("report-format", "", "Write a validation report (sarif|junit|gitlab-codequality)")
+	validateCmd.PersistentFlags().Bool("watch", false, "Validate again whenever files under the paths change")
+	validateCmd.PersistentFlags().Duration("watch-interval", time.Second, "How often to check for changes with --watch")
+	addFilterFlags(validateCmd)
+	validateCmd.PersistentFlags().String("report-file", "", "File to write the validation report to, - for stdout (default snowplow-cli.sarif|snowplow-cli-junit.xml|gl-code-quality-report.json)")
 }
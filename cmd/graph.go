@@ -0,0 +1,147 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	snplog "github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/plan"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var GraphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Export the tracking plan dependency graph",
+	Long: `Outputs how local data structures, event specifications, data products and source applications reference each other
+
+Data structures referenced but not found locally are included and marked as such.
+
+Use --impact with an iglu uri to list every event specification and data product affected by a change to that data structure.
+Entities of a source application are counted against every event specification the source application emits.`,
+	Example: `  $ snowplow-cli graph --format mermaid
+  $ snowplow-cli graph --format dot | dot -Tsvg > plan.svg
+  $ snowplow-cli graph --impact iglu:com.acme/checkout/jsonschema/1-0-0`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := snplog.InitLogging(cmd); err != nil {
+			snplog.LogFatal(err)
+		}
+
+		dpDirectory, _ := cmd.Flags().GetString("data-products")
+		dsDirectory, _ := cmd.Flags().GetString("data-structures")
+		format, _ := cmd.Flags().GetString("format")
+		impactUri, _ := cmd.Flags().GetString("impact")
+		output, _ := cmd.Flags().GetString("output")
+
+		if format != "dot" && format != "mermaid" && format != "json" {
+			snplog.LogFatal(fmt.Errorf("unsupported format %s. Was not dot, mermaid or json", format))
+		}
+
+		vars, err := util.TemplateVarsFromCmd(cmd)
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+
+		p, err := plan.Load(context.Background(), []string{dpDirectory}, []string{dsDirectory}, vars)
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+
+		graph := plan.BuildGraph(p)
+
+		var out string
+		if impactUri != "" {
+			if _, err := model.ParseIgluUri(impactUri); err != nil {
+				snplog.LogFatal(err)
+			}
+			impact := plan.FindImpact(p, impactUri)
+			switch {
+			case !cmd.Flags().Changed("format"):
+				out = impactText(impact)
+			case format == "json":
+				b, err := json.MarshalIndent(impact, "", "  ")
+				if err != nil {
+					snplog.LogFatal(err)
+				}
+				out = string(b) + "\n"
+			default:
+				out = render(graph.Subgraph(impact.NodeIds()), format)
+			}
+		} else {
+			out = render(graph, format)
+		}
+
+		if output == "" {
+			fmt.Print(out)
+			return
+		}
+		if err := os.WriteFile(output, []byte(out), 0644); err != nil {
+			snplog.LogFatal(err)
+		}
+		slog.Info("graph", "msg", "wrote", "file", output)
+	},
+}
+
+func render(graph *plan.Graph, format string) string {
+	switch format {
+	case "dot":
+		return graph.Dot()
+	case "mermaid":
+		return graph.Mermaid()
+	default:
+		b, err := graph.Json()
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+		return string(b) + "\n"
+	}
+}
+
+func impactText(impact plan.Impact) string {
+	var b strings.Builder
+	if len(impact.EventSpecs) == 0 && len(impact.SourceApps) == 0 {
+		fmt.Fprintf(&b, "%s is not referenced by the tracking plan\n", impact.Uri)
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "Changing %s affects\n", impact.Uri)
+	if len(impact.SourceApps) > 0 {
+		fmt.Fprintf(&b, "\nSource applications:\n")
+		for _, sa := range impact.SourceApps {
+			fmt.Fprintf(&b, "  %s\n", sa)
+		}
+	}
+	fmt.Fprintf(&b, "\nData products:\n")
+	for _, dp := range impact.DataProducts {
+		fmt.Fprintf(&b, "  %s\n", dp)
+	}
+	fmt.Fprintf(&b, "\nEvent specifications:\n")
+	for _, es := range impact.EventSpecs {
+		fmt.Fprintf(&b, "  %s / %s (%s)\n", es.DataProduct, es.EventSpec, es.Reason)
+	}
+	return b.String()
+}
+
+func init() {
+	GraphCmd.Flags().String("data-products", util.DataProductsFolder, "Directory of data products and source applications")
+	GraphCmd.Flags().String("data-structures", util.DataStructuresFolder, "Directory of data structures")
+	GraphCmd.Flags().String("format", "dot", "Output format (dot|mermaid|json)")
+	GraphCmd.Flags().String("impact", "", "Only list what is affected by a change to this data structure iglu uri")
+	GraphCmd.Flags().StringP("output", "o", "", "File to write to. Prints to stdout when not set")
+	util.AddTemplateFlags(GraphCmd)
+}
@@ -0,0 +1,80 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package cmd
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/snowplow/snowplow-cli/internal/lint"
+	"github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/lsp"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var LspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Start a language server for tracking plan files",
+	Long: `Start a language server speaking the Language Server Protocol over stdio
+
+Provides for data structure, data product and source application files:
+  - Diagnostics from the local validators, lint rules and policies
+  - Go to definition for source application $ref and iglu source uris
+  - Completion of local iglu uris and source application paths
+  - Hover showing the properties of data structures
+
+Paths are relative to the workspace root the editor opens. The console
+is not queried, run validate for compatibility and deployment checks.
+Logs are written to stderr.`,
+	Example: `
+  Neovim:
+  vim.lsp.start({
+    name = "snowplow-cli",
+    cmd = { "snowplow-cli", "lsp" },
+    root_dir = vim.fs.root(0, { "data-structures", "data-products" }),
+  })
+
+  Helix '<workspace>/.helix/languages.toml':
+  [language-server.snowplow-cli]
+  command = "snowplow-cli"
+  args = ["lsp"]
+
+  [[language]]
+  name = "yaml"
+  language-servers = ["snowplow-cli", "yaml-language-server"]`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		level := slog.LevelInfo
+		if debug, _ := cmd.Flags().GetBool("debug"); debug {
+			level = slog.LevelDebug
+		}
+		logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+		slog.SetDefault(logger)
+
+		dsDir, _ := cmd.Flags().GetString("data-structures")
+		dpDir, _ := cmd.Flags().GetString("data-products")
+		lintConfig, _ := cmd.Flags().GetString("lint-config")
+
+		ctx := logging.ContextWithLogger(cmd.Context(), logger)
+
+		return lsp.Serve(ctx, os.Stdin, os.Stdout, lsp.Options{
+			DataStructuresDir: dsDir,
+			DataProductsDir:   dpDir,
+			LintConfig:        lintConfig,
+		})
+	},
+}
+
+func init() {
+	LspCmd.Flags().String("data-structures", util.DataStructuresFolder, "Directory of local data structures")
+	LspCmd.Flags().String("data-products", util.DataProductsFolder, "Directory of local data products and source applications")
+	LspCmd.Flags().String("lint-config", lint.ConfigFile, "Lint rules configuration file")
+}
@@ -0,0 +1,75 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package plan
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	snplog "github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/plan"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var docsCmd = &cobra.Command{
+	Use:   "docs <out-dir>",
+	Short: "Generate a documentation site for the tracking plan",
+	Args:  cobra.ExactArgs(1),
+	Long: `Renders the local data products, event specifications, source applications and data structures
+into a static documentation site.
+
+Pages are cross-linked: event specifications link to the data structures they reference,
+data structures list every event specification and source application using them and
+source applications list the event specifications they emit.
+Trigger images are copied alongside the pages and a search index is generated.`,
+	Example: `  $ snowplow-cli plan docs ./site
+  $ snowplow-cli plan docs ./docs --format markdown --data-products ./dps --data-structures ./dss`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dpDirectory, _ := cmd.Flags().GetString("data-products")
+		dsDirectory, _ := cmd.Flags().GetString("data-structures")
+		format, _ := cmd.Flags().GetString("format")
+
+		docsFormat := plan.DocsFormat(format)
+		if docsFormat != plan.DocsHtml && docsFormat != plan.DocsMarkdown {
+			snplog.LogFatal(errors.New("unsupported format. Was not html or markdown"))
+		}
+
+		vars, err := util.TemplateVarsFromCmd(cmd)
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+
+		p, err := plan.Load(context.Background(), []string{dpDirectory}, []string{dsDirectory}, vars)
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+
+		err = plan.WriteDocs(p, args[0], docsFormat)
+		if err != nil {
+			snplog.LogFatal(err)
+		}
+
+		slog.Info("docs", "msg", "wrote tracking plan documentation", "directory", args[0],
+			"data products", len(p.DataProducts),
+			"source applications", len(p.SourceApps),
+			"data structures", len(p.DataStructures),
+		)
+	},
+}
+
+func init() {
+	docsCmd.Flags().String("data-products", util.DataProductsFolder, "Directory of data products and source applications")
+	docsCmd.Flags().String("data-structures", util.DataStructuresFolder, "Directory of data structures")
+	docsCmd.Flags().String("format", string(plan.DocsHtml), "Output format (html|markdown)")
+	util.AddTemplateFlags(docsCmd)
+}
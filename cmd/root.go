@@ -16,6 +16,7 @@ import (
 	"github.com/snowplow/snowplow-cli/cmd/dp"
 	"github.com/snowplow/snowplow-cli/cmd/ds"
 	"github.com/snowplow/snowplow-cli/cmd/events"
+	"github.com/snowplow/snowplow-cli/cmd/plan"
 	"github.com/snowplow/snowplow-cli/internal/util"
 	"github.com/spf13/cobra"
 )
@@ -63,7 +64,10 @@ Then on:
 	RootCmd.AddCommand(ds.DataStructuresCmd)
 	RootCmd.AddCommand(dp.DataProductsCmd)
 	RootCmd.AddCommand(McpCmd)
+	RootCmd.AddCommand(LspCmd)
 	RootCmd.AddCommand(SetupCmd)
 	RootCmd.AddCommand(StatusCmd)
 	RootCmd.AddCommand(events.EventsCmd)
+	RootCmd.AddCommand(plan.PlanCmd)
+	RootCmd.AddCommand(GraphCmd)
 }
@@ -266,3 +266,172 @@ func Test_AddJson_Existing(t *testing.T) {
 		t.Fatalf("Result of adding the event specs is not expected, expected:\n%s\nactual:\n%s", expected, string(res))
 	}
 }
+
+func Test_ReplaceSourceUris(t *testing.T) {
+	replacements := map[string]string{
+		"iglu:com.acme/checkout/jsonschema/1-0-0": "iglu:com.acme/checkout/jsonschema/1-1-0",
+	}
+
+	yaml := `data:
+  eventSpecifications:
+    - name: checkout # keep me
+      event:
+        source: iglu:com.acme/checkout/jsonschema/1-0-0
+      entities:
+        tracked:
+          - source: "iglu:com.acme/checkout/jsonschema/1-0-0"
+          - source: iglu:com.acme/checkout/jsonschema/1-0-0-extra
+      description: iglu:com.acme/checkout/jsonschema/1-0-0
+      # source: iglu:com.acme/checkout/jsonschema/1-0-0
+      notes: |
+        source: iglu:com.acme/checkout/jsonschema/1-0-0
+`
+	expectedYaml := `data:
+  eventSpecifications:
+    - name: checkout # keep me
+      event:
+        source: iglu:com.acme/checkout/jsonschema/1-1-0
+      entities:
+        tracked:
+          - source: "iglu:com.acme/checkout/jsonschema/1-1-0"
+          - source: iglu:com.acme/checkout/jsonschema/1-0-0-extra
+      description: iglu:com.acme/checkout/jsonschema/1-0-0
+      # source: iglu:com.acme/checkout/jsonschema/1-0-0
+      notes: |
+        source: iglu:com.acme/checkout/jsonschema/1-0-0
+`
+	res, err := ReplaceSourceUris([]byte(yaml), replacements)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res) != expectedYaml {
+		t.Fatalf("unexpected yaml\n%s", res)
+	}
+
+	json := `{"event": {"source": "iglu:com.acme/checkout/jsonschema/1-0-0"}, "entities": {"tracked": [{"source":"iglu:com.acme/checkout/jsonschema/1-0-0","minCardinality": 1}]}}`
+	expectedJson := `{"event": {"source": "iglu:com.acme/checkout/jsonschema/1-1-0"}, "entities": {"tracked": [{"source":"iglu:com.acme/checkout/jsonschema/1-1-0","minCardinality": 1}]}}`
+	res, err = ReplaceSourceUris([]byte(json), replacements)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res) != expectedJson {
+		t.Fatalf("unexpected json\n%s", res)
+	}
+}
+
+func Test_SetDataStructureVersionYaml(t *testing.T) {
+	original := `apiVersion: v1 # keep
+resourceType: data-structure
+meta:
+  hidden: false
+  schemaType: event
+data:
+  $schema: http://iglucentral.com/schemas/com.snowplowanalytics.self-desc/schema/jsonschema/1-0-0#
+  self:
+    vendor: com.acme
+    version: "1-0-0" # the version
+    name: checkout
+    format: jsonschema
+  type: object
+`
+	expected := `apiVersion: v1 # keep
+resourceType: data-structure
+meta:
+  hidden: false
+  schemaType: event
+data:
+  $schema: http://iglucentral.com/schemas/com.snowplowanalytics.self-desc/schema/jsonschema/1-0-0#
+  self:
+    vendor: com.acme
+    version: "2-0-0" # the version
+    name: checkout
+    format: jsonschema
+  type: object
+`
+	res, err := SetDataStructureVersion([]byte(original), "checkout.yaml", "2-0-0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res) != expected {
+		t.Fatalf("unexpected yaml\n%s", res)
+	}
+}
+
+func Test_SetDataStructureVersionJson(t *testing.T) {
+	original := `{
+  "apiVersion": "v1",
+  "data": {
+    "self": {"vendor": "com.acme", "name": "checkout", "format": "jsonschema", "version": "1-0-0"}
+  }
+}`
+	expected := `{
+  "apiVersion": "v1",
+  "data": {
+    "self": {"vendor": "com.acme", "name": "checkout", "format": "jsonschema", "version": "1-0-1"}
+  }
+}`
+	res, err := SetDataStructureVersion([]byte(original), "checkout.json", "1-0-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res) != expected {
+		t.Fatalf("unexpected json\n%s", res)
+	}
+}
+
+func Test_SetDataStructureDeprecationYaml(t *testing.T) {
+	deprecated := true
+	original := `apiVersion: v1
+resourceType: data-structure
+meta:
+  hidden: false
+  schemaType: event # an event
+  customData: {}
+  deprecated: false
+data:
+  self:
+    vendor: com.acme
+    name: checkout
+    format: jsonschema
+    version: 1-0-0
+`
+	expected := `apiVersion: v1
+resourceType: data-structure
+meta:
+  hidden: false
+  schemaType: event # an event
+  customData: {}
+  deprecated: true
+  replacedBy: iglu:com.acme/checkout/jsonschema/2-0-0
+  sunsetDate: "2027-01-01"
+data:
+  self:
+    vendor: com.acme
+    name: checkout
+    format: jsonschema
+    version: 1-0-0
+`
+	meta := model.DataStructureMeta{Deprecated: &deprecated, ReplacedBy: "iglu:com.acme/checkout/jsonschema/2-0-0", SunsetDate: "2027-01-01"}
+	res, err := SetDataStructureDeprecation([]byte(original), "checkout.yaml", meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res) != expected {
+		t.Fatalf("unexpected yaml\n%s", res)
+	}
+}
+
+func Test_SetDataStructureDeprecationJson(t *testing.T) {
+	deprecated := true
+	original := `{"meta": {"hidden": false}, "data": {}}`
+	expected := `{"meta": {"hidden": false,"deprecated":true,"replacedBy":"iglu:com.acme/checkout/jsonschema/2-0-0"}, "data": {}}`
+
+	meta := model.DataStructureMeta{Deprecated: &deprecated, ReplacedBy: "iglu:com.acme/checkout/jsonschema/2-0-0"}
+	res, err := SetDataStructureDeprecation([]byte(original), "checkout.json", meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res) != expected {
+		t.Fatalf("unexpected json\n%s", res)
+	}
+}
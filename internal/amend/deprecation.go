@@ -0,0 +1,146 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package amend
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/tidwall/sjson"
+)
+
+type metaField struct {
+	key   string
+	value any
+}
+
+func deprecationFields(meta model.DataStructureMeta) []metaField {
+	fields := []metaField{{"deprecated", meta.IsDeprecated()}}
+	if meta.ReplacedBy != "" {
+		fields = append(fields, metaField{"replacedBy", meta.ReplacedBy})
+	}
+	if meta.SunsetDate != "" {
+		fields = append(fields, metaField{"sunsetDate", meta.SunsetDate})
+	}
+	return fields
+}
+
+// SetDataStructureDeprecation writes the deprecated, replacedBy and
+// sunsetDate fields of meta into the meta of a data structure file
+func SetDataStructureDeprecation(file []byte, fileName string, meta model.DataStructureMeta) ([]byte, error) {
+	fields := deprecationFields(meta)
+	if strings.HasSuffix(fileName, ".yaml") || strings.HasSuffix(fileName, "yml") {
+		return setYamlMeta(file, fields)
+	} else if strings.HasSuffix(fileName, ".json") {
+		var err error
+		for _, f := range fields {
+			file, err = sjson.SetBytes(file, "meta."+f.key, f.value)
+			if err != nil {
+				return []byte{}, err
+			}
+		}
+		return file, nil
+	}
+	return []byte{}, fmt.Errorf("file has not recognized extension %s. Recognized are .yaml, .yml, .json", fileName)
+}
+
+func setYamlMeta(file []byte, fields []metaField) ([]byte, error) {
+	comments := yaml.CommentMap{}
+	var node ast.Node
+	if err := yaml.UnmarshalWithOptions(file, &node, yaml.UseOrderedMap(), yaml.CommentToMap(comments)); err != nil {
+		return []byte{}, fmt.Errorf("failed to parse data structure YAML: %w", err)
+	}
+
+	mappingNode, ok := node.(*ast.MappingNode)
+	if !ok {
+		return []byte{}, fmt.Errorf("root node is not a mapping")
+	}
+
+	var metaValue ast.Node
+	for _, value := range mappingNode.Values {
+		if value.Key.String() == "meta" {
+			metaValue = value.Value
+			break
+		}
+	}
+	if metaValue == nil {
+		return []byte{}, fmt.Errorf("'meta' key not found")
+	}
+
+	metaMappingNode, ok := metaValue.(*ast.MappingNode)
+	if !ok {
+		return []byte{}, fmt.Errorf("meta value is not a mapping")
+	}
+
+	for _, f := range fields {
+		fieldBytes, err := yaml.Marshal(map[string]any{f.key: f.value})
+		if err != nil {
+			return []byte{}, fmt.Errorf("failed to marshal %s: %w", f.key, err)
+		}
+
+		var fieldNode ast.Node
+		if err := yaml.Unmarshal(fieldBytes, &fieldNode); err != nil {
+			return []byte{}, fmt.Errorf("failed to parse %s YAML: %w", f.key, err)
+		}
+
+		fieldMapping, ok := fieldNode.(*ast.MappingNode)
+		if !ok || len(fieldMapping.Values) != 1 {
+			return []byte{}, fmt.Errorf("failed to build %s YAML", f.key)
+		}
+		fieldValue := fieldMapping.Values[0]
+
+		replaced := false
+		for _, value := range metaMappingNode.Values {
+			if value.Key.String() == f.key {
+				value.Value = fieldValue.Value
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			// align the new key with the existing meta keys
+			if len(metaMappingNode.Values) > 0 {
+				indent := metaMappingNode.Values[0].Key.GetToken().Position.Column
+				fieldValue.AddColumn(indent - fieldValue.Key.GetToken().Position.Column)
+			}
+			metaMappingNode.Values = append(metaMappingNode.Values, fieldValue)
+		}
+	}
+
+	output, err := yaml.MarshalWithOptions(node, yaml.WithComment(comments), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return []byte{}, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+
+	return output, nil
+}
+
+func SetDataStructureDeprecationInFile(fileName string, meta model.DataStructureMeta) error {
+	file, err := os.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	output, err := SetDataStructureDeprecation(file, fileName, meta)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(fileName, output, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,268 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package amend
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	googleyaml "gopkg.in/yaml.v3"
+)
+
+// change sets or removes key of the mapping found at path, path holds
+// mapping keys and sequence indices
+type change struct {
+	path   []any
+	key    string
+	remove bool
+}
+
+// MergeResource applies to file the fields of fresh, the same resource as
+// download would write it, that differ. Comments, key order and formatting
+// of everything else in file are kept, file is returned as is when nothing
+// changed
+func MergeResource(file []byte, fresh []byte, fileName string) ([]byte, error) {
+	current, err := Normalize(file)
+	if err != nil {
+		return []byte{}, fmt.Errorf("failed to parse %s: %w", fileName, err)
+	}
+	desired, err := Normalize(fresh)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	if reflect.DeepEqual(current, desired) {
+		return file, nil
+	}
+
+	currentMap, okCurrent := current.(map[string]any)
+	desiredMap, okDesired := desired.(map[string]any)
+	if !okCurrent || !okDesired {
+		return fresh, nil
+	}
+	changes := diffMappings(nil, currentMap, desiredMap)
+
+	if strings.HasSuffix(fileName, ".yaml") || strings.HasSuffix(fileName, "yml") {
+		return mergeYaml(file, fresh, changes)
+	} else if strings.HasSuffix(fileName, ".json") {
+		return mergeJson(file, fresh, changes)
+	}
+	return []byte{}, fmt.Errorf("file has not recognized extension %s. Recognized are .yaml, .yml, .json", fileName)
+}
+
+// Normalize decodes yaml or json to the values encoding/json would produce
+// so numbers compare equal whichever format they were read from
+func Normalize(file []byte) (any, error) {
+	var v any
+	if err := googleyaml.Unmarshal(file, &v); err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var result any
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func diffMappings(path []any, current map[string]any, desired map[string]any) []change {
+	var result []change
+
+	keys := make([]string, 0, len(desired))
+	for k := range desired {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		cv, ok := current[k]
+		dv := desired[k]
+		if ok && reflect.DeepEqual(cv, dv) {
+			continue
+		}
+		keyPath := append(slices.Clone(path), k)
+
+		cm, cIsMap := cv.(map[string]any)
+		dm, dIsMap := dv.(map[string]any)
+		if ok && cIsMap && dIsMap {
+			result = append(result, diffMappings(keyPath, cm, dm)...)
+			continue
+		}
+
+		// sequences of mappings keeping their length, eg: event
+		// specifications, are merged item by item
+		if cs, ds := mappings(cv), mappings(dv); ok && cs != nil && ds != nil && len(cs) == len(ds) {
+			for i := range ds {
+				result = append(result, diffMappings(append(slices.Clone(keyPath), i), cs[i], ds[i])...)
+			}
+			continue
+		}
+
+		result = append(result, change{path: path, key: k})
+	}
+
+	var removed []string
+	for k := range current {
+		if _, ok := desired[k]; !ok {
+			removed = append(removed, k)
+		}
+	}
+	sort.Strings(removed)
+	for _, k := range removed {
+		result = append(result, change{path: path, key: k, remove: true})
+	}
+
+	return result
+}
+
+func mappings(v any) []map[string]any {
+	s, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	result := []map[string]any{}
+	for _, i := range s {
+		m, ok := i.(map[string]any)
+		if !ok {
+			return nil
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
+func mergeYaml(file []byte, fresh []byte, changes []change) ([]byte, error) {
+	comments := yaml.CommentMap{}
+	var node ast.Node
+	if err := yaml.UnmarshalWithOptions(file, &node, yaml.UseOrderedMap(), yaml.CommentToMap(comments)); err != nil {
+		return []byte{}, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	var freshNode ast.Node
+	if err := yaml.UnmarshalWithOptions(fresh, &freshNode, yaml.UseOrderedMap()); err != nil {
+		return []byte{}, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	for _, c := range changes {
+		target, err := mappingAt(node, c.path)
+		if err != nil {
+			return []byte{}, err
+		}
+
+		if c.remove {
+			target.Values = slices.DeleteFunc(target.Values, func(v *ast.MappingValueNode) bool {
+				return v.Key.String() == c.key
+			})
+			continue
+		}
+
+		source, err := mappingAt(freshNode, c.path)
+		if err != nil {
+			return []byte{}, err
+		}
+		value := valueOf(source, c.key)
+		if value == nil {
+			return []byte{}, fmt.Errorf("'%s' key not found", c.key)
+		}
+
+		if existing := valueOf(target, c.key); existing != nil {
+			value.AddColumn(existing.Key.GetToken().Position.Column - value.Key.GetToken().Position.Column)
+			existing.Value = value.Value
+			continue
+		}
+
+		if len(target.Values) == 0 || target.IsFlowStyle {
+			return []byte{}, fmt.Errorf("cannot add '%s' to a flow style mapping", c.key)
+		}
+		// align the new key with the existing keys
+		value.AddColumn(target.Values[0].Key.GetToken().Position.Column - value.Key.GetToken().Position.Column)
+		target.Values = append(target.Values, value)
+	}
+
+	output, err := yaml.MarshalWithOptions(node, yaml.WithComment(comments), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return []byte{}, fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+
+	return output, nil
+}
+
+func mappingAt(node ast.Node, path []any) (*ast.MappingNode, error) {
+	for _, p := range path {
+		switch seg := p.(type) {
+		case string:
+			m, ok := node.(*ast.MappingNode)
+			if !ok {
+				return nil, fmt.Errorf("'%s' parent is not a mapping", seg)
+			}
+			v := valueOf(m, seg)
+			if v == nil {
+				return nil, fmt.Errorf("'%s' key not found", seg)
+			}
+			node = v.Value
+		case int:
+			s, ok := node.(*ast.SequenceNode)
+			if !ok || seg >= len(s.Values) {
+				return nil, fmt.Errorf("item %d not found", seg)
+			}
+			node = s.Values[seg]
+		}
+	}
+
+	m, ok := node.(*ast.MappingNode)
+	if !ok {
+		return nil, fmt.Errorf("value at %v is not a mapping", path)
+	}
+	return m, nil
+}
+
+func valueOf(m *ast.MappingNode, key string) *ast.MappingValueNode {
+	for _, v := range m.Values {
+		if v.Key.String() == key {
+			return v
+		}
+	}
+	return nil
+}
+
+var jsonPathEscaper = strings.NewReplacer(`\`, `\\`, ".", `\.`, "*", `\*`, "?", `\?`, "|", `\|`, "#", `\#`, "@", `\@`)
+
+func mergeJson(file []byte, fresh []byte, changes []change) ([]byte, error) {
+	var err error
+	for _, c := range changes {
+		var segments []string
+		for _, p := range append(slices.Clone(c.path), c.key) {
+			segments = append(segments, jsonPathEscaper.Replace(fmt.Sprint(p)))
+		}
+		path := strings.Join(segments, ".")
+
+		if c.remove {
+			file, err = sjson.DeleteBytes(file, path)
+		} else {
+			// the raw value of fresh is indented for the same depth
+			file, err = sjson.SetRawBytes(file, path, []byte(gjson.GetBytes(fresh, path).Raw))
+		}
+		if err != nil {
+			return []byte{}, err
+		}
+	}
+	return file, nil
+}
@@ -0,0 +1,146 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package amend
+
+import (
+	"bytes"
+	"testing"
+)
+
+const mergeOriginal = `# yaml-language-server: $schema=https://example.com/data-product.json
+
+# owned by the checkout team
+apiVersion: v1
+resourceType: data-product
+resourceName: 3b7c9fce-9f33-4c91-800b-8c8804f388c9
+data:
+  name: checkout # display name
+  description: old description
+  sourceApplications:
+    - $ref: ../source-apps/web.yaml
+  eventSpecifications:
+    - resourceName: 15c70141-c223-4172-ab39-86a0194e44e8
+      name: add to cart
+      # fired from the basket
+      event:
+        source: iglu:com.acme/add_to_cart/jsonschema/1-0-0
+  legacy: true
+`
+
+const mergeFresh = `apiVersion: v1
+resourceType: data-product
+resourceName: 3b7c9fce-9f33-4c91-800b-8c8804f388c9
+data:
+    name: checkout
+    description: new description
+    owner: me@example.com
+    sourceApplications:
+        - $ref: ../source-apps/web.yaml
+    eventSpecifications:
+        - resourceName: 15c70141-c223-4172-ab39-86a0194e44e8
+          name: add to cart
+          event:
+            source: iglu:com.acme/add_to_cart/jsonschema/1-0-1
+          entities:
+            tracked:
+                - source: iglu:com.acme/product/jsonschema/1-0-0
+`
+
+func Test_MergeResourceYaml(t *testing.T) {
+	expected := `# yaml-language-server: $schema=https://example.com/data-product.json
+# owned by the checkout team
+apiVersion: v1
+resourceType: data-product
+resourceName: 3b7c9fce-9f33-4c91-800b-8c8804f388c9
+data:
+  name: checkout # display name
+  description: new description
+  sourceApplications:
+    - $ref: ../source-apps/web.yaml
+  eventSpecifications:
+    - resourceName: 15c70141-c223-4172-ab39-86a0194e44e8
+      name: add to cart
+      # fired from the basket
+      event:
+        source: iglu:com.acme/add_to_cart/jsonschema/1-0-1
+      entities:
+        tracked:
+            - source: iglu:com.acme/product/jsonschema/1-0-0
+  owner: me@example.com
+`
+
+	output, err := MergeResource([]byte(mergeOriginal), []byte(mergeFresh), "checkout.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != expected {
+		t.Fatalf("unexpected output\n%s", output)
+	}
+}
+
+func Test_MergeResourceUnchanged(t *testing.T) {
+	fresh := `apiVersion: v1
+resourceType: data-product
+resourceName: 3b7c9fce-9f33-4c91-800b-8c8804f388c9
+data:
+    name: checkout
+    description: old description
+    legacy: true
+    sourceApplications:
+        - $ref: ../source-apps/web.yaml
+    eventSpecifications:
+        - resourceName: 15c70141-c223-4172-ab39-86a0194e44e8
+          name: add to cart
+          event:
+            source: iglu:com.acme/add_to_cart/jsonschema/1-0-0
+`
+
+	output, err := MergeResource([]byte(mergeOriginal), []byte(fresh), "checkout.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(output, []byte(mergeOriginal)) {
+		t.Fatalf("expected the file to be untouched got\n%s", output)
+	}
+}
+
+func Test_MergeResourceJson(t *testing.T) {
+	original := `{
+  "resourceName": "3b7c9fce-9f33-4c91-800b-8c8804f388c9",
+  "data": {
+    "description": "old",
+    "name": "checkout",
+    "legacy": true
+  }
+}`
+	fresh := `{
+  "resourceName": "3b7c9fce-9f33-4c91-800b-8c8804f388c9",
+  "data": {
+    "name": "checkout",
+    "description": "new"
+  }
+}`
+	expected := `{
+  "resourceName": "3b7c9fce-9f33-4c91-800b-8c8804f388c9",
+  "data": {
+    "description": "new",
+    "name": "checkout"
+  }
+}`
+
+	output, err := MergeResource([]byte(original), []byte(fresh), "checkout.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != expected {
+		t.Fatalf("unexpected output\n%s", output)
+	}
+}
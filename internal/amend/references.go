@@ -0,0 +1,99 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package amend
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+)
+
+type sourceUriVisitor struct {
+	replacements map[string]string
+	found        []*ast.StringNode
+}
+
+func (v *sourceUriVisitor) Visit(node ast.Node) ast.Visitor {
+	mv, ok := node.(*ast.MappingValueNode)
+	if !ok || mv.Key == nil || mv.Key.GetToken() == nil || mv.Key.GetToken().Value != "source" {
+		return v
+	}
+	if value, ok := mv.Value.(*ast.StringNode); ok {
+		if _, ok := v.replacements[value.Value]; ok {
+			v.found = append(v.found, value)
+		}
+	}
+	return v
+}
+
+// ReplaceSourceUris swaps data structure uris found as the value of a
+// source key. The file is parsed as yaml, which json is a subset of, and
+// only the matching scalars are spliced so comments and formatting survive
+func ReplaceSourceUris(file []byte, replacements map[string]string) ([]byte, error) {
+	f, err := parser.ParseBytes(file, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	visitor := &sourceUriVisitor{replacements: replacements}
+	for _, doc := range f.Docs {
+		if doc != nil {
+			ast.Walk(visitor, doc)
+		}
+	}
+
+	type splice struct {
+		start int
+		old   string
+		new   string
+	}
+	var splices []splice
+	for _, node := range visitor.found {
+		start, err := tokenValueOffset(file, node.GetToken())
+		if err != nil {
+			return nil, fmt.Errorf("source %w", err)
+		}
+		splices = append(splices, splice{start, node.Value, replacements[node.Value]})
+	}
+	// apply from the end of the file so earlier offsets stay valid
+	slices.SortFunc(splices, func(a, b splice) int { return b.start - a.start })
+
+	out := bytes.Clone(file)
+	for _, s := range splices {
+		out = slices.Concat(out[:s.start], []byte(s.new), out[s.start+len(s.old):])
+	}
+	return out, nil
+}
+
+func ReplaceSourceUrisInFile(fileName string, replacements map[string]string) (bool, error) {
+	file, err := os.ReadFile(fileName)
+	if err != nil {
+		return false, err
+	}
+
+	output, err := ReplaceSourceUris(file, replacements)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", fileName, err)
+	}
+	if bytes.Equal(output, file) {
+		return false, nil
+	}
+
+	if err := os.WriteFile(fileName, output, 0644); err != nil {
+		return false, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,205 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package amend
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Conflict is a field changed differently on both sides of a merge, values
+// are nil where the field is absent
+type Conflict struct {
+	Path   string `yaml:"path" json:"path"`
+	Base   any    `yaml:"base" json:"base"`
+	Local  any    `yaml:"local" json:"local"`
+	Remote any    `yaml:"remote" json:"remote"`
+}
+
+type absent struct{}
+
+var missing = absent{}
+
+// item keys identifying sequence items across versions, eg: event
+// specifications by resourceName, source applications by $ref
+var itemKeys = []string{"resourceName", "$ref", "id"}
+
+// Merge3 merges the changes made to base in local and in remote, as decoded
+// by Normalize. Fields changed on one side only take that side, fields
+// changed differently on both sides keep the local value and are reported
+// as conflicts. Without a base, a nil base, every difference conflicts
+func Merge3(base any, local any, remote any) (any, []Conflict) {
+	if base == nil {
+		base = missing
+	}
+	merged, conflicts := merge3("", base, local, remote)
+	if merged == missing {
+		return nil, conflicts
+	}
+	return merged, conflicts
+}
+
+func merge3(path string, base any, local any, remote any) (any, []Conflict) {
+	switch {
+	case reflect.DeepEqual(local, remote), reflect.DeepEqual(base, remote):
+		return local, nil
+	case reflect.DeepEqual(base, local):
+		return remote, nil
+	}
+
+	lm, lIsMap := local.(map[string]any)
+	rm, rIsMap := remote.(map[string]any)
+	if lIsMap && rIsMap {
+		bm, _ := base.(map[string]any)
+		return mergeMappings(path, bm, lm, rm)
+	}
+
+	if key := itemKey(base, local, remote); key != "" {
+		return mergeSequences(path, key, base, local.([]any), remote.([]any))
+	}
+
+	return local, []Conflict{{Path: path, Base: present(base), Local: present(local), Remote: present(remote)}}
+}
+
+func mergeMappings(path string, base map[string]any, local map[string]any, remote map[string]any) (any, []Conflict) {
+	keys := map[string]bool{}
+	for _, m := range []map[string]any{base, local, remote} {
+		for k := range m {
+			keys[k] = true
+		}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	result := map[string]any{}
+	var conflicts []Conflict
+	for _, k := range sorted {
+		v, c := merge3(path+"/"+escapeToken(k), lookup(base, k), lookup(local, k), lookup(remote, k))
+		conflicts = append(conflicts, c...)
+		if v != missing {
+			result[k] = v
+		}
+	}
+	return result, conflicts
+}
+
+// mergeSequences merges items by identity, in the remote order followed
+// by the items only added locally
+func mergeSequences(path string, key string, base any, local []any, remote []any) (any, []Conflict) {
+	bs, _ := base.([]any)
+	byKey := func(items []any) map[any]any {
+		result := map[any]any{}
+		for _, i := range items {
+			result[i.(map[string]any)[key]] = i
+		}
+		return result
+	}
+	baseItems, localItems, remoteItems := byKey(bs), byKey(local), byKey(remote)
+
+	var order []any
+	for _, items := range [][]any{remote, local} {
+		for _, i := range items {
+			id := i.(map[string]any)[key]
+			if !containsKey(order, id) {
+				order = append(order, id)
+			}
+		}
+	}
+
+	result := []any{}
+	var conflicts []Conflict
+	for _, id := range order {
+		v, c := merge3(fmt.Sprintf("%s/%d", path, len(result)), lookupAny(baseItems, id), lookupAny(localItems, id), lookupAny(remoteItems, id))
+		conflicts = append(conflicts, c...)
+		if v != missing {
+			result = append(result, v)
+		}
+	}
+	return result, conflicts
+}
+
+// itemKey finds the key identifying the items of the sequences, empty when
+// they are not sequences of uniquely identified mappings
+func itemKey(base any, local any, remote any) string {
+	var sequences [][]any
+	for _, v := range []any{local, remote} {
+		s, ok := v.([]any)
+		if !ok {
+			return ""
+		}
+		sequences = append(sequences, s)
+	}
+	if s, ok := base.([]any); ok {
+		sequences = append(sequences, s)
+	}
+
+	for _, key := range itemKeys {
+		unique := true
+		for _, s := range sequences {
+			seen := map[any]bool{}
+			for _, i := range s {
+				m, ok := i.(map[string]any)
+				if !ok {
+					return ""
+				}
+				id, ok := m[key].(string)
+				if !ok || seen[id] {
+					unique = false
+					break
+				}
+				seen[id] = true
+			}
+		}
+		if unique {
+			return key
+		}
+	}
+	return ""
+}
+
+func lookup(m map[string]any, k string) any {
+	if v, ok := m[k]; ok {
+		return v
+	}
+	return missing
+}
+
+func lookupAny(m map[any]any, k any) any {
+	if v, ok := m[k]; ok {
+		return v
+	}
+	return missing
+}
+
+func containsKey(keys []any, k any) bool {
+	for _, i := range keys {
+		if i == k {
+			return true
+		}
+	}
+	return false
+}
+
+func present(v any) any {
+	if v == missing {
+		return nil
+	}
+	return v
+}
+
+func escapeToken(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
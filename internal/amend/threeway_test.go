@@ -0,0 +1,117 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package amend
+
+import (
+	"reflect"
+	"testing"
+)
+
+func decode(t *testing.T, s string) any {
+	v, err := Normalize([]byte(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func Test_Merge3(t *testing.T) {
+	base := decode(t, `
+data:
+  name: checkout
+  description: old
+  owner: a@example.com
+  eventSpecifications:
+    - resourceName: es1
+      name: add to cart
+    - resourceName: es2
+      name: purchase
+`)
+	local := decode(t, `
+data:
+  name: checkout
+  description: written in git
+  owner: a@example.com
+  eventSpecifications:
+    - resourceName: es1
+      name: add to cart
+    - resourceName: es2
+      name: purchase
+      description: local
+    - resourceName: es3
+      name: refund
+`)
+	remote := decode(t, `
+data:
+  name: checkout
+  description: old
+  owner: b@example.com
+  eventSpecifications:
+    - resourceName: es1
+      name: add item to cart
+    - resourceName: es2
+      name: purchase
+`)
+
+	merged, conflicts := Merge3(base, local, remote)
+	if len(conflicts) != 0 {
+		t.Fatalf("unexpected conflicts %v", conflicts)
+	}
+
+	expected := decode(t, `
+data:
+  name: checkout
+  description: written in git
+  owner: b@example.com
+  eventSpecifications:
+    - resourceName: es1
+      name: add item to cart
+    - resourceName: es2
+      name: purchase
+      description: local
+    - resourceName: es3
+      name: refund
+`)
+	if !reflect.DeepEqual(merged, expected) {
+		t.Fatalf("unexpected merge %v", merged)
+	}
+}
+
+func Test_Merge3Conflicts(t *testing.T) {
+	base := decode(t, "data:\n  name: checkout\n  eventSpecifications:\n    - resourceName: es1\n      name: add to cart\n")
+	local := decode(t, "data:\n  name: checkout\n  eventSpecifications:\n    - resourceName: es1\n      name: basket add\n")
+	remote := decode(t, "data:\n  name: checkout flow\n  eventSpecifications: []\n")
+
+	merged, conflicts := Merge3(base, local, remote)
+
+	expected := []Conflict{{
+		Path:   "/data/eventSpecifications/0",
+		Base:   map[string]any{"resourceName": "es1", "name": "add to cart"},
+		Local:  map[string]any{"resourceName": "es1", "name": "basket add"},
+		Remote: nil,
+	}}
+	if !reflect.DeepEqual(conflicts, expected) {
+		t.Fatalf("unexpected conflicts %#v", conflicts)
+	}
+	if !reflect.DeepEqual(merged, decode(t, "data:\n  name: checkout flow\n  eventSpecifications:\n    - resourceName: es1\n      name: basket add\n")) {
+		t.Fatalf("expected local values for conflicts got %v", merged)
+	}
+}
+
+func Test_Merge3WithoutBase(t *testing.T) {
+	local := decode(t, "data:\n  name: checkout\n  owner: a@example.com\n")
+	remote := decode(t, "data:\n  name: checkout\n  owner: b@example.com\n")
+
+	_, conflicts := Merge3(nil, local, remote)
+	if len(conflicts) != 1 || conflicts[0].Path != "/data/owner" || conflicts[0].Base != nil {
+		t.Fatalf("unexpected conflicts %#v", conflicts)
+	}
+}
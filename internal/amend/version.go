@@ -0,0 +1,113 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package amend
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/token"
+	"github.com/tidwall/sjson"
+)
+
+// SetDataStructureVersion rewrites data.self.version of a data structure
+// file. Only the version value changes, comments and key order are kept
+func SetDataStructureVersion(file []byte, fileName string, version string) ([]byte, error) {
+	if strings.HasSuffix(fileName, ".yaml") || strings.HasSuffix(fileName, "yml") {
+		return setYamlVersion(file, version)
+	} else if strings.HasSuffix(fileName, ".json") {
+		return sjson.SetBytes(file, "data.self.version", version)
+	}
+	return []byte{}, fmt.Errorf("file has not recognized extension %s. Recognized are .yaml, .yml, .json", fileName)
+}
+
+func setYamlVersion(file []byte, version string) ([]byte, error) {
+	f, err := parser.ParseBytes(file, parser.ParseComments)
+	if err != nil {
+		return []byte{}, fmt.Errorf("failed to parse data structure YAML: %w", err)
+	}
+
+	path, err := yaml.PathString("$.data.self.version")
+	if err != nil {
+		return []byte{}, err
+	}
+
+	node, err := path.FilterFile(f)
+	if err != nil {
+		return []byte{}, fmt.Errorf("'data.self.version' key not found: %w", err)
+	}
+
+	// splice the new value in place of the old token rather than
+	// re-encoding the document so the rest of the file is untouched
+	tok := node.GetToken()
+	valueStart, err := tokenValueOffset(file, tok)
+	if err != nil {
+		return []byte{}, fmt.Errorf("'data.self.version' %w", err)
+	}
+
+	var out bytes.Buffer
+	out.Write(file[:valueStart])
+	out.WriteString(version)
+	out.Write(file[valueStart+len(tok.Value):])
+
+	return out.Bytes(), nil
+}
+
+// SetDataStructureVersionInFile writes fileName with its version set to
+// newFileName, which is either fileName itself or a new file
+func SetDataStructureVersionInFile(fileName string, newFileName string, version string) error {
+	file, err := os.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	output, err := SetDataStructureVersion(file, fileName, version)
+	if err != nil {
+		return err
+	}
+
+	if newFileName != fileName {
+		if _, err := os.Stat(newFileName); err == nil {
+			return fmt.Errorf("failed to write %s, file already exists", newFileName)
+		}
+	}
+
+	if err := os.WriteFile(newFileName, output, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+// tokenValueOffset finds the byte offset of a scalar token's value in the
+// raw file, skipping any quote that precedes it
+func tokenValueOffset(file []byte, tok *token.Token) (int, error) {
+	lines := bytes.SplitAfter(file, []byte("\n"))
+	if tok.Position.Line < 1 || tok.Position.Line > len(lines) {
+		return 0, fmt.Errorf("position out of range")
+	}
+
+	offset := 0
+	for _, l := range lines[:tok.Position.Line-1] {
+		offset += len(l)
+	}
+	line := lines[tok.Position.Line-1]
+	start := min(max(tok.Position.Column-1, 0), len(line))
+	idx := bytes.Index(line[start:], []byte(tok.Value))
+	if idx < 0 {
+		return 0, fmt.Errorf("value not found on line %d", tok.Position.Line)
+	}
+	return offset + start + idx, nil
+}
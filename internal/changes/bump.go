@@ -0,0 +1,184 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package changes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/util"
+)
+
+// Bump of a data structure file. In the vendor/name/version layout the new
+// version goes to NewFileName next to the previous one, otherwise
+// NewFileName is FileName
+type Bump struct {
+	FileName      string
+	NewFileName   string
+	Uri           string
+	LocalVersion  string
+	RemoteVersion string
+	NewVersion    string
+}
+
+// bumpable are the changed data structures already deployed, whose next
+// version can be computed from the deployed one. Of several local versions
+// of a data structure only the highest is bumped
+func bumpable(changes Changes) ([]model.DSChangeContext, error) {
+	var res []model.DSChangeContext
+	var versions []model.SemVersion
+	index := map[DataStructureId]int{}
+	for _, ds := range slices.Concat(changes.ToUpdatePatch, changes.ToUpdateNewVersion) {
+		if ds.RemoteVersion == "" {
+			continue
+		}
+		data, err := ds.DS.ParseData()
+		if err != nil {
+			return nil, err
+		}
+		version, err := model.ParseSemVer(data.Self.Version)
+		if err != nil {
+			return nil, err
+		}
+		id := idFromSelf(data.Self)
+		if i, ok := index[id]; ok {
+			if model.SemVerCmp(*version, versions[i]) == 1 {
+				res[i], versions[i] = ds, *version
+			}
+			continue
+		}
+		index[id] = len(res)
+		res = append(res, ds)
+		versions = append(versions, *version)
+	}
+	return res, nil
+}
+
+// UpgradeType maps the MODEL-REVISION-ADDITION part names onto the upgrade
+// types understood by model.SemNextVer
+func UpgradeType(part string) (string, error) {
+	switch part {
+	case "major", "model":
+		return "major", nil
+	case "revision":
+		return "revision", nil
+	case "addition", "minor":
+		return "minor", nil
+	}
+	return "", fmt.Errorf("unknown version part %s. Was not major, revision or addition", part)
+}
+
+func newBump(ds model.DSChangeContext, next model.SemVersion) (*Bump, error) {
+	data, err := ds.DS.ParseData()
+	if err != nil {
+		return nil, err
+	}
+	local, err := model.ParseSemVer(data.Self.Version)
+	if err != nil {
+		return nil, err
+	}
+	if model.SemVerCmp(next, *local) == 0 {
+		return nil, nil
+	}
+	newFileName := ds.FileName
+	if util.IsVersionedLayout(ds.FileName, data.Self) {
+		newFileName = filepath.Join(filepath.Dir(ds.FileName), next.String()+filepath.Ext(ds.FileName))
+	}
+	return &Bump{
+		FileName:      ds.FileName,
+		NewFileName:   newFileName,
+		Uri:           data.Self.IgluUri(),
+		LocalVersion:  data.Self.Version,
+		RemoteVersion: ds.RemoteVersion,
+		NewVersion:    next.String(),
+	}, nil
+}
+
+// ExplicitBumps sets every changed data structure to the next version of
+// its deployed version for the given upgrade type
+func ExplicitBumps(changes Changes, upgradeType string) ([]Bump, error) {
+	candidates, err := bumpable(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []Bump
+	for _, ds := range candidates {
+		remote, err := model.ParseSemVer(ds.RemoteVersion)
+		if err != nil {
+			return nil, err
+		}
+		b, err := newBump(ds, model.SemNextVer(*remote, upgradeType))
+		if err != nil {
+			return nil, err
+		}
+		if b != nil {
+			res = append(res, *b)
+		}
+	}
+	return res, nil
+}
+
+// AutoBumps asks the schema migration service which version each changed
+// data structure requires and keeps the highest across destinations. The
+// service needs destinations to compare with, without any it is an error
+func AutoBumps(cnx context.Context, c *console.ApiClient, changes Changes) ([]Bump, error) {
+	candidates, err := bumpable(changes)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	ok, err := console.HasDestinations(cnx, c)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("the organization has no destinations to compute the required versions against, use --to to choose the version part to bump")
+	}
+
+	var res []Bump
+	for _, ds := range candidates {
+		reports, err := console.ValidateMigrations(cnx, c, ds)
+		if err != nil {
+			return nil, err
+		}
+
+		var next *model.SemVersion
+		for _, r := range reports {
+			v, err := model.ParseSemVer(r.SuggestedVersion)
+			if err != nil {
+				return nil, err
+			}
+			if next == nil || model.SemVerCmp(*v, *next) == 1 {
+				next = v
+			}
+		}
+		if next == nil {
+			continue
+		}
+
+		b, err := newBump(ds, *next)
+		if err != nil {
+			return nil, err
+		}
+		if b != nil {
+			res = append(res, *b)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package changes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+func bumpDs(name string, version string) model.DataStructure {
+	return model.DataStructure{Data: map[string]any{
+		"$schema": "http://iglucentral.com/schemas/com.snowplowanalytics.self-desc/schema/jsonschema/1-0-0#",
+		"self":    map[string]any{"vendor": "com.acme", "name": name, "format": "jsonschema", "version": version},
+	}}
+}
+
+func Test_ExplicitBumps(t *testing.T) {
+	changes := Changes{
+		ToUpdatePatch: []model.DSChangeContext{
+			{DS: bumpDs("checkout", "1-0-0"), FileName: "patched.yaml", RemoteVersion: "1-0-0"},
+		},
+		ToUpdateNewVersion: []model.DSChangeContext{
+			{DS: bumpDs("cart", "1-1-0"), FileName: "already.yaml", RemoteVersion: "1-0-0"},
+			{DS: bumpDs("login", "1-0-0"), FileName: "undeployed.yaml", RemoteVersion: ""},
+		},
+	}
+
+	upgradeType, err := UpgradeType("revision")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bumps, err := ExplicitBumps(changes, upgradeType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bumps) != 1 {
+		t.Fatalf("expected 1 bump, got %+v", bumps)
+	}
+	if bumps[0].FileName != "patched.yaml" || bumps[0].NewVersion != "1-1-0" {
+		t.Fatalf("unexpected bump %+v", bumps[0])
+	}
+
+	upgradeType, _ = UpgradeType("addition")
+	bumps, err = ExplicitBumps(changes, upgradeType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bumps) != 2 || bumps[1].FileName != "already.yaml" || bumps[1].NewVersion != "1-0-1" {
+		t.Fatalf("unexpected bumps %+v", bumps)
+	}
+
+	history := Changes{
+		ToUpdatePatch: []model.DSChangeContext{
+			{DS: bumpDs("checkout", "1-0-1"), FileName: "checkout/1-0-1.yaml", RemoteVersion: "1-0-1"},
+			{DS: bumpDs("checkout", "1-0-0"), FileName: "checkout/1-0-0.yaml", RemoteVersion: "1-0-1"},
+		},
+	}
+	bumps, err = ExplicitBumps(history, upgradeType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Bump{FileName: "checkout/1-0-1.yaml", NewFileName: "checkout/1-0-2.yaml", Uri: "iglu:com.acme/checkout/jsonschema/1-0-1", LocalVersion: "1-0-1", RemoteVersion: "1-0-1", NewVersion: "1-0-2"}
+	if len(bumps) != 1 || bumps[0] != expected {
+		t.Fatalf("expected only the highest version to be bumped, got %+v", bumps)
+	}
+
+	if _, err := UpgradeType("patch"); err == nil {
+		t.Fatal("expected unknown version part to fail")
+	}
+}
+
+func Test_AutoBumpsNoDestinations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/msc/v1/organizations/orgid/destinations/v3" {
+			_, _ = io.WriteString(w, `[]`)
+			return
+		}
+		t.Errorf("Unexpected request, got: %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	client := &console.ApiClient{Http: &http.Client{}, Jwt: "token", BaseUrl: fmt.Sprintf("%s/api/msc/v1/organizations/orgid", server.URL)}
+	changes := Changes{
+		ToUpdatePatch: []model.DSChangeContext{
+			{DS: bumpDs("checkout", "1-0-0"), FileName: "patched.yaml", RemoteVersion: "1-0-0"},
+		},
+	}
+
+	if _, err := AutoBumps(context.Background(), client, changes); err == nil {
+		t.Fatal("expected an error without destinations")
+	}
+
+	bumps, err := AutoBumps(context.Background(), client, Changes{})
+	if err != nil || len(bumps) != 0 {
+		t.Fatalf("expected nothing to do without changes, got %v %v", bumps, err)
+	}
+}
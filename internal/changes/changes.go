@@ -13,7 +13,8 @@ package changes
 import (
 	"context"
 	"errors"
-	"reflect"
+	"fmt"
+	"sort"
 
 	"github.com/r3labs/diff/v3"
 	"github.com/snowplow/snowplow-cli/internal/console"
@@ -60,6 +61,17 @@ type Changes struct {
 	ToUpdatePatch      []model.DSChangeContext
 }
 
+type localVersion struct {
+	fileName string
+	ds       model.DataStructure
+	data     model.DataStructureData
+	version  *model.SemVersion
+}
+
+// GetChanges compares local data structures with the remote listing. Files
+// in the vendor/name/version layout may hold several versions of the same
+// data structure, every version newer than the deployed one is published in
+// ascending order
 func GetChanges(locals map[string]model.DataStructure, remoteListing []console.ListResponse, env console.DataStructureEnv) (Changes, error) {
 	res := Changes{
 		make([]model.DSChangeContext, 0),
@@ -72,77 +84,205 @@ func GetChanges(locals map[string]model.DataStructure, remoteListing []console.L
 		remotesSet[DataStructureId{remote.Vendor, remote.Name, remote.Format}] = remote
 	}
 
+	groups := make(map[DataStructureId][]localVersion)
+	var ids []DataStructureId
 	for f, ds := range locals {
 		data, err := ds.ParseData()
 		if err != nil {
 			return Changes{}, err
 		}
-		remotePair, exists := remotesSet[idFromSelf(data.Self)]
-		// DS does not exists, we should create it
-		if !exists {
-			res.ToCreate = append(res.ToCreate, NewDSChangeContext(ds, f))
-		} else {
-			//Remote DS exists,
-			if !reflect.DeepEqual(ds.Meta, remotePair.Meta) {
-				// Meta is different, needs updating
-				res.ToUpdateMeta = append(res.ToUpdateMeta, NewDSChangeContext(ds, f))
-			}
-			contentHash, err := ds.GetContentHash()
-			if err != nil {
+		id := idFromSelf(data.Self)
+		if _, ok := groups[id]; !ok {
+			ids = append(ids, id)
+		}
+		groups[id] = append(groups[id], localVersion{fileName: f, ds: ds, data: data})
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return fmt.Sprint(ids[i]) < fmt.Sprint(ids[j])
+	})
+
+	for _, id := range ids {
+		group := groups[id]
+		remotePair, exists := remotesSet[id]
+		if len(group) == 1 {
+			if err := addChanges(&res, group[0].fileName, group[0].ds, group[0].data, remotePair, exists, env); err != nil {
 				return Changes{}, err
 			}
-			var foundDeployment bool
-			// find the correct deployment to compare to
-			for _, deployment := range remotePair.Deployments {
-				if deployment.Env == env {
-					foundDeployment = true
-					if deployment.ContentHash != contentHash {
-						// data structure has changed
-						if data.Self.Version != deployment.Version {
-							// Different version, create new version
-							res.ToUpdateNewVersion = append(res.ToUpdateNewVersion, NewDSChangeContextWithVersion(ds, f, deployment.Version))
-						} else {
-							// Same version, but different hash, patch
-							res.ToUpdatePatch = append(res.ToUpdatePatch, NewDSChangeContextWithVersionAndHashes(ds, f, deployment.Version, contentHash, deployment.ContentHash))
-						}
-					}
+			continue
+		}
+		if err := addVersionedChanges(&res, group, remotePair, exists, env); err != nil {
+			return Changes{}, err
+		}
+	}
+	return res, nil
+}
+
+func addChanges(res *Changes, f string, ds model.DataStructure, data model.DataStructureData, remotePair console.ListResponse, exists bool, env console.DataStructureEnv) error {
+	// DS does not exists, we should create it
+	if !exists {
+		res.ToCreate = append(res.ToCreate, NewDSChangeContext(ds, f))
+		return nil
+	}
+	//Remote DS exists,
+	if ds.Meta.ChangedFrom(remotePair.Meta) {
+		// Meta is different, needs updating
+		res.ToUpdateMeta = append(res.ToUpdateMeta, NewDSChangeContext(ds, f))
+	}
+	contentHash, err := ds.GetContentHash()
+	if err != nil {
+		return err
+	}
+	var foundDeployment bool
+	// find the correct deployment to compare to
+	for _, deployment := range remotePair.Deployments {
+		if deployment.Env == env {
+			foundDeployment = true
+			if deployment.ContentHash != contentHash {
+				// data structure has changed
+				if data.Self.Version != deployment.Version {
+					// Different version, create new version
+					res.ToUpdateNewVersion = append(res.ToUpdateNewVersion, NewDSChangeContextWithVersion(ds, f, deployment.Version))
+				} else {
+					// Same version, but different hash, patch
+					res.ToUpdatePatch = append(res.ToUpdatePatch, NewDSChangeContextWithVersionAndHashes(ds, f, deployment.Version, contentHash, deployment.ContentHash))
 				}
 			}
-			if !foundDeployment {
-				// DS exists, but we didn't find a version of it
-				// We should deploy from dev to prod
-				res.ToUpdateNewVersion = append(res.ToUpdateNewVersion, NewDSChangeContextWithVersion(ds, f, ""))
-			}
 		}
 	}
-	return res, nil
+	if !foundDeployment {
+		// DS exists, but we didn't find a version of it
+		// We should deploy from dev to prod
+		res.ToUpdateNewVersion = append(res.ToUpdateNewVersion, NewDSChangeContextWithVersion(ds, f, ""))
+	}
+	return nil
 }
 
-func PerformChangesDev(cnx context.Context, c *console.ApiClient, changes Changes, managedFrom string) error {
-	// Create and create new version both follow the same logic
-	validatePublish := append(changes.ToCreate, changes.ToUpdateNewVersion...)
-	for _, ds := range validatePublish {
-		vr, err := console.Validate(cnx, c, ds.DS)
+func addVersionedChanges(res *Changes, group []localVersion, remotePair console.ListResponse, exists bool, env console.DataStructureEnv) error {
+	for i := range group {
+		v, err := model.ParseSemVer(group[i].data.Self.Version)
 		if err != nil {
-			return err
+			return fmt.Errorf("invalid version in %s: %w", group[i].fileName, err)
 		}
-		if !vr.Valid {
-			return errors.New(vr.Message)
+		group[i].version = v
+	}
+	sort.Slice(group, func(i, j int) bool {
+		return model.SemVerCmp(*group[i].version, *group[j].version) < 0
+	})
+
+	// every version is created in order, the first one creates the data structure
+	if !exists {
+		for _, lv := range group {
+			res.ToCreate = append(res.ToCreate, NewDSChangeContext(lv.ds, lv.fileName))
 		}
-		_, err = console.PublishDev(cnx, c, ds.DS, false, managedFrom)
-		if err != nil {
-			return err
+		return nil
+	}
+
+	// meta is not versioned, the latest version holds it
+	latest := group[len(group)-1]
+	if latest.ds.Meta.ChangedFrom(remotePair.Meta) {
+		res.ToUpdateMeta = append(res.ToUpdateMeta, NewDSChangeContext(latest.ds, latest.fileName))
+	}
+
+	var deployment *console.Deployment
+	for _, d := range remotePair.Deployments {
+		if d.Env == env {
+			deployment = &d
+		}
+	}
+
+	// each new version is compared to the one published just before it
+	if deployment == nil {
+		previous := ""
+		for _, lv := range group {
+			res.ToUpdateNewVersion = append(res.ToUpdateNewVersion, NewDSChangeContextWithVersion(lv.ds, lv.fileName, previous))
+			previous = lv.data.Self.Version
 		}
+		return nil
 	}
-	for _, ds := range changes.ToUpdatePatch {
-		vr, err := console.Validate(cnx, c, ds.DS)
+
+	deployed, err := model.ParseSemVer(deployment.Version)
+	if err != nil {
+		return err
+	}
+
+	previous := deployment.Version
+	for _, lv := range group {
+		switch model.SemVerCmp(*lv.version, *deployed) {
+		case 1:
+			res.ToUpdateNewVersion = append(res.ToUpdateNewVersion, NewDSChangeContextWithVersion(lv.ds, lv.fileName, previous))
+			previous = lv.data.Self.Version
+		case 0:
+			contentHash, err := lv.ds.GetContentHash()
+			if err != nil {
+				return err
+			}
+			if contentHash != deployment.ContentHash {
+				res.ToUpdatePatch = append(res.ToUpdatePatch, NewDSChangeContextWithVersionAndHashes(lv.ds, lv.fileName, deployment.Version, contentHash, deployment.ContentHash))
+			}
+		}
+		// older versions are history, already published
+	}
+	return nil
+}
+
+type publishStep struct {
+	ds      model.DSChangeContext
+	isPatch bool
+	id      DataStructureId
+	version *model.SemVersion
+}
+
+// publishOrder lists creates, new versions and patches so that the versions
+// of each data structure are published in ascending order, eg: a patch of
+// the deployed version before the versions following it
+func publishOrder(changes Changes) ([]publishStep, error) {
+	var steps []publishStep
+	add := func(dss []model.DSChangeContext, isPatch bool) error {
+		for _, ds := range dss {
+			data, err := ds.DS.ParseData()
+			if err != nil {
+				return err
+			}
+			v, err := model.ParseSemVer(data.Self.Version)
+			if err != nil {
+				return err
+			}
+			steps = append(steps, publishStep{ds, isPatch, idFromSelf(data.Self), v})
+		}
+		return nil
+	}
+	if err := add(changes.ToCreate, false); err != nil {
+		return nil, err
+	}
+	if err := add(changes.ToUpdatePatch, true); err != nil {
+		return nil, err
+	}
+	if err := add(changes.ToUpdateNewVersion, false); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(steps, func(i, j int) bool {
+		if steps[i].id != steps[j].id {
+			return fmt.Sprint(steps[i].id) < fmt.Sprint(steps[j].id)
+		}
+		return model.SemVerCmp(*steps[i].version, *steps[j].version) < 0
+	})
+	return steps, nil
+}
+
+func PerformChangesDev(cnx context.Context, c *console.ApiClient, changes Changes, managedFrom string) error {
+	steps, err := publishOrder(changes)
+	if err != nil {
+		return err
+	}
+	for _, step := range steps {
+		vr, err := console.Validate(cnx, c, step.ds.DS)
 		if err != nil {
 			return err
 		}
 		if !vr.Valid {
 			return errors.New(vr.Message)
 		}
-		_, err = console.PublishDev(cnx, c, ds.DS, true, managedFrom)
+		_, err = console.PublishDev(cnx, c, step.ds.DS, step.isPatch, managedFrom)
 		if err != nil {
 			return err
 		}
@@ -169,9 +309,12 @@ func PerformChangesProd(cnx context.Context, c *console.ApiClient, changes Chang
 	if err != nil {
 		return err
 	}
-	validatePublish := append(changes.ToCreate, changes.ToUpdateNewVersion...)
-	for _, ds := range validatePublish {
-		_, err := console.PublishProd(cnx, c, ds.DS, managedFrom)
+	steps, err := publishOrder(changes)
+	if err != nil {
+		return err
+	}
+	for _, step := range steps {
+		_, err := console.PublishProd(cnx, c, step.ds.DS, managedFrom)
 		if err != nil {
 			return err
 		}
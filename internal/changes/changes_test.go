@@ -13,6 +13,7 @@ package changes
 import (
 	. "github.com/snowplow/snowplow-cli/internal/console"
 	. "github.com/snowplow/snowplow-cli/internal/model"
+	"reflect"
 	"testing"
 )
 
@@ -196,3 +197,78 @@ func Test_GetChangesProdDeploy(t *testing.T) {
 	}
 
 }
+
+func versionedDs(version string) DataStructure {
+	return DataStructure{
+		Meta: DataStructureMeta{Hidden: false, SchemaType: "entity"},
+		Data: map[string]any{
+			"self": map[string]any{
+				"vendor":  "string",
+				"name":    "string",
+				"format":  "string",
+				"version": version,
+			},
+			"schema": "string"},
+	}
+}
+
+func Test_GetChangesVersionedLayout(t *testing.T) {
+	locals := map[string]DataStructure{
+		"string/string/1-0-0.yaml": versionedDs("1-0-0"),
+		"string/string/1-0-2.yaml": versionedDs("1-0-2"),
+		"string/string/1-0-1.yaml": versionedDs("1-0-1"),
+		"string/string/1-1-0.yaml": versionedDs("1-1-0"),
+	}
+	remote := ListResponse{
+		Vendor: "string",
+		Name:   "string",
+		Meta:   DataStructureMeta{Hidden: false, SchemaType: "entity"},
+		Format: "string",
+		Deployments: []Deployment{
+			{
+				Version:     "1-0-1",
+				Env:         "DEV",
+				ContentHash: "different",
+			},
+		},
+	}
+
+	res, err := GetChanges(locals, []ListResponse{remote}, "DEV")
+	if err != nil {
+		t.Fatalf("Can't calcuate changes %s", err)
+	}
+
+	if len(res.ToCreate) != 0 || len(res.ToUpdateMeta) != 0 || len(res.ToUpdatePatch) != 1 || len(res.ToUpdateNewVersion) != 2 {
+		t.Fatalf("Unexpected result, expecting one patch and two new versions, got %+v", res)
+	}
+	if res.ToUpdatePatch[0].FileName != "string/string/1-0-1.yaml" {
+		t.Fatalf("Unexpected patch %s", res.ToUpdatePatch[0].FileName)
+	}
+	if res.ToUpdateNewVersion[0].FileName != "string/string/1-0-2.yaml" || res.ToUpdateNewVersion[1].FileName != "string/string/1-1-0.yaml" {
+		t.Fatalf("Unexpected new versions order %+v", res.ToUpdateNewVersion)
+	}
+	if res.ToUpdateNewVersion[0].RemoteVersion != "1-0-1" || res.ToUpdateNewVersion[1].RemoteVersion != "1-0-2" {
+		t.Fatalf("Expected new versions to compare to the version before them, got %s and %s", res.ToUpdateNewVersion[0].RemoteVersion, res.ToUpdateNewVersion[1].RemoteVersion)
+	}
+
+	steps, err := publishOrder(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	for _, s := range steps {
+		order = append(order, s.ds.FileName)
+	}
+	expected := []string{"string/string/1-0-1.yaml", "string/string/1-0-2.yaml", "string/string/1-1-0.yaml"}
+	if !reflect.DeepEqual(order, expected) || !steps[0].isPatch {
+		t.Fatalf("Expected the patch of the deployed version published first, got %v", order)
+	}
+
+	res, err = GetChanges(locals, []ListResponse{}, "DEV")
+	if err != nil {
+		t.Fatalf("Can't calcuate changes %s", err)
+	}
+	if len(res.ToCreate) != 4 || res.ToCreate[0].FileName != "string/string/1-0-0.yaml" || res.ToCreate[3].FileName != "string/string/1-1-0.yaml" {
+		t.Fatalf("Unexpected result, expecting all versions created in order, got %+v", res.ToCreate)
+	}
+}
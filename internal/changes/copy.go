@@ -0,0 +1,154 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package changes
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+// Conflict is a version of a data structure that can not be copied to the
+// target organization
+type Conflict struct {
+	Uri    string
+	Reason string
+}
+
+// CopyChanges works out the changes publishing every version of sources to
+// env of the target organization, in ascending order. The first version of a
+// data structure the target does not have creates it, each following version
+// is a new version of the one published before it. targetVersions are all
+// the versions the target has in its development environment. Versions the
+// target has with a different content, or older than its deployed version
+// but missing, are returned as conflicts. The change contexts are named
+// after the data structure uri
+func CopyChanges(sources []model.DataStructure, targetVersions []model.DataStructure, listing []console.ListResponse, env console.DataStructureEnv) (Changes, []Conflict, error) {
+	res := Changes{}
+	var conflicts []Conflict
+
+	targetHashes := map[string]string{}
+	for _, ds := range targetVersions {
+		data, err := ds.ParseData()
+		if err != nil {
+			return res, nil, err
+		}
+		hash, err := ds.GetContentHash()
+		if err != nil {
+			return res, nil, err
+		}
+		targetHashes[data.Self.IgluUri()] = hash
+	}
+
+	remotesSet := map[DataStructureId]console.ListResponse{}
+	for _, remote := range listing {
+		remotesSet[DataStructureId{remote.Vendor, remote.Name, remote.Format}] = remote
+	}
+
+	groups := map[DataStructureId][]localVersion{}
+	var ids []DataStructureId
+	for _, ds := range sources {
+		data, err := ds.ParseData()
+		if err != nil {
+			return res, nil, err
+		}
+		uri := data.Self.IgluUri()
+		v, err := model.ParseSemVer(data.Self.Version)
+		if err != nil {
+			return res, nil, fmt.Errorf("invalid version of %s: %w", uri, err)
+		}
+		id := idFromSelf(data.Self)
+		if _, ok := groups[id]; !ok {
+			ids = append(ids, id)
+		}
+		groups[id] = append(groups[id], localVersion{fileName: uri, ds: ds, data: data, version: v})
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return fmt.Sprint(ids[i]) < fmt.Sprint(ids[j])
+	})
+
+	for _, id := range ids {
+		group := groups[id]
+		sort.Slice(group, func(i, j int) bool {
+			return model.SemVerCmp(*group[i].version, *group[j].version) < 0
+		})
+		group = dedupVersions(group)
+
+		remote, exists := remotesSet[id]
+		var deployed *model.SemVersion
+		deployedVersion := ""
+		for _, d := range remote.Deployments {
+			if d.Env == env {
+				v, err := model.ParseSemVer(d.Version)
+				if err != nil {
+					return res, nil, err
+				}
+				deployed = v
+				deployedVersion = d.Version
+			}
+		}
+
+		// meta is not versioned, the latest version holds it
+		latest := group[len(group)-1]
+		if exists && latest.ds.Meta.ChangedFrom(remote.Meta) {
+			res.ToUpdateMeta = append(res.ToUpdateMeta, NewDSChangeContext(latest.ds, latest.fileName))
+		}
+
+		created := exists
+		previous := deployedVersion
+		for _, lv := range group {
+			hash, err := lv.ds.GetContentHash()
+			if err != nil {
+				return res, nil, err
+			}
+			targetHash, inTarget := targetHashes[lv.fileName]
+			if inTarget && targetHash != hash {
+				conflicts = append(conflicts, Conflict{Uri: lv.fileName, Reason: "the target has a different content for this version"})
+				continue
+			}
+			if deployed != nil && model.SemVerCmp(*lv.version, *deployed) <= 0 {
+				if !inTarget {
+					conflicts = append(conflicts, Conflict{Uri: lv.fileName, Reason: fmt.Sprintf("older than the deployed version %s", deployedVersion)})
+				}
+				// already published
+				continue
+			}
+			if env == console.PROD && !inTarget {
+				conflicts = append(conflicts, Conflict{Uri: lv.fileName, Reason: "not deployed to the development environment of the target"})
+				continue
+			}
+			if !created {
+				// the first version creates the data structure
+				res.ToCreate = append(res.ToCreate, NewDSChangeContext(lv.ds, lv.fileName))
+				created = true
+			} else {
+				res.ToUpdateNewVersion = append(res.ToUpdateNewVersion, NewDSChangeContextWithVersion(lv.ds, lv.fileName, previous))
+			}
+			previous = lv.data.Self.Version
+		}
+	}
+
+	return res, conflicts, nil
+}
+
+// listings may return a version once per deployment
+func dedupVersions(group []localVersion) []localVersion {
+	var res []localVersion
+	for _, lv := range group {
+		if len(res) > 0 && res[len(res)-1].fileName == lv.fileName {
+			continue
+		}
+		res = append(res, lv)
+	}
+	return res
+}
@@ -0,0 +1,79 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package changes
+
+import (
+	"testing"
+
+	. "github.com/snowplow/snowplow-cli/internal/console"
+	. "github.com/snowplow/snowplow-cli/internal/model"
+)
+
+func Test_CopyChanges(t *testing.T) {
+	sources := []DataStructure{versionedDs("1-0-2"), versionedDs("1-0-0"), versionedDs("1-0-1"), versionedDs("1-0-1"), versionedDs("1-1-0")}
+
+	res, conflicts, err := CopyChanges(sources, nil, nil, DEV)
+	if err != nil {
+		t.Fatalf("Can't calcuate changes %s", err)
+	}
+	if len(conflicts) != 0 || len(res.ToCreate) != 1 || len(res.ToUpdateNewVersion) != 3 {
+		t.Fatalf("Unexpected result, expecting the first version created and the others as new versions, got %+v %+v", res, conflicts)
+	}
+	if res.ToCreate[0].FileName != "iglu:string/string/string/1-0-0" {
+		t.Fatalf("Unexpected creation %+v", res.ToCreate)
+	}
+	for i, previous := range []string{"1-0-0", "1-0-1", "1-0-2"} {
+		if res.ToUpdateNewVersion[i].RemoteVersion != previous {
+			t.Fatalf("Expected new version %d to follow %s, got %+v", i, previous, res.ToUpdateNewVersion[i])
+		}
+	}
+
+	different := versionedDs("1-0-1")
+	different.Data["schema"] = "different"
+	remote := ListResponse{
+		Vendor: "string",
+		Name:   "string",
+		Format: "string",
+		Meta:   DataStructureMeta{Hidden: true, SchemaType: "entity"},
+		Deployments: []Deployment{
+			{Version: "1-0-1", Env: DEV},
+		},
+	}
+
+	res, conflicts, err = CopyChanges(sources, []DataStructure{versionedDs("1-0-0"), different}, []ListResponse{remote}, DEV)
+	if err != nil {
+		t.Fatalf("Can't calcuate changes %s", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Uri != "iglu:string/string/string/1-0-1" {
+		t.Fatalf("Expected a conflict on 1-0-1, got %+v", conflicts)
+	}
+	if len(res.ToCreate) != 0 || len(res.ToUpdateMeta) != 1 || len(res.ToUpdateNewVersion) != 2 {
+		t.Fatalf("Unexpected result, expecting a meta update and two new versions, got %+v", res)
+	}
+	if res.ToUpdateNewVersion[0].FileName != "iglu:string/string/string/1-0-2" || res.ToUpdateNewVersion[0].RemoteVersion != "1-0-1" {
+		t.Fatalf("Unexpected new version %+v", res.ToUpdateNewVersion[0])
+	}
+	if res.ToUpdateNewVersion[1].RemoteVersion != "1-0-2" {
+		t.Fatalf("Expected 1-1-0 to follow 1-0-2, got %+v", res.ToUpdateNewVersion[1])
+	}
+
+	// prod only gets versions the development environment has
+	res, conflicts, err = CopyChanges(sources, []DataStructure{versionedDs("1-0-0"), versionedDs("1-0-1")}, []ListResponse{remote}, PROD)
+	if err != nil {
+		t.Fatalf("Can't calcuate changes %s", err)
+	}
+	if len(res.ToUpdateNewVersion) != 2 || res.ToUpdateNewVersion[1].FileName != "iglu:string/string/string/1-0-1" {
+		t.Fatalf("Unexpected prod versions %+v", res.ToUpdateNewVersion)
+	}
+	if len(conflicts) != 2 {
+		t.Fatalf("Expected the versions missing in dev as conflicts, got %+v", conflicts)
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package changes
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/snowplow/snowplow-cli/internal/git"
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+// Filter selects data structures by their vendor/name/format/version.
+// Patterns without wildcards are prefixes, as with ds download --match,
+// patterns with wildcards match whole path segments
+type Filter struct {
+	Match   []string
+	Exclude []string
+	// Files limits the selection to these absolute paths when not nil
+	Files map[string]bool
+}
+
+func NewFilter(match []string, exclude []string, changedSince string) (Filter, error) {
+	f := Filter{}
+	for _, patterns := range [][]string{match, exclude} {
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return f, fmt.Errorf("invalid pattern %q: %w", p, err)
+			}
+		}
+	}
+	f.Match = match
+	f.Exclude = exclude
+	if changedSince != "" {
+		files, err := git.ChangedFiles(changedSince)
+		if err != nil {
+			return f, err
+		}
+		f.Files = files
+	}
+	return f, nil
+}
+
+func (f Filter) IsEmpty() bool {
+	return len(f.Match) == 0 && len(f.Exclude) == 0 && f.Files == nil
+}
+
+func matchesUri(pattern string, uri string) bool {
+	pattern = strings.TrimPrefix(pattern, "iglu:")
+	if !strings.ContainsAny(pattern, "*?[") {
+		return strings.HasPrefix(uri, pattern)
+	}
+	segments := strings.Split(uri, "/")
+	for i := range segments {
+		if ok, _ := path.Match(pattern, strings.Join(segments[:i+1], "/")); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(patterns []string, uri string) bool {
+	for _, p := range patterns {
+		if matchesUri(p, uri) {
+			return true
+		}
+	}
+	return false
+}
+
+// Selects reports whether the data structure in fileName is selected
+func (f Filter) Selects(fileName string, ds model.DataStructure) (bool, error) {
+	if f.Files != nil {
+		abs, err := git.AbsPath(fileName)
+		if err != nil {
+			return false, err
+		}
+		if !f.Files[abs] {
+			return false, nil
+		}
+	}
+	if len(f.Match) == 0 && len(f.Exclude) == 0 {
+		return true, nil
+	}
+	data, err := ds.ParseData()
+	if err != nil {
+		return false, err
+	}
+	self := data.Self
+	uri := fmt.Sprintf("%s/%s/%s/%s", self.Vendor, self.Name, self.Format, self.Version)
+	if len(f.Match) > 0 && !matchesAny(f.Match, uri) {
+		return false, nil
+	}
+	return !matchesAny(f.Exclude, uri), nil
+}
+
+// Apply keeps the changes to the selected data structures
+func (f Filter) Apply(c Changes) (Changes, error) {
+	if f.IsEmpty() {
+		return c, nil
+	}
+	var err error
+	filtered := c.Keep(func(ds model.DSChangeContext) bool {
+		selected, e := f.Selects(ds.FileName, ds.DS)
+		if e != nil && err == nil {
+			err = e
+		}
+		return selected
+	})
+	return filtered, err
+}
+
+// Locals keeps the selected local data structures
+func (f Filter) Locals(locals map[string]model.DataStructure) (map[string]model.DataStructure, error) {
+	if f.IsEmpty() {
+		return locals, nil
+	}
+	selected := map[string]model.DataStructure{}
+	for fileName, ds := range locals {
+		ok, err := f.Selects(fileName, ds)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			selected[fileName] = ds
+		}
+	}
+	return selected, nil
+}
+
+// Keep returns the changes for which keep is true
+func (c Changes) Keep(keep func(model.DSChangeContext) bool) Changes {
+	filter := func(dss []model.DSChangeContext) []model.DSChangeContext {
+		var result []model.DSChangeContext
+		for _, ds := range dss {
+			if keep(ds) {
+				result = append(result, ds)
+			}
+		}
+		return result
+	}
+	return Changes{
+		ToCreate:           filter(c.ToCreate),
+		ToUpdateMeta:       filter(c.ToUpdateMeta),
+		ToUpdateNewVersion: filter(c.ToUpdateNewVersion),
+		ToUpdatePatch:      filter(c.ToUpdatePatch),
+	}
+}
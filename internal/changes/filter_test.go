@@ -0,0 +1,84 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package changes
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+func filterDs(vendor string, name string) model.DataStructure {
+	return model.DataStructure{Data: map[string]any{
+		"self": map[string]any{"vendor": vendor, "name": name, "format": "jsonschema", "version": "1-0-0"},
+	}}
+}
+
+func Test_matchesUri(t *testing.T) {
+	uri := "com.acme.web/add_to_cart/jsonschema/1-0-0"
+	cases := map[string]bool{
+		"com.acme":                       true,
+		"iglu:com.acme.web/add":          true,
+		"com.acme.app":                   false,
+		"com.acme.*":                     true,
+		"*/add_*":                        true,
+		"com.acme.*/remove_*":            false,
+		"com.acme.web/*/jsonschema/1-*":  true,
+		"com.acme.web/*/jsonschema/2-*":  false,
+		"iglu:com.acme.web/add_to_cart/": true,
+	}
+	for pattern, expected := range cases {
+		if got := matchesUri(pattern, uri); got != expected {
+			t.Errorf("matchesUri(%q) = %v, want %v", pattern, got, expected)
+		}
+	}
+}
+
+func Test_FilterApply(t *testing.T) {
+	c := Changes{
+		ToCreate: []model.DSChangeContext{
+			{DS: filterDs("com.acme.web", "add_to_cart"), FileName: "web/add_to_cart.yaml"},
+			{DS: filterDs("com.acme.app", "screen_view"), FileName: "app/screen_view.yaml"},
+		},
+		ToUpdateNewVersion: []model.DSChangeContext{
+			{DS: filterDs("com.acme.web", "legacy_click"), FileName: "web/legacy_click.yaml"},
+		},
+	}
+
+	f, err := NewFilter([]string{"com.acme.web"}, []string{"*/legacy_*"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := f.Apply(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.ToCreate) != 1 || res.ToCreate[0].FileName != "web/add_to_cart.yaml" || len(res.ToUpdateNewVersion) != 0 {
+		t.Fatalf("unexpected changes %+v", res)
+	}
+
+	abs, err := filepath.Abs("app/screen_view.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = Filter{Files: map[string]bool{abs: true}}.Apply(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.ToCreate) != 1 || res.ToCreate[0].FileName != "app/screen_view.yaml" {
+		t.Fatalf("unexpected changes %+v", res)
+	}
+
+	if _, err := NewFilter([]string{"com.acme.[web"}, nil, ""); err == nil {
+		t.Fatal("expected an invalid pattern error")
+	}
+}
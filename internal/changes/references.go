@@ -0,0 +1,209 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package changes
+
+import (
+	"context"
+	"sort"
+
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/release"
+)
+
+// StaleReference is an event specification still pinning an older version
+// of a data structure about to be published as a new version
+type StaleReference struct {
+	EventSpecId   string
+	EventSpecName string
+	DataProduct   string
+	// Location is event, tracked entity or enriched entity
+	Location string
+	OldUri   string
+	NewUri   string
+	// FileName is the local data product file defining the event
+	// specification, empty when it only exists remotely
+	FileName string
+	// Breaking is set when the new version is a new model, references are
+	// only bumped automatically across non breaking versions
+	Breaking bool
+}
+
+type schemaRef struct {
+	location string
+	source   string
+}
+
+func localRefs(es model.EventSpec) []schemaRef {
+	var refs []schemaRef
+	if es.Event.Source != "" {
+		refs = append(refs, schemaRef{"event", es.Event.Source})
+	}
+	for _, e := range es.Entities.Tracked {
+		refs = append(refs, schemaRef{"tracked entity", e.Source})
+	}
+	for _, e := range es.Entities.Enriched {
+		refs = append(refs, schemaRef{"enriched entity", e.Source})
+	}
+	return refs
+}
+
+func remoteRefs(es console.RemoteEventSpec) []schemaRef {
+	var refs []schemaRef
+	if es.Event != nil && es.Event.Source != "" {
+		refs = append(refs, schemaRef{"event", es.Event.Source})
+	}
+	for _, e := range es.Entities.Tracked {
+		refs = append(refs, schemaRef{"tracked entity", e.Source})
+	}
+	for _, e := range es.Entities.Enriched {
+		refs = append(refs, schemaRef{"enriched entity", e.Source})
+	}
+	return refs
+}
+
+// olderVersionOf returns the newest published version of the data
+// structure a reference points to an older version of, and whether it is a
+// new model
+func olderVersionOf(source string, published []model.DataStructureSelf) (model.DataStructureSelf, bool, bool) {
+	ref, err := model.ParseIgluUri(source)
+	if err != nil {
+		return model.DataStructureSelf{}, false, false
+	}
+	refVer, err := model.ParseSemVer(ref.Version)
+	if err != nil {
+		return model.DataStructureSelf{}, false, false
+	}
+	var newest model.DataStructureSelf
+	var newestVer *model.SemVersion
+	for _, p := range published {
+		if p.Vendor != ref.Vendor || p.Name != ref.Name || p.Format != ref.Format {
+			continue
+		}
+		pVer, err := model.ParseSemVer(p.Version)
+		if err != nil {
+			continue
+		}
+		if model.SemVerCmp(*refVer, *pVer) < 0 && (newestVer == nil || model.SemVerCmp(*pVer, *newestVer) > 0) {
+			newest, newestVer = p, pVer
+		}
+	}
+	if newestVer == nil {
+		return newest, false, false
+	}
+	return newest, true, newestVer.Major != refVer.Major
+}
+
+// FindStaleReferences cross references local and remote event
+// specifications against the data structures getting a new version. Local
+// event specifications take precedence over their remote counterpart
+func FindStaleReferences(changes Changes, local *release.LocalFilesRefsResolved, remote *console.DataProductsAndRelatedResources) ([]StaleReference, error) {
+	var published []model.DataStructureSelf
+	for _, ds := range changes.ToUpdateNewVersion {
+		data, err := ds.DS.ParseData()
+		if err != nil {
+			return nil, err
+		}
+		published = append(published, data.Self)
+	}
+	if len(published) == 0 {
+		return nil, nil
+	}
+
+	var res []StaleReference
+	seen := map[string]bool{}
+
+	check := func(esId string, esName string, dpName string, fileName string, refs []schemaRef) {
+		for _, r := range refs {
+			if p, ok, breaking := olderVersionOf(r.source, published); ok {
+				res = append(res, StaleReference{
+					EventSpecId:   esId,
+					EventSpecName: esName,
+					DataProduct:   dpName,
+					Location:      r.location,
+					OldUri:        r.source,
+					NewUri:        p.IgluUri(),
+					FileName:      fileName,
+					Breaking:      breaking,
+				})
+			}
+		}
+	}
+
+	if local != nil {
+		for _, dp := range local.DataProudcts {
+			for _, es := range dp.Data.EventSpecifications {
+				seen[es.ResourceName] = true
+				check(es.ResourceName, es.Name, dp.Data.Name, local.IdToFileName[es.ResourceName], localRefs(es))
+			}
+		}
+	}
+
+	if remote != nil {
+		dpNames := map[string]string{}
+		for _, dp := range remote.DataProducts {
+			dpNames[dp.Id] = dp.Name
+		}
+		for _, es := range remote.EventSpecs {
+			if seen[es.Id] {
+				continue
+			}
+			check(es.Id, es.Name, dpNames[es.DataProductId], "", remoteRefs(es))
+		}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].DataProduct != res[j].DataProduct {
+			return res[i].DataProduct < res[j].DataProduct
+		}
+		return res[i].EventSpecName < res[j].EventSpecName
+	})
+
+	return res, nil
+}
+
+func PrintStaleReferences(ctx context.Context, refs []StaleReference) {
+	logger := logging.LoggerFromContext(ctx)
+
+	for _, r := range refs {
+		args := []any{
+			"data product", r.DataProduct, "event specification", r.EventSpecName,
+			"as", r.Location, "pinned", r.OldUri, "new", r.NewUri,
+		}
+		if r.FileName != "" {
+			args = append(args, "file", r.FileName)
+		} else {
+			args = append(args, "remote only", true)
+		}
+		if r.Breaking {
+			args = append(args, "new model", true)
+		}
+		logger.Warn("references previous version", args...)
+	}
+}
+
+// ReferenceBumps are the uri replacements, by local file, pointing stale
+// references at the new version. Remote only references and references
+// across a new model are left out
+func ReferenceBumps(refs []StaleReference) map[string]map[string]string {
+	replacements := map[string]map[string]string{}
+	for _, r := range refs {
+		if r.FileName == "" || r.Breaking {
+			continue
+		}
+		if replacements[r.FileName] == nil {
+			replacements[r.FileName] = map[string]string{}
+		}
+		replacements[r.FileName][r.OldUri] = r.NewUri
+	}
+	return replacements
+}
@@ -0,0 +1,82 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package changes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/release"
+)
+
+func Test_FindStaleReferences(t *testing.T) {
+	changes := Changes{
+		ToUpdateNewVersion: []model.DSChangeContext{{
+			DS: model.DataStructure{Data: map[string]any{
+				"$schema": "http://iglucentral.com/schemas/com.snowplowanalytics.self-desc/schema/jsonschema/1-0-0#",
+				"self":    map[string]any{"vendor": "com.acme", "name": "checkout", "format": "jsonschema", "version": "1-1-0"},
+			}},
+		}},
+	}
+
+	local := &release.LocalFilesRefsResolved{
+		DataProudcts: []model.DataProduct{{
+			ResourceName: "dp-1",
+			Data: model.DataProductData{
+				Name: "Shop",
+				EventSpecifications: []model.EventSpec{
+					{ResourceName: "es-1", Name: "old", Event: model.SchemaRef{Source: "iglu:com.acme/checkout/jsonschema/1-0-0"}},
+					{ResourceName: "es-2", Name: "current", Event: model.SchemaRef{Source: "iglu:com.acme/checkout/jsonschema/1-1-0"}},
+					{ResourceName: "es-4", Name: "previous model", Event: model.SchemaRef{Source: "iglu:com.acme/checkout/jsonschema/0-1-0"}},
+				},
+			},
+		}},
+		IdToFileName: map[string]string{"es-1": "shop.yaml", "es-2": "shop.yaml", "es-4": "shop.yaml"},
+	}
+
+	remote := &console.DataProductsAndRelatedResources{
+		DataProducts: []console.RemoteDataProduct{{Id: "dp-2", Name: "Remote"}},
+		EventSpecs: []console.RemoteEventSpec{
+			// local definition wins
+			{Id: "es-2", Name: "current", Event: &console.EventWrapper{Event: console.Event{Source: "iglu:com.acme/checkout/jsonschema/1-0-0"}}},
+			{Id: "es-3", Name: "remote", DataProductId: "dp-2", Entities: console.Entities{
+				Tracked: []console.Entity{{Source: "iglu:com.acme/checkout/jsonschema/1-0-1"}},
+			}},
+		},
+	}
+
+	refs, err := FindStaleReferences(changes, local, remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(refs) != 3 {
+		t.Fatalf("expected 3 stale references, got %+v", refs)
+	}
+
+	if refs[0].EventSpecId != "es-3" || refs[0].FileName != "" || refs[0].Location != "tracked entity" {
+		t.Fatalf("unexpected remote reference %+v", refs[0])
+	}
+	if refs[1].EventSpecId != "es-1" || refs[1].FileName != "shop.yaml" || refs[1].NewUri != "iglu:com.acme/checkout/jsonschema/1-1-0" || refs[1].Breaking {
+		t.Fatalf("unexpected local reference %+v", refs[1])
+	}
+	if refs[2].EventSpecId != "es-4" || !refs[2].Breaking {
+		t.Fatalf("expected a new model reference %+v", refs[2])
+	}
+
+	bumps := ReferenceBumps(refs)
+	expected := map[string]map[string]string{"shop.yaml": {"iglu:com.acme/checkout/jsonschema/1-0-0": "iglu:com.acme/checkout/jsonschema/1-1-0"}}
+	if !reflect.DeepEqual(bumps, expected) {
+		t.Fatalf("expected only the non breaking local reference bumped, got %v", bumps)
+	}
+}
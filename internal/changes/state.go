@@ -0,0 +1,120 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package changes
+
+import (
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/state"
+)
+
+// remoteRecord is the state of a data structure deployed to env, as found
+// in the listing
+func remoteRecord(remote console.ListResponse, env console.DataStructureEnv) (state.Resource, bool, error) {
+	for _, d := range remote.Deployments {
+		if d.Env != env {
+			continue
+		}
+		hash, err := state.Hash(map[string]any{"contentHash": d.ContentHash, "meta": remote.Meta})
+		if err != nil {
+			return state.Resource{}, false, err
+		}
+		return state.Resource{Name: remote.Name, Version: d.Version, Hash: hash}, true, nil
+	}
+	return state.Resource{}, false, nil
+}
+
+func listingById(listing []console.ListResponse) map[DataStructureId]console.ListResponse {
+	result := map[DataStructureId]console.ListResponse{}
+	for _, r := range listing {
+		result[DataStructureId{r.Vendor, r.Name, r.Format}] = r
+	}
+	return result
+}
+
+// WarnRemoteChanges warns about the data structures the changes update that
+// were changed in Console since the last publish or download
+func WarnRemoteChanges(changes Changes, listing []console.ListResponse, env console.DataStructureEnv, st *state.State) error {
+	remotes := listingById(listing)
+	warned := map[string]bool{}
+
+	for _, c := range slices.Concat(changes.ToUpdateMeta, changes.ToUpdateNewVersion, changes.ToUpdatePatch) {
+		data, err := c.DS.ParseData()
+		if err != nil {
+			return err
+		}
+		key := state.DataStructureKey(string(env), data.Self.Vendor, data.Self.Name, data.Self.Format)
+		remote, ok := remotes[idFromSelf(data.Self)]
+		if !ok || warned[key] {
+			continue
+		}
+		r, deployed, err := remoteRecord(remote, env)
+		if err != nil {
+			return err
+		}
+		if !deployed {
+			continue
+		}
+		if synced, changed := state.Changed(st.DataStructures, key, r.Hash); changed {
+			warned[key] = true
+			slog.Warn("publish", "msg", "data structure was changed in Console since the last sync, the changes will be overwritten",
+				"file", c.FileName, "remote version", r.Version, "last synced version", synced.Version, "last synced", synced.SyncedAt.Format(time.RFC3339))
+		}
+	}
+
+	return nil
+}
+
+// RecordDataStructures records the deployment to env of the local data
+// structures, from the listing fetched once they were published
+func RecordDataStructures(locals map[string]model.DataStructure, listing []console.ListResponse, env console.DataStructureEnv, st *state.State) error {
+	remotes := listingById(listing)
+	syncedAt := time.Now().UTC()
+
+	var files []string
+	for f := range locals {
+		files = append(files, f)
+	}
+	slices.Sort(files)
+
+	for _, f := range files {
+		data, err := locals[f].ParseData()
+		if err != nil {
+			return err
+		}
+		remote, ok := remotes[idFromSelf(data.Self)]
+		if !ok {
+			continue
+		}
+		r, deployed, err := remoteRecord(remote, env)
+		if err != nil {
+			return err
+		}
+		if !deployed {
+			continue
+		}
+		// with several versions locally the file of the deployed one is kept
+		key := state.DataStructureKey(string(env), data.Self.Vendor, data.Self.Name, data.Self.Format)
+		if existing, ok := st.DataStructures[key]; ok && existing.SyncedAt.Equal(syncedAt) && data.Self.Version != r.Version {
+			continue
+		}
+		r.File = filepath.ToSlash(f)
+		r.SyncedAt = syncedAt
+		st.DataStructures[key] = r
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package changes
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	. "github.com/snowplow/snowplow-cli/internal/console"
+	. "github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/state"
+)
+
+func Test_RecordAndWarnRemoteChanges(t *testing.T) {
+	local := DataStructure{
+		Meta: DataStructureMeta{SchemaType: "event"},
+		Data: map[string]any{
+			"self": map[string]any{
+				"vendor":  "com.acme",
+				"name":    "checkout",
+				"format":  "jsonschema",
+				"version": "1-0-1",
+			},
+			"schema": "string"},
+	}
+	listing := func(hash string) []ListResponse {
+		return []ListResponse{{
+			Vendor:      "com.acme",
+			Name:        "checkout",
+			Format:      "jsonschema",
+			Meta:        DataStructureMeta{SchemaType: "event"},
+			Deployments: []Deployment{{Version: "1-0-0", Env: DEV, ContentHash: hash}},
+		}}
+	}
+
+	st, err := state.Load(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordDataStructures(map[string]DataStructure{"checkout.yaml": local}, listing("synced"), DEV, st); err != nil {
+		t.Fatal(err)
+	}
+	r, ok := st.DataStructures["DEV:com.acme/checkout/jsonschema"]
+	if !ok || r.Version != "1-0-0" || r.File != "checkout.yaml" || r.SyncedAt.IsZero() {
+		t.Fatalf("unexpected state %+v", st.DataStructures)
+	}
+
+	var logOutput bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logOutput, nil)))
+	defer slog.SetDefault(defaultLogger)
+
+	changes := Changes{ToUpdateNewVersion: []DSChangeContext{NewDSChangeContextWithVersion(local, "checkout.yaml", "1-0-0")}}
+
+	if err := WarnRemoteChanges(changes, listing("synced"), DEV, st); err != nil {
+		t.Fatal(err)
+	}
+	if logOutput.Len() != 0 {
+		t.Fatalf("expected no warning got %s", logOutput.String())
+	}
+
+	if err := WarnRemoteChanges(changes, listing("edited in console"), DEV, st); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logOutput.String(), "changed in Console since the last sync") {
+		t.Fatalf("expected a warning got %s", logOutput.String())
+	}
+}
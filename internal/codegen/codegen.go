@@ -0,0 +1,253 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package codegen
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/release"
+)
+
+type Cardinality struct {
+	Min int
+	// Max is nil when the number of entities is unbounded
+	Max *int
+}
+
+type EntityParam struct {
+	Name        string
+	Source      string
+	TypeName    string
+	Cardinality Cardinality
+}
+
+type TrackingFunction struct {
+	Name            string
+	EventSpecName   string
+	EventSpecId     string
+	Description     string
+	DataProductName string
+	EventSource     string
+	EventTypeName   string
+	Entities        []EntityParam
+	Triggers        []model.Trigger
+	AppIds          []string
+}
+
+type PayloadType struct {
+	Name   string
+	Source string
+	Schema map[string]any
+}
+
+type TrackingPlan struct {
+	Functions []TrackingFunction
+	Types     []PayloadType
+}
+
+// BuildTrackingPlan walks every event specification of the resolved data
+// products and produces one tracking function per event specification.
+// Payload types are taken from the local data structures when available,
+// falling back to the schema defined inline on the event specification.
+func BuildTrackingPlan(local *release.LocalFilesRefsResolved, dataStructures map[string]model.DataStructure) TrackingPlan {
+	dsByUri := map[string]map[string]any{}
+	for _, ds := range dataStructures {
+		data, err := ds.ParseData()
+		if err != nil {
+			continue
+		}
+		dsByUri[data.Self.IgluUri()] = ds.Data
+	}
+
+	saAppIds := map[string][]string{}
+	for _, sa := range local.SourceApps {
+		saAppIds[sa.ResourceName] = sa.Data.AppIds
+	}
+
+	dps := slices.Clone(local.DataProudcts)
+	sort.SliceStable(dps, func(i, j int) bool {
+		return dps[i].Data.Name < dps[j].Data.Name
+	})
+
+	types := newTypeRegistry(dsByUri)
+	usedNames := map[string]int{}
+	var functions []TrackingFunction
+
+	for _, dp := range dps {
+		var dpSaIds []string
+		for _, sa := range dp.Data.SourceApplications {
+			dpSaIds = append(dpSaIds, sa["id"])
+		}
+
+		for _, es := range dp.Data.EventSpecifications {
+			if es.Event.Source == "" {
+				slog.Warn("codegen", "msg", "skipping event specification without an event", "name", es.Name, "data product", dp.Data.Name)
+				continue
+			}
+
+			var excluded []string
+			for _, esa := range es.ExcludedSourceApplications {
+				excluded = append(excluded, esa["id"])
+			}
+
+			appIds := []string{}
+			for _, saId := range dpSaIds {
+				if slices.Contains(excluded, saId) {
+					continue
+				}
+				for _, appId := range saAppIds[saId] {
+					if !slices.Contains(appIds, appId) {
+						appIds = append(appIds, appId)
+					}
+				}
+			}
+
+			name := "track" + pascalCase(es.Name)
+			usedNames[name]++
+			if usedNames[name] > 1 {
+				name = fmt.Sprintf("%s%d", name, usedNames[name])
+			}
+
+			eventTypeName := types.register(es.Event.Source, es.Event.Schema)
+
+			entityNames := map[string]int{}
+			var entities []EntityParam
+			for _, ent := range es.Entities.Tracked {
+				self, err := model.ParseIgluUri(ent.Source)
+				paramName := "entity"
+				if err == nil {
+					paramName = camelCase(self.Name)
+				}
+				entityNames[paramName]++
+				if entityNames[paramName] > 1 {
+					paramName = fmt.Sprintf("%s%d", paramName, entityNames[paramName])
+				}
+
+				min := 0
+				if ent.MinCardinality != nil {
+					min = *ent.MinCardinality
+				}
+
+				entities = append(entities, EntityParam{
+					Name:        paramName,
+					Source:      ent.Source,
+					TypeName:    types.register(ent.Source, ent.Schema),
+					Cardinality: Cardinality{Min: min, Max: ent.MaxCardinality},
+				})
+			}
+
+			functions = append(functions, TrackingFunction{
+				Name:            name,
+				EventSpecName:   es.Name,
+				EventSpecId:     es.ResourceName,
+				Description:     es.Description,
+				DataProductName: dp.Data.Name,
+				EventSource:     es.Event.Source,
+				EventTypeName:   eventTypeName,
+				Entities:        entities,
+				Triggers:        es.Triggers,
+				AppIds:          appIds,
+			})
+		}
+	}
+
+	return TrackingPlan{Functions: functions, Types: types.types}
+}
+
+type typeRegistry struct {
+	dsByUri   map[string]map[string]any
+	byUri     map[string]string
+	usedNames map[string]bool
+	types     []PayloadType
+}
+
+func newTypeRegistry(dsByUri map[string]map[string]any) *typeRegistry {
+	return &typeRegistry{dsByUri: dsByUri, byUri: map[string]string{}, usedNames: map[string]bool{}}
+}
+
+func (r *typeRegistry) register(uri string, inline map[string]any) string {
+	if name, ok := r.byUri[uri]; ok {
+		return name
+	}
+
+	name := "Payload"
+	self, err := model.ParseIgluUri(uri)
+	if err == nil {
+		name = pascalCase(self.Name)
+	}
+	if r.usedNames[name] && err == nil {
+		name = fmt.Sprintf("%sV%s", name, strings.ReplaceAll(self.Version, "-", "_"))
+	}
+	base := name
+	for i := 2; r.usedNames[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+
+	schema, ok := r.dsByUri[uri]
+	if !ok {
+		schema = inline
+	}
+
+	r.byUri[uri] = name
+	r.usedNames[name] = true
+	r.types = append(r.types, PayloadType{Name: name, Source: uri, Schema: schema})
+	return name
+}
+
+func splitWords(s string) []string {
+	var words []string
+	var current []rune
+	runes := []rune(s)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(current) > 0 {
+				words = append(words, string(current))
+				current = nil
+			}
+			continue
+		}
+		if unicode.IsUpper(r) && len(current) > 0 && i > 0 && unicode.IsLower(runes[i-1]) {
+			words = append(words, string(current))
+			current = nil
+		}
+		current = append(current, r)
+	}
+	if len(current) > 0 {
+		words = append(words, string(current))
+	}
+	return words
+}
+
+func pascalCase(s string) string {
+	var b strings.Builder
+	for _, w := range splitWords(s) {
+		runes := []rune(strings.ToLower(w))
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	res := b.String()
+	if res == "" || unicode.IsDigit([]rune(res)[0]) {
+		res = "X" + res
+	}
+	return res
+}
+
+func camelCase(s string) string {
+	p := []rune(pascalCase(s))
+	p[0] = unicode.ToLower(p[0])
+	return string(p)
+}
@@ -0,0 +1,197 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/release"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func testLocal() *release.LocalFilesRefsResolved {
+	return &release.LocalFilesRefsResolved{
+		SourceApps: []model.SourceApp{
+			{ResourceName: "sa-web", Data: model.SourceAppData{Name: "Web", AppIds: []string{"web", "web-qa"}}},
+			{ResourceName: "sa-ios", Data: model.SourceAppData{Name: "iOS", AppIds: []string{"ios"}}},
+		},
+		DataProudcts: []model.DataProduct{{
+			ResourceName: "dp-1",
+			Data: model.DataProductData{
+				Name:               "Checkout",
+				SourceApplications: []map[string]string{{"id": "sa-web"}, {"id": "sa-ios"}},
+				EventSpecifications: []model.EventSpec{
+					{
+						ResourceName:               "es-1",
+						Name:                       "checkout started",
+						ExcludedSourceApplications: []map[string]string{{"id": "sa-ios"}},
+						Triggers:                   []model.Trigger{{Description: "user clicks checkout", AppIds: []string{"web"}}},
+						Event:                      model.SchemaRef{Source: "iglu:com.acme/checkout_started/jsonschema/1-0-0"},
+						Entities: model.EntitiesDef{Tracked: []model.SchemaRef{
+							{Source: "iglu:com.acme/user/jsonschema/1-0-0", MinCardinality: intPtr(1), MaxCardinality: intPtr(1)},
+							{Source: "iglu:com.acme/product/jsonschema/1-0-0", MinCardinality: intPtr(1)},
+							{Source: "iglu:com.acme/promo/jsonschema/1-0-0", MinCardinality: intPtr(0), MaxCardinality: intPtr(2)},
+						}},
+					},
+					{
+						ResourceName: "es-2",
+						Name:         "no event",
+					},
+				},
+			},
+		}},
+	}
+}
+
+func Test_BuildTrackingPlan(t *testing.T) {
+	ds := map[string]model.DataStructure{
+		"file": {Data: map[string]any{
+			"self": map[string]any{"vendor": "com.acme", "name": "checkout_started", "format": "jsonschema", "version": "1-0-0"},
+			"type": "object",
+			"properties": map[string]any{
+				"total": map[string]any{"type": "number"},
+			},
+		}},
+	}
+
+	plan := BuildTrackingPlan(testLocal(), ds)
+
+	if len(plan.Functions) != 1 {
+		t.Fatalf("expected 1 function, got %d", len(plan.Functions))
+	}
+
+	f := plan.Functions[0]
+	if f.Name != "trackCheckoutStarted" {
+		t.Fatalf("unexpected function name %s", f.Name)
+	}
+	if strings.Join(f.AppIds, ",") != "web,web-qa" {
+		t.Fatalf("unexpected app ids %v", f.AppIds)
+	}
+	if len(f.Entities) != 3 || f.Entities[0].Name != "user" || f.Entities[0].Cardinality.Min != 1 {
+		t.Fatalf("unexpected entities %+v", f.Entities)
+	}
+	if len(plan.Types) != 4 || plan.Types[0].Schema == nil {
+		t.Fatalf("unexpected types %+v", plan.Types)
+	}
+}
+
+func Test_RenderTypescript(t *testing.T) {
+	plan := BuildTrackingPlan(testLocal(), map[string]model.DataStructure{})
+	out := RenderTypescript(plan)
+
+	expected := []string{
+		"export function trackCheckoutStarted(event: CheckoutStarted, entities: { user: User; product: [Product, ...Product[]]; promo?: [Promo?, Promo?] }, trackers?: Array<string>): void {",
+		"context.push({ schema: 'iglu:com.acme/user/jsonschema/1-0-0', data: entities.user });",
+		"for (const data of entities.product ?? []) {",
+		" * - user clicks checkout (app ids: web)",
+		" * App ids: web, web-qa",
+		"export type CheckoutStarted = Record<string, unknown>;",
+	}
+
+	for _, e := range expected {
+		if !strings.Contains(out, e) {
+			t.Fatalf("expected output to contain %q\n%s", e, out)
+		}
+	}
+}
+
+func Test_RenderTypescriptEscapesComments(t *testing.T) {
+	plan := TrackingPlan{
+		Types: []PayloadType{{
+			Name:   "Checkout",
+			Source: "iglu:com.acme/checkout/jsonschema/1-0-0*/ alert(1)",
+			Schema: map[string]any{
+				"description": "ends here */ export const a = 1;\nexport const b = 2;",
+				"properties": map[string]any{
+					"total": map[string]any{"type": "number", "description": "total */ price\r\nexport const c = 3;"},
+				},
+			},
+		}},
+		Functions: []TrackingFunction{{
+			Name:          "trackCheckout",
+			EventSpecName: "checkout\nexport const d = 4;",
+			Description:   "first line\n*/ export const e = 5;",
+			EventSource:   "iglu:com.acme/checkout/jsonschema/1-0-0\n*/",
+			EventTypeName: "Checkout",
+		}},
+	}
+	out := RenderTypescript(plan)
+
+	expected := []string{
+		" * iglu:com.acme/checkout/jsonschema/1-0-0*\\/ alert(1)\n",
+		" * ends here *\\/ export const a = 1; export const b = 2;\n",
+		"/** total *\\/ price export const c = 3; */\n",
+		" * Event specification: checkout export const d = 4;\n",
+		" * first line *\\/ export const e = 5;\n",
+		" * @see iglu:com.acme/checkout/jsonschema/1-0-0 *\\/\n",
+	}
+	for _, e := range expected {
+		if !strings.Contains(out, e) {
+			t.Fatalf("expected output to contain %q\n%s", e, out)
+		}
+	}
+
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "export const") {
+			t.Fatalf("description escaped its comment: %q\n%s", line, out)
+		}
+	}
+}
+
+func Test_EntityFieldType(t *testing.T) {
+	cases := []struct {
+		c        Cardinality
+		expected string
+		optional bool
+	}{
+		{Cardinality{Min: 1, Max: intPtr(1)}, "T", false},
+		{Cardinality{Min: 0, Max: intPtr(1)}, "T", true},
+		{Cardinality{Min: 0}, "Array<T>", true},
+		{Cardinality{Min: 2}, "[T, T, ...T[]]", false},
+		{Cardinality{Min: 1, Max: intPtr(3)}, "[T, T?, T?]", false},
+	}
+
+	for _, c := range cases {
+		res, optional := entityFieldType(EntityParam{TypeName: "T", Cardinality: c.c})
+		if res != c.expected || optional != c.optional {
+			t.Fatalf("for %+v expected %s (%v) got %s (%v)", c.c, c.expected, c.optional, res, optional)
+		}
+	}
+}
+
+func Test_TsType(t *testing.T) {
+	schema := map[string]any{
+		"type":     "object",
+		"required": []any{"id"},
+		"properties": map[string]any{
+			"id":        map[string]any{"type": "string"},
+			"kind":      map[string]any{"enum": []any{"a", "b"}},
+			"tags":      map[string]any{"type": []any{"array", "null"}, "items": map[string]any{"type": "string"}},
+			"odd-field": map[string]any{"type": "integer"},
+		},
+	}
+
+	expected := `{
+  id: string;
+  kind?: "a" | "b";
+  "odd-field"?: number;
+  tags?: Array<string> | null;
+}`
+
+	if res := tsType(schema, ""); res != expected {
+		t.Fatalf("unexpected type\n%s", res)
+	}
+}
@@ -0,0 +1,295 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// tuples longer than this are rendered as open ended arrays
+const maxTupleLength = 16
+
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func RenderTypescript(plan TrackingPlan) string {
+	var b strings.Builder
+
+	b.WriteString("// Code generated by snowplow-cli. DO NOT EDIT.\n\n")
+	b.WriteString("import { trackSelfDescribingEvent, SelfDescribingJson } from '@snowplow/browser-tracker';\n")
+
+	for _, t := range plan.Types {
+		b.WriteString("\n")
+		renderPayloadType(&b, t)
+	}
+
+	for _, f := range plan.Functions {
+		b.WriteString("\n")
+		renderFunction(&b, f)
+	}
+
+	return b.String()
+}
+
+func renderPayloadType(b *strings.Builder, t PayloadType) {
+	fmt.Fprintf(b, "/**\n * %s\n", sanitizeComment(t.Source))
+	if desc, ok := t.Schema["description"].(string); ok && desc != "" {
+		fmt.Fprintf(b, " *\n * %s\n", sanitizeComment(desc))
+	}
+	b.WriteString(" */\n")
+
+	if _, hasProps := t.Schema["properties"]; hasProps {
+		fmt.Fprintf(b, "export interface %s %s\n", t.Name, tsObject(t.Schema, ""))
+	} else {
+		fmt.Fprintf(b, "export type %s = %s;\n", t.Name, tsType(t.Schema, ""))
+	}
+}
+
+func renderFunction(b *strings.Builder, f TrackingFunction) {
+	b.WriteString("/**\n")
+	fmt.Fprintf(b, " * Event specification: %s\n", sanitizeComment(f.EventSpecName))
+	fmt.Fprintf(b, " * Data product: %s\n", sanitizeComment(f.DataProductName))
+	if f.Description != "" {
+		fmt.Fprintf(b, " *\n * %s\n", sanitizeComment(f.Description))
+	}
+	if len(f.Triggers) > 0 {
+		b.WriteString(" *\n * Triggers:\n")
+		for _, t := range f.Triggers {
+			line := t.Description
+			if len(t.AppIds) > 0 {
+				line += fmt.Sprintf(" (app ids: %s)", strings.Join(t.AppIds, ", "))
+			}
+			if t.Url != "" {
+				line += fmt.Sprintf(" (url: %s)", t.Url)
+			}
+			fmt.Fprintf(b, " * - %s\n", sanitizeComment(line))
+		}
+	}
+	if len(f.AppIds) > 0 {
+		fmt.Fprintf(b, " *\n * App ids: %s\n", sanitizeComment(strings.Join(f.AppIds, ", ")))
+	}
+	fmt.Fprintf(b, " *\n * @see %s\n */\n", sanitizeComment(f.EventSource))
+
+	params := []string{fmt.Sprintf("event: %s", f.EventTypeName)}
+
+	var fields []string
+	anyRequired := false
+	for _, e := range f.Entities {
+		if e.Cardinality.Max != nil && *e.Cardinality.Max == 0 {
+			continue
+		}
+		fieldType, optional := entityFieldType(e)
+		if optional {
+			fields = append(fields, fmt.Sprintf("%s?: %s", e.Name, fieldType))
+		} else {
+			anyRequired = true
+			fields = append(fields, fmt.Sprintf("%s: %s", e.Name, fieldType))
+		}
+	}
+	if len(fields) > 0 {
+		entitiesType := fmt.Sprintf("{ %s }", strings.Join(fields, "; "))
+		if anyRequired {
+			params = append(params, fmt.Sprintf("entities: %s", entitiesType))
+		} else {
+			params = append(params, fmt.Sprintf("entities: %s = {}", entitiesType))
+		}
+	}
+	params = append(params, "trackers?: Array<string>")
+
+	fmt.Fprintf(b, "export function %s(%s): void {\n", f.Name, strings.Join(params, ", "))
+	b.WriteString("  const context: SelfDescribingJson[] = [];\n")
+	for _, e := range f.Entities {
+		if e.Cardinality.Max != nil && *e.Cardinality.Max == 0 {
+			continue
+		}
+		_, optional := entityFieldType(e)
+		switch {
+		case isSingle(e.Cardinality) && !optional:
+			fmt.Fprintf(b, "  context.push({ schema: '%s', data: entities.%s });\n", e.Source, e.Name)
+		case isSingle(e.Cardinality):
+			fmt.Fprintf(b, "  if (entities.%s !== undefined) {\n    context.push({ schema: '%s', data: entities.%s });\n  }\n", e.Name, e.Source, e.Name)
+		default:
+			fmt.Fprintf(b, "  for (const data of entities.%s ?? []) {\n    context.push({ schema: '%s', data });\n  }\n", e.Name, e.Source)
+		}
+	}
+	fmt.Fprintf(b, "  trackSelfDescribingEvent({ event: { schema: '%s', data: event }, context }, trackers);\n", f.EventSource)
+	b.WriteString("}\n")
+}
+
+func isSingle(c Cardinality) bool {
+	return c.Max != nil && *c.Max == 1
+}
+
+// entityFieldType encodes the entity cardinality in the parameter type,
+// using a tuple with the minimum number of required elements followed by
+// either optional elements up to the maximum, or a rest element when
+// the maximum is unbounded.
+func entityFieldType(e EntityParam) (string, bool) {
+	c := e.Cardinality
+	t := e.TypeName
+
+	if isSingle(c) {
+		return t, c.Min == 0
+	}
+	if c.Min == 0 && c.Max == nil {
+		return fmt.Sprintf("Array<%s>", t), true
+	}
+
+	elems := []string{}
+	for range c.Min {
+		elems = append(elems, t)
+	}
+	if c.Max == nil || *c.Max > maxTupleLength {
+		elems = append(elems, fmt.Sprintf("...%s[]", t))
+	} else {
+		for i := c.Min; i < *c.Max; i++ {
+			elems = append(elems, t+"?")
+		}
+	}
+
+	return fmt.Sprintf("[%s]", strings.Join(elems, ", ")), c.Min == 0
+}
+
+func tsType(schema map[string]any, indent string) string {
+	if schema == nil {
+		return "Record<string, unknown>"
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && len(enum) > 0 {
+		literals := []string{}
+		for _, v := range enum {
+			lit, err := json.Marshal(v)
+			if err == nil {
+				literals = append(literals, string(lit))
+			}
+		}
+		return strings.Join(literals, " | ")
+	}
+
+	var types []string
+	switch t := schema["type"].(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+
+	if len(types) == 0 {
+		if _, ok := schema["properties"]; ok {
+			return tsObject(schema, indent)
+		}
+		return "unknown"
+	}
+
+	out := []string{}
+	for _, t := range types {
+		var ts string
+		switch t {
+		case "string":
+			ts = "string"
+		case "integer", "number":
+			ts = "number"
+		case "boolean":
+			ts = "boolean"
+		case "null":
+			ts = "null"
+		case "array":
+			items, _ := schema["items"].(map[string]any)
+			if items == nil {
+				ts = "Array<unknown>"
+			} else {
+				ts = fmt.Sprintf("Array<%s>", tsType(items, indent))
+			}
+		case "object":
+			if _, ok := schema["properties"]; ok {
+				ts = tsObject(schema, indent)
+			} else {
+				ts = "Record<string, unknown>"
+			}
+		default:
+			ts = "unknown"
+		}
+		if !slices.Contains(out, ts) {
+			out = append(out, ts)
+		}
+	}
+
+	return strings.Join(out, " | ")
+}
+
+func tsObject(schema map[string]any, indent string) string {
+	props, _ := schema["properties"].(map[string]any)
+	if len(props) == 0 {
+		return "{}"
+	}
+
+	required := map[string]bool{}
+	if req, ok := schema["required"].([]any); ok {
+		for _, r := range req {
+			if s, ok := r.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	inner := indent + "  "
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, k := range keys {
+		prop, _ := props[k].(map[string]any)
+		if desc, ok := prop["description"].(string); ok && desc != "" {
+			fmt.Fprintf(&b, "%s/** %s */\n", inner, sanitizeComment(desc))
+		}
+		name := k
+		if !tsIdentifier.MatchString(k) {
+			quoted, _ := json.Marshal(k)
+			name = string(quoted)
+		}
+		optional := "?"
+		if required[k] {
+			optional = ""
+		}
+		fmt.Fprintf(&b, "%s%s%s: %s;\n", inner, name, optional, tsType(prop, inner))
+	}
+	b.WriteString(indent + "}")
+
+	return b.String()
+}
+
+// commentEscaper keeps schema text inside the doc comment it is rendered
+// into. Line terminators would end a line comment or drop the leading
+// asterisk and a closing delimiter would end a block comment early
+var commentEscaper = strings.NewReplacer(
+	"*/", "*\\/",
+	"\r\n", " ",
+	"\r", " ",
+	"\n", " ",
+	"\u2028", " ",
+	"\u2029", " ",
+)
+
+func sanitizeComment(s string) string {
+	return commentEscaper.Replace(s)
+}
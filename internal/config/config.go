@@ -36,7 +36,17 @@ func InitConsoleFlags(cmd *cobra.Command) {
 }
 
 type rawAppConfig struct {
-	Console map[string]string
+	Console  map[string]string
+	Profiles map[string]map[string]string
+}
+
+// Profile is a named set of Console settings, eg: to work with more than
+// one organization
+type Profile struct {
+	Host     string
+	ApiKeyId string
+	ApiKey   string
+	OrgId    string
 }
 
 func loadEnvFiles(cmd *cobra.Command, baseDir string) error {
@@ -110,45 +120,7 @@ func initConsoleConfigWithOptions(cmd *cobra.Command, skipMissingCheck bool, bas
 		return fmt.Errorf("failed to load .env file: %w", err)
 	}
 
-	var configBytes []byte
-	var err error
-	var potentialConfigs []string
-
-	if configFileName, _ := cmd.Flags().GetString("config"); configFileName != "" {
-		potentialConfigs = append(potentialConfigs, configFileName)
-	}
-
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
-
-	userConfigDir, err := os.UserConfigDir()
-	if err != nil {
-		return err
-	}
-
-	configDir := filepath.Join(userConfigDir, "snowplow", "snowplow.yml")
-	unixish := filepath.Join(home, ".config", "snowplow", "snowplow.yml")
-
-	paths := []string{unixish, configDir}
-
-	potentialConfigs = append(potentialConfigs, paths...)
-
-	slog.Debug("looking for config at", "paths", strings.Join(potentialConfigs, "\n"))
-
-	for _, p := range potentialConfigs {
-		configBytes, err = os.ReadFile(p)
-		if err != nil {
-			slog.Debug("config not found at", "file", p, "err", err)
-		} else {
-			slog.Debug("config found at", "file", p)
-			break
-		}
-	}
-
-	var config rawAppConfig
-	err = yaml.Unmarshal(configBytes, &config)
+	config, err := readConfigFile(cmd)
 	if err != nil {
 		return err
 	}
@@ -217,6 +189,97 @@ Get API credentials at: https://docs.snowplow.io/docs/using-the-snowplow-console
 	return nil
 }
 
+// readConfigFile reads the first config file found, --config first
+func readConfigFile(cmd *cobra.Command) (rawAppConfig, error) {
+	var configBytes []byte
+	var err error
+	var potentialConfigs []string
+
+	if configFileName, _ := cmd.Flags().GetString("config"); configFileName != "" {
+		potentialConfigs = append(potentialConfigs, configFileName)
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return rawAppConfig{}, err
+	}
+
+	userConfigDir, err := os.UserConfigDir()
+	if err != nil {
+		return rawAppConfig{}, err
+	}
+
+	configDir := filepath.Join(userConfigDir, "snowplow", "snowplow.yml")
+	unixish := filepath.Join(home, ".config", "snowplow", "snowplow.yml")
+
+	paths := []string{unixish, configDir}
+
+	potentialConfigs = append(potentialConfigs, paths...)
+
+	slog.Debug("looking for config at", "paths", strings.Join(potentialConfigs, "\n"))
+
+	for _, p := range potentialConfigs {
+		configBytes, err = os.ReadFile(p)
+		if err != nil {
+			slog.Debug("config not found at", "file", p, "err", err)
+		} else {
+			slog.Debug("config found at", "file", p)
+			break
+		}
+	}
+
+	var config rawAppConfig
+	err = yaml.Unmarshal(configBytes, &config)
+	return config, err
+}
+
+// LoadProfile reads the named profile from the profiles section of the
+// config file. SNOWPLOW_CONSOLE_PROFILE_<PROFILE>_<NAME> environment
+// variables override its values and the host defaults to the --host default
+func LoadProfile(cmd *cobra.Command, name string) (Profile, error) {
+	config, err := readConfigFile(cmd)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	values := map[string]string{}
+	for k, v := range config.Profiles[name] {
+		values[k] = v
+	}
+	for _, k := range []string{"api-key-id", "api-key", "host", "org-id"} {
+		if value, ok := os.LookupEnv(toProfileEnvName(name, k)); ok && value != "" {
+			values[k] = value
+			slog.Debug("profile value found in env", "profile", name, "env", toProfileEnvName(name, k))
+		}
+	}
+	if values["host"] == "" {
+		if f := cmd.Flags().Lookup("host"); f != nil {
+			values["host"] = f.DefValue
+		}
+	}
+
+	var missingVars []string
+	for _, k := range []string{"api-key-id", "api-key", "host", "org-id"} {
+		if values[k] == "" {
+			missingVars = append(missingVars, k)
+		}
+	}
+	if len(missingVars) > 0 {
+		return Profile{}, fmt.Errorf(`profile "%s" values not set: %s
+
+Profiles can be provided via:
+  1. Config file (snowplow.yml): profiles.%s.<name>: <value>
+  2. Environment variables: %s=<value>`, name, strings.Join(missingVars, ", "), name, toProfileEnvName(name, "<name>"))
+	}
+
+	return Profile{
+		Host:     values["host"],
+		ApiKeyId: values["api-key-id"],
+		ApiKey:   values["api-key"],
+		OrgId:    values["org-id"],
+	}, nil
+}
+
 func PersistConfig(orgID, apiKeyID, apiKeySecret, consoleHost string, isDotEnv bool) error {
 	if isDotEnv {
 		return SaveDotenvFile(orgID, apiKeyID, apiKeySecret, consoleHost)
@@ -319,3 +382,7 @@ var getConfigPath = func() string {
 func toEnvName(s string) string {
 	return envNamePrefix + strings.ReplaceAll(strings.ToUpper(s), "-", "_")
 }
+
+func toProfileEnvName(profile string, s string) string {
+	return toEnvName("profile-" + profile + "-" + s)
+}
@@ -13,6 +13,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/joho/godotenv"
@@ -122,6 +123,65 @@ func Test_ConfigValidate(t *testing.T) {
 	}
 }
 
+func Test_LoadProfile(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "snowplow.yml")
+	err := os.WriteFile(configFile, []byte(`
+profiles:
+  staging:
+    api-key-id: staging-key-id
+    api-key: staging-key
+    org-id: staging-org
+    host: https://staging.example.com
+  prod:
+    api-key-id: prod-key-id
+    org-id: prod-org
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(old []string) { os.Args = old }(os.Args)
+	os.Args = []string{"xxx", "--config", configFile, "-a", "x", "-S", "x", "-o", "x"}
+
+	loadProfile := func(name string) (Profile, error) {
+		var profile Profile
+		var err error
+		testCmd := build()
+		testCmd.Run = func(cmd *cobra.Command, args []string) {
+			profile, err = LoadProfile(cmd, name)
+		}
+		if err := testCmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		return profile, err
+	}
+
+	staging, err := loadProfile("staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if staging != (Profile{Host: "https://staging.example.com", ApiKeyId: "staging-key-id", ApiKey: "staging-key", OrgId: "staging-org"}) {
+		t.Errorf("unexpected profile %+v", staging)
+	}
+
+	if _, err := loadProfile("prod"); err == nil || !strings.Contains(err.Error(), "api-key") {
+		t.Errorf("expected the missing api key to be reported, got %v", err)
+	}
+
+	t.Setenv("SNOWPLOW_CONSOLE_PROFILE_PROD_API_KEY", "prod-key")
+	prod, err := loadProfile("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prod != (Profile{Host: "https://console.snowplowanalytics.com", ApiKeyId: "prod-key-id", ApiKey: "prod-key", OrgId: "prod-org"}) {
+		t.Errorf("unexpected profile %+v", prod)
+	}
+
+	if _, err := loadProfile("missing"); err == nil {
+		t.Error("expected an unknown profile to fail")
+	}
+}
+
 func Test_ConfigFromEnvFile(t *testing.T) {
 	defer func(old []string) { os.Args = old }(os.Args)
 
@@ -78,6 +78,9 @@ type fullMeta struct {
 	Hidden      *bool              `json:"hidden,omitempty"`
 	SchemaType  string             `json:"schemaType,omitempty"`
 	CustomData  *map[string]string `json:"customData,omitempty"`
+	Deprecated  *bool              `json:"deprecated,omitempty"`
+	ReplacedBy  string             `json:"replacedBy,omitempty"`
+	SunsetDate  string             `json:"sunsetDate,omitempty"`
 	LockStatus  string             `json:"lockStatus,omitempty"`
 	ManagedFrom string             `json:"managedFrom,omitempty"`
 }
@@ -305,6 +308,16 @@ func GetDataStructureDeployments(cnx context.Context, client *ApiClient, dsHash
 }
 
 func GetAllDataStructures(cnx context.Context, client *ApiClient, match []string, includeLegacy bool) ([]model.DataStructure, error) {
+	return getAllDataStructures(cnx, client, match, includeLegacy, false)
+}
+
+// GetAllDataStructureVersions is GetAllDataStructures returning every
+// published version rather than only the latest one
+func GetAllDataStructureVersions(cnx context.Context, client *ApiClient, match []string, includeLegacy bool) ([]model.DataStructure, error) {
+	return getAllDataStructures(cnx, client, match, includeLegacy, true)
+}
+
+func getAllDataStructures(cnx context.Context, client *ApiClient, match []string, includeLegacy bool, allVersions bool) ([]model.DataStructure, error) {
 
 	listResp, err := GetDataStructureListing(cnx, client)
 	if err != nil {
@@ -316,7 +329,12 @@ func GetAllDataStructures(cnx context.Context, client *ApiClient, match []string
 	var skippedCount int
 	var includedLegacyCount int
 
-	req, err := http.NewRequestWithContext(cnx, "GET", fmt.Sprintf("%s/data-structures/v1/schemas/versions?latest=true", client.BaseUrl), nil)
+	url := fmt.Sprintf("%s/data-structures/v1/schemas/versions?latest=true", client.BaseUrl)
+	if allVersions {
+		url = fmt.Sprintf("%s/data-structures/v1/schemas/versions", client.BaseUrl)
+	}
+
+	req, err := http.NewRequestWithContext(cnx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -338,9 +356,15 @@ func GetAllDataStructures(cnx context.Context, client *ApiClient, match []string
 	}
 
 	dsDataMap := map[string]map[string]any{}
+	dsVersionsMap := map[string][]map[string]any{}
 	for _, ds := range dsData {
 		if self, ok := ds["self"].(map[string]any); ok {
-			dsDataMap[fmt.Sprintf("%s-%s-%s-%s", self["vendor"], self["name"], self["format"], self["version"])] = ds
+			versionKey := fmt.Sprintf("%s-%s-%s-%s", self["vendor"], self["name"], self["format"], self["version"])
+			if _, seen := dsDataMap[versionKey]; !seen {
+				key := fmt.Sprintf("%s-%s-%s", self["vendor"], self["name"], self["format"])
+				dsVersionsMap[key] = append(dsVersionsMap[key], ds)
+			}
+			dsDataMap[versionKey] = ds
 		} else {
 			return nil, fmt.Errorf("wrong data structure self section %s", ds["self"])
 		}
@@ -361,21 +385,33 @@ func GetAllDataStructures(cnx context.Context, client *ApiClient, match []string
 			continue
 		}
 
+		versionsAdded := false
 		for _, deployment := range dsResp.Deployments {
 			if deployment.Env == DEV {
+				meta := dsResp.Meta
+				if !meta.IsDeprecated() {
+					meta.Deprecated = nil
+				}
 				if dsResp.Meta.SchemaType == "" {
 					if !includeLegacy {
 						skippedCount++
 						continue
 					} else {
 						includedLegacyCount++
-						meta := dsResp.Meta
 						meta.SchemaType = "entity"
-						dataStructure := model.DataStructure{ApiVersion: "v1", ResourceType: "data-structure", Meta: meta, Data: dsDataMap[fmt.Sprintf("%s-%s-%s-%s", dsResp.Vendor, dsResp.Name, dsResp.Format, deployment.Version)]}
-						res = append(res, dataStructure)
+					}
+				}
+				if allVersions {
+					// every version is listed once, whichever versions are deployed
+					if versionsAdded {
+						continue
+					}
+					versionsAdded = true
+					for _, data := range dsVersionsMap[fmt.Sprintf("%s-%s-%s", dsResp.Vendor, dsResp.Name, dsResp.Format)] {
+						res = append(res, model.DataStructure{ApiVersion: "v1", ResourceType: "data-structure", Meta: meta, Data: data})
 					}
 				} else {
-					dataStructure := model.DataStructure{ApiVersion: "v1", ResourceType: "data-structure", Meta: dsResp.Meta, Data: dsDataMap[fmt.Sprintf("%s-%s-%s-%s", dsResp.Vendor, dsResp.Name, dsResp.Format, deployment.Version)]}
+					dataStructure := model.DataStructure{ApiVersion: "v1", ResourceType: "data-structure", Meta: meta, Data: dsDataMap[fmt.Sprintf("%s-%s-%s-%s", dsResp.Vendor, dsResp.Name, dsResp.Format, deployment.Version)]}
 					res = append(res, dataStructure)
 				}
 			}
@@ -461,6 +497,9 @@ func MetadateUpdate(cnx context.Context, client *ApiClient, ds *model.DataStruct
 		Hidden:      &ds.Meta.Hidden,
 		SchemaType:  ds.Meta.SchemaType,
 		CustomData:  &ds.Meta.CustomData,
+		Deprecated:  ds.Meta.Deprecated,
+		ReplacedBy:  ds.Meta.ReplacedBy,
+		SunsetDate:  ds.Meta.SunsetDate,
 		LockStatus:  "locked",
 		ManagedFrom: managedFrom,
 	}
@@ -17,6 +17,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -580,6 +581,14 @@ func Test_GetAllDataStructuresDraftsOk(t *testing.T) {
 func Test_MetadataUpdate_Ok(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/api/msc/v1/organizations/orgid/data-structures/v1/20308fa345d397de04f26a34a6083744d06ae1aeb673e1658b0b50a7a86ea395/meta" {
+			var body map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			// deprecation is left as is in console when the file does not set it
+			if _, ok := body["deprecated"]; ok {
+				t.Fatalf("unexpected deprecated in %v", body)
+			}
 			w.WriteHeader(http.StatusOK)
 			return
 		}
@@ -939,3 +948,51 @@ func TestGetAllDataStructures_EmptySchemaType(t *testing.T) {
 		t.Errorf("legacy data structure with empty schemaType should be converted to 'entity': got '%s'", legacyDS.Meta.SchemaType)
 	}
 }
+
+func Test_GetAllDataStructureVersionsDeduplicates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/msc/v1/organizations/orgid/data-structures/v1":
+			_, _ = io.WriteString(w, `[{
+				"hash": "h",
+				"vendor": "com.acme",
+				"name": "checkout",
+				"format": "jsonschema",
+				"meta": {"hidden": false, "schemaType": "event", "customData": {}},
+				"deployments": [
+					{"version": "1-0-1", "env": "DEV", "contentHash": "b"},
+					{"version": "1-0-0", "env": "DEV", "contentHash": "a"},
+					{"version": "1-0-1", "env": "PROD", "contentHash": "b"}
+				]
+			}]`)
+		case "/api/msc/v1/organizations/orgid/data-structures/v1/schemas/versions":
+			_, _ = io.WriteString(w, `[
+				{"self": {"vendor": "com.acme", "name": "checkout", "format": "jsonschema", "version": "1-0-0"}},
+				{"self": {"vendor": "com.acme", "name": "checkout", "format": "jsonschema", "version": "1-0-1"}},
+				{"self": {"vendor": "com.acme", "name": "checkout", "format": "jsonschema", "version": "1-0-1"}}
+			]`)
+		default:
+			t.Errorf("Unexpected request, got: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := &ApiClient{Http: &http.Client{}, Jwt: "token", BaseUrl: fmt.Sprintf("%s/api/msc/v1/organizations/orgid", server.URL)}
+
+	result, err := GetAllDataStructureVersions(context.Background(), client, []string{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var versions []string
+	for _, ds := range result {
+		data, err := ds.ParseData()
+		if err != nil {
+			t.Fatal(err)
+		}
+		versions = append(versions, data.Self.Version)
+	}
+	if !reflect.DeepEqual(versions, []string{"1-0-0", "1-0-1"}) {
+		t.Fatalf("expected every version once, got %v", versions)
+	}
+}
@@ -136,6 +136,16 @@ func fetchDestinations(cnx context.Context, client *ApiClient) ([]destination, e
 
 }
 
+// HasDestinations reports whether the organization has destinations, schema
+// migrations are only checked against them
+func HasDestinations(cnx context.Context, client *ApiClient) (bool, error) {
+	destinations, err := fetchDestinations(cnx, client)
+	if err != nil {
+		return false, err
+	}
+	return len(destinations) > 0, nil
+}
+
 func ValidateMigrations(cnx context.Context, client *ApiClient, ds model.DSChangeContext) (map[string]MigrationReport, error) {
 
 	destinations, err := fetchDestinations(cnx, client)
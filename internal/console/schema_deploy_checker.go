@@ -16,6 +16,9 @@ import (
 	"log/slog"
 	"slices"
 	"strings"
+	"sync"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
 )
 
 var builtInSchema = []string{
@@ -25,6 +28,13 @@ var builtInSchema = []string{
 
 type SchemaDeployChecker interface {
 	IsDSDeployed(uri string) (found bool, deployedVersions []string, err error)
+	DeprecationChecker
+}
+
+// DeprecationChecker resolves the deprecation metadata of deployed data
+// structures, deprecation applies to every version of a data structure
+type DeprecationChecker interface {
+	DeprecatedMeta(uri string) (meta model.DataStructureMeta, deprecated bool)
 }
 
 type schemaDeployCheckProvider struct {
@@ -99,6 +109,21 @@ func (sdc *schemaDeployCheckProvider) IsDSDeployed(uri string) (found bool, depl
 	return false, nil, nil
 }
 
+func (sdc *schemaDeployCheckProvider) DeprecatedMeta(uri string) (model.DataStructureMeta, bool) {
+	self, err := model.ParseIgluUri(uri)
+	if err != nil {
+		return model.DataStructureMeta{}, false
+	}
+
+	for _, ds := range sdc.dsList {
+		if ds.Vendor == self.Vendor && ds.Name == self.Name && ds.Format == self.Format {
+			return ds.Meta, ds.Meta.Deprecates(self)
+		}
+	}
+
+	return model.DataStructureMeta{}, false
+}
+
 func NewSchemaDeployChecker(cnx context.Context, c *ApiClient) (SchemaDeployChecker, error) {
 	igluCentral, err := GetIgluCentralListing(cnx, c)
 	if err != nil {
@@ -110,11 +135,27 @@ func NewSchemaDeployChecker(cnx context.Context, c *ApiClient) (SchemaDeployChec
 		return nil, err
 	}
 
+	// deployments are cached for the lifetime of the checker, the lock is
+	// not held while fetching so concurrent lookups of a hash may both fetch
+	var mu sync.Mutex
+	deployments := map[string][]Deployment{}
 	lookup := func(hash string) ([]Deployment, error) {
+		mu.Lock()
+		deploys, ok := deployments[hash]
+		mu.Unlock()
+		if ok {
+			return deploys, nil
+		}
+
 		deploys, err := GetDataStructureDeployments(cnx, c, hash)
 		if err != nil {
 			return nil, err
 		}
+
+		mu.Lock()
+		deployments[hash] = deploys
+		mu.Unlock()
+
 		return deploys, nil
 	}
 
@@ -15,6 +15,8 @@ import (
 	"slices"
 	"sort"
 	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
 )
 
 func Test_IsDeployed_Iglu(t *testing.T) {
@@ -158,3 +160,39 @@ func Test_IsDeployed_ConsoleAlternativesFail(t *testing.T) {
 		t.Fatal("should have errored")
 	}
 }
+
+func Test_DeprecatedMeta(t *testing.T) {
+	deprecated := true
+	mock := &schemaDeployCheckProvider{
+		[]string{},
+		[]ListResponse{{
+			Vendor: "vendor",
+			Name:   "name",
+			Format: "format",
+			Meta:   model.DataStructureMeta{Deprecated: &deprecated, ReplacedBy: "iglu:vendor/name/format/2-0-0"},
+		}, {
+			Vendor: "vendor",
+			Name:   "other",
+			Format: "format",
+		}},
+		func(h string) ([]Deployment, error) {
+			return nil, nil
+		},
+	}
+
+	if meta, deprecated := mock.DeprecatedMeta("iglu:vendor/name/format/1-0-0"); !deprecated || meta.ReplacedBy != "iglu:vendor/name/format/2-0-0" {
+		t.Fatal("expected 1-0-0 to be deprecated", meta)
+	}
+	if _, deprecated := mock.DeprecatedMeta("iglu:vendor/name/format/2-0-0"); deprecated {
+		t.Fatal("replacement version should not be deprecated")
+	}
+	if _, deprecated := mock.DeprecatedMeta("iglu:vendor/name/format/2-0-1"); deprecated {
+		t.Fatal("versions after the replacement should not be deprecated")
+	}
+	if _, deprecated := mock.DeprecatedMeta("iglu:vendor/name/format/1-1-0"); !deprecated {
+		t.Fatal("expected 1-1-0 to be deprecated")
+	}
+	if _, deprecated := mock.DeprecatedMeta("iglu:vendor/other/format/1-0-0"); deprecated {
+		t.Fatal("other should not be deprecated")
+	}
+}
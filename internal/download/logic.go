@@ -15,6 +15,8 @@ import (
 
 	"github.com/snowplow/snowplow-cli/internal/console"
 	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/release"
+	"github.com/snowplow/snowplow-cli/internal/state"
 	"github.com/snowplow/snowplow-cli/internal/util"
 )
 
@@ -48,13 +50,34 @@ func DownloadDataProductsAndRelatedResources(files util.Files, cnx context.Conte
 		return err
 	}
 
-	_, err = files.CreateDataProducts(dps, isPlain)
+	fileNameToDp, err := files.CreateDataProducts(dps, isPlain)
 	if err != nil {
 		return err
 	}
 
 	slog.Info("download", "msg", "wrote data products", "count", len(dps))
-	return nil
+
+	if files.StateDir == "" {
+		return nil
+	}
+	idToFileName := map[string]string{}
+	for f, sa := range fileNameToSa {
+		idToFileName[sa.ResourceName] = f
+	}
+	for f, dp := range fileNameToDp {
+		idToFileName[dp.ResourceName] = f
+		for _, es := range dp.Data.EventSpecifications {
+			idToFileName[es.ResourceName] = f
+		}
+	}
+	st, err := state.Load(files.StateDir)
+	if err != nil {
+		return err
+	}
+	if err := release.RecordRemote(*res, idToFileName, st); err != nil {
+		return err
+	}
+	return st.Save(files.StateDir)
 }
 
 func downloadTriggerImages(remoteEss []console.RemoteEventSpec, cnx context.Context, client *console.ApiClient, files util.Files) (map[string]string, error) {
@@ -87,7 +110,20 @@ func downloadTriggerImages(remoteEss []console.RemoteEventSpec, cnx context.Cont
 }
 
 func GetDataStructuresWithOptions(ctx context.Context, client *console.ApiClient, includeDrafts bool, match []string, includeLegacy bool) ([]model.DataStructure, error) {
-	dss, err := console.GetAllDataStructures(ctx, client, match, includeLegacy)
+	return getDataStructures(ctx, client, includeDrafts, match, includeLegacy, false)
+}
+
+func GetAllDataStructureVersionsWithOptions(ctx context.Context, client *console.ApiClient, includeDrafts bool, match []string, includeLegacy bool) ([]model.DataStructure, error) {
+	return getDataStructures(ctx, client, includeDrafts, match, includeLegacy, true)
+}
+
+func getDataStructures(ctx context.Context, client *console.ApiClient, includeDrafts bool, match []string, includeLegacy bool, allVersions bool) ([]model.DataStructure, error) {
+	getAll := console.GetAllDataStructures
+	if allVersions {
+		getAll = console.GetAllDataStructureVersions
+	}
+
+	dss, err := getAll(ctx, client, match, includeLegacy)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,80 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+func run(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// ChangedFiles returns the absolute paths of the files changed since the
+// merge base of ref and HEAD in the repository of the working directory,
+// uncommitted and untracked files included. Changes made on ref after the
+// branch point are not reported. Deleted files are left out
+func ChangedFiles(ref string) (map[string]bool, error) {
+	top, err := run("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	top = strings.TrimSpace(top)
+
+	base, err := run("merge-base", ref, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	base = strings.TrimSpace(base)
+
+	diff, err := run("diff", "--name-only", "--diff-filter=d", "-z", base, "--")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := run("ls-files", "--others", "--exclude-standard", "--full-name", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]bool{}
+	for _, f := range strings.Split(diff+untracked, "\x00") {
+		if f != "" {
+			files[filepath.Join(top, filepath.FromSlash(f))] = true
+		}
+	}
+	return files, nil
+}
+
+// AbsPath is the absolute path of fileName as it would be found in the
+// result of ChangedFiles
+func AbsPath(fileName string) (string, error) {
+	abs, err := filepath.Abs(fileName)
+	if err != nil {
+		return "", err
+	}
+	// the repository root has its symlinks resolved
+	dir, err := filepath.EvalSymlinks(filepath.Dir(abs))
+	if err != nil {
+		return abs, nil
+	}
+	return filepath.Join(dir, filepath.Base(abs)), nil
+}
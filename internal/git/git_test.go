@@ -0,0 +1,96 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestChangedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	write := func(name string, content string) {
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git := func(args ...string) {
+		if _, err := run(args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "test")
+	write("ds/unchanged.yaml", "a")
+	write("ds/committed.yaml", "a")
+	write("ds/modified.yaml", "a")
+	write("ds/deleted.yaml", "a")
+	git("add", ".")
+	git("commit", "-q", "-m", "base")
+	git("tag", "base")
+	git("branch", "-M", "main")
+
+	git("checkout", "-q", "-b", "feature")
+	write("ds/committed.yaml", "b")
+	git("commit", "-q", "-am", "change")
+
+	// main moves on after the branch point, which must not count as a change
+	git("checkout", "-q", "main")
+	write("ds/upstream.yaml", "a")
+	git("add", ".")
+	git("commit", "-q", "-m", "upstream")
+	git("checkout", "-q", "feature")
+
+	write("ds/modified.yaml", "b")
+	write("ds/untracked.yaml", "b")
+	if err := os.Remove("ds/deleted.yaml"); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Chdir("ds")
+	files, err := ChangedFiles("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, expected := range map[string]bool{
+		"unchanged.yaml": false,
+		"committed.yaml": true,
+		"modified.yaml":  true,
+		"untracked.yaml": true,
+		"deleted.yaml":   false,
+		"upstream.yaml":  false,
+	} {
+		abs, err := AbsPath(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if files[abs] != expected {
+			t.Errorf("%s changed = %v, want %v (%v)", name, files[abs], expected, files)
+		}
+	}
+
+	if _, err := ChangedFiles("no-such-ref"); err == nil {
+		t.Fatal("expected an error for an unknown ref")
+	}
+}
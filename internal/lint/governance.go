@@ -0,0 +1,214 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+// Governance holds the organisation specific settings of the data product
+// and source application rules
+type Governance struct {
+	Domains              []string `yaml:"domains"`
+	MinDescriptionLength int      `yaml:"minDescriptionLength"`
+}
+
+// DataProducts are all the data products being validated, keyed by file,
+// so rules can look across data products
+type DataProducts map[string]model.DataProduct
+
+type DataProductRule struct {
+	Id          string
+	Description string
+	Severity    Severity
+	Check       func(g Governance, dp model.DataProduct, all DataProducts) []Violation
+}
+
+type SourceAppRule struct {
+	Id          string
+	Description string
+	Severity    Severity
+	Check       func(g Governance, sa model.SourceApp) []Violation
+}
+
+// Governance rules are organisation specific, they are off until enabled
+// in the lint config
+var DataProductRules = []DataProductRule{
+	{
+		Id:          "dp-owner-required",
+		Description: "Data products have an owner",
+		Severity:    SeverityOff,
+		Check: func(g Governance, dp model.DataProduct, all DataProducts) []Violation {
+			if strings.TrimSpace(dp.Data.Owner) != "" {
+				return nil
+			}
+			return []Violation{{Path: []any{"data", "owner"}, Message: "owner is required"}}
+		},
+	},
+	{
+		Id:          "dp-domain-allowed",
+		Description: "Data products have a domain from governance.domains",
+		Severity:    SeverityOff,
+		Check: func(g Governance, dp model.DataProduct, all DataProducts) []Violation {
+			path := []any{"data", "domain"}
+			if strings.TrimSpace(dp.Data.Domain) == "" {
+				return []Violation{{Path: path, Message: "domain is required"}}
+			}
+			if len(g.Domains) > 0 && !slices.Contains(g.Domains, dp.Data.Domain) {
+				return []Violation{{Path: path, Message: fmt.Sprintf("domain %s is not one of the allowed domains %v", dp.Data.Domain, g.Domains)}}
+			}
+			return nil
+		},
+	},
+	{
+		Id:          "es-trigger-image",
+		Description: "Event specifications have at least one trigger with an image",
+		Severity:    SeverityOff,
+		Check: func(g Governance, dp model.DataProduct, all DataProducts) []Violation {
+			var result []Violation
+			for i, es := range dp.Data.EventSpecifications {
+				hasImage := slices.ContainsFunc(es.Triggers, func(t model.Trigger) bool {
+					return t.Image != nil && t.Image.Ref != ""
+				})
+				if !hasImage {
+					result = append(result, Violation{
+						Path:    []any{"data", "eventSpecifications", i, "triggers"},
+						Message: fmt.Sprintf("event specification %s needs at least one trigger with an image", es.Name),
+					})
+				}
+			}
+			return result
+		},
+	},
+	{
+		Id:          "dp-description-length",
+		Description: "Data products and event specifications have a description of at least governance.minDescriptionLength characters",
+		Severity:    SeverityOff,
+		Check: func(g Governance, dp model.DataProduct, all DataProducts) []Violation {
+			var result []Violation
+			if v, ok := descriptionCheck(g, dp.Data.Description, []any{"data", "description"}); !ok {
+				result = append(result, v)
+			}
+			for i, es := range dp.Data.EventSpecifications {
+				if v, ok := descriptionCheck(g, es.Description, []any{"data", "eventSpecifications", i, "description"}); !ok {
+					result = append(result, v)
+				}
+			}
+			return result
+		},
+	},
+	{
+		Id:          "es-unique-name",
+		Description: "Event specification names are unique across data products",
+		Severity:    SeverityOff,
+		Check: func(g Governance, dp model.DataProduct, all DataProducts) []Violation {
+			var files []string
+			for f := range all {
+				files = append(files, f)
+			}
+			sort.Strings(files)
+
+			var result []Violation
+			for i, es := range dp.Data.EventSpecifications {
+				var usedIn []string
+				for _, f := range files {
+					other := all[f]
+					for j, oes := range other.Data.EventSpecifications {
+						sameSpec := other.ResourceName == dp.ResourceName && i == j
+						if !sameSpec && oes.Name == es.Name {
+							usedIn = append(usedIn, other.Data.Name)
+						}
+					}
+				}
+				if len(usedIn) > 0 {
+					result = append(result, Violation{
+						Path:    []any{"data", "eventSpecifications", i, "name"},
+						Message: fmt.Sprintf("event specification name %s is also used in %s", es.Name, strings.Join(usedIn, ", ")),
+					})
+				}
+			}
+			return result
+		},
+	},
+}
+
+var SourceAppRules = []SourceAppRule{
+	{
+		Id:          "sa-app-ids-required",
+		Description: "Source applications declare at least one app id",
+		Severity:    SeverityOff,
+		Check: func(g Governance, sa model.SourceApp) []Violation {
+			if len(sa.Data.AppIds) > 0 {
+				return nil
+			}
+			return []Violation{{Path: []any{"data", "appIds"}, Message: "at least one app id is required"}}
+		},
+	},
+	{
+		Id:          "sa-description-length",
+		Description: "Source applications have a description of at least governance.minDescriptionLength characters",
+		Severity:    SeverityOff,
+		Check: func(g Governance, sa model.SourceApp) []Violation {
+			if v, ok := descriptionCheck(g, sa.Data.Description, []any{"data", "description"}); !ok {
+				return []Violation{v}
+			}
+			return nil
+		},
+	},
+}
+
+func descriptionCheck(g Governance, description string, path []any) (Violation, bool) {
+	minLength := max(g.MinDescriptionLength, 1)
+	length := len([]rune(strings.TrimSpace(description)))
+	if length >= minLength {
+		return Violation{}, true
+	}
+	if length == 0 {
+		return Violation{Path: path, Message: "description is required"}, false
+	}
+	return Violation{Path: path, Message: fmt.Sprintf("description should be at least %d characters, got %d", minLength, length)}, false
+}
+
+// RunDataProduct checks a data product against the enabled data product rules
+func (c Config) RunDataProduct(fileName string, dp model.DataProduct, all DataProducts) []Finding {
+	var findings []Finding
+	for _, r := range DataProductRules {
+		severity := c.severityOf(r.Id, r.Severity)
+		if severity == SeverityOff {
+			continue
+		}
+		for _, v := range r.Check(c.Governance, dp, all) {
+			findings = append(findings, Finding{File: fileName, Rule: r.Id, Severity: severity, Path: jsonPointer(v.Path), Message: v.Message})
+		}
+	}
+	return findings
+}
+
+// RunSourceApp checks a source application against the enabled source
+// application rules
+func (c Config) RunSourceApp(fileName string, sa model.SourceApp) []Finding {
+	var findings []Finding
+	for _, r := range SourceAppRules {
+		severity := c.severityOf(r.Id, r.Severity)
+		if severity == SeverityOff {
+			continue
+		}
+		for _, v := range r.Check(c.Governance, sa) {
+			findings = append(findings, Finding{File: fileName, Rule: r.Id, Severity: severity, Path: jsonPointer(v.Path), Message: v.Message})
+		}
+	}
+	return findings
+}
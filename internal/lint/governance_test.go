@@ -0,0 +1,117 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package lint
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+const governanceConfig = `rules:
+  dp-owner-required: error
+  dp-domain-allowed: error
+  dp-description-length: warning
+  es-trigger-image: warning
+  es-unique-name: error
+  sa-app-ids-required: error
+governance:
+  domains: [marketing, checkout]
+  minDescriptionLength: 10
+`
+
+func findingStrings(findings []Finding) []string {
+	var result []string
+	for _, f := range findings {
+		result = append(result, string(f.Severity)+" "+f.String())
+	}
+	return result
+}
+
+func Test_RunDataProduct(t *testing.T) {
+	config, err := ParseConfig([]byte(governanceConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkout := model.DataProduct{
+		ResourceName: "dp1",
+		Data: model.DataProductData{
+			Name:        "Checkout",
+			Domain:      "sales",
+			Description: "short",
+			EventSpecifications: []model.EventSpec{{
+				Name:        "Order placed",
+				Description: "an order was placed",
+				Triggers:    []model.Trigger{{Description: "button", Image: &model.Ref{Ref: "./images/button.png"}}},
+			}},
+		},
+	}
+	marketing := model.DataProduct{
+		ResourceName: "dp2",
+		Data: model.DataProductData{
+			Name:        "Marketing",
+			Owner:       "team@acme.com",
+			Domain:      "marketing",
+			Description: "marketing campaigns",
+			EventSpecifications: []model.EventSpec{{
+				Name:        "Order placed",
+				Description: "an order placed from a campaign",
+				Triggers:    []model.Trigger{{Description: "no image"}},
+			}},
+		},
+	}
+	all := DataProducts{"checkout.yaml": checkout, "marketing.yaml": marketing}
+
+	got := findingStrings(config.RunDataProduct("checkout.yaml", checkout, all))
+	expected := []string{
+		"error [dp-owner-required] /data/owner: owner is required",
+		"error [dp-domain-allowed] /data/domain: domain sales is not one of the allowed domains [marketing checkout]",
+		"warning [dp-description-length] /data/description: description should be at least 10 characters, got 5",
+		"error [es-unique-name] /data/eventSpecifications/0/name: event specification name Order placed is also used in Marketing",
+	}
+	if !slices.Equal(got, expected) {
+		t.Fatalf("unexpected findings\n%#v", got)
+	}
+
+	got = findingStrings(config.RunDataProduct("marketing.yaml", marketing, all))
+	expected = []string{
+		"warning [es-trigger-image] /data/eventSpecifications/0/triggers: event specification Order placed needs at least one trigger with an image",
+		"error [es-unique-name] /data/eventSpecifications/0/name: event specification name Order placed is also used in Checkout",
+	}
+	if !slices.Equal(got, expected) {
+		t.Fatalf("unexpected findings\n%#v", got)
+	}
+}
+
+func Test_RunSourceApp(t *testing.T) {
+	config, err := ParseConfig([]byte(governanceConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := findingStrings(config.RunSourceApp("web.yaml", model.SourceApp{Data: model.SourceAppData{Name: "web"}}))
+	expected := []string{"error [sa-app-ids-required] /data/appIds: at least one app id is required"}
+	if !slices.Equal(got, expected) {
+		t.Fatalf("unexpected findings\n%#v", got)
+	}
+}
+
+func Test_GovernanceOffByDefault(t *testing.T) {
+	config := Config{}
+	if f := config.RunSourceApp("web.yaml", model.SourceApp{}); len(f) != 0 {
+		t.Fatalf("expected no findings got %v", f)
+	}
+	if f := config.RunDataProduct("dp.yaml", model.DataProduct{}, DataProducts{}); len(f) != 0 {
+		t.Fatalf("expected no findings got %v", f)
+	}
+}
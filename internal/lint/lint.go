@@ -0,0 +1,421 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package lint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/policy"
+)
+
+const ConfigFile = ".snowplow-lint.yml"
+
+const disableDirective = "snowplow-lint-disable"
+
+type Severity string
+
+const (
+	SeverityOff     Severity = "off"
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = policy.SeverityWarning
+	SeverityError   Severity = policy.SeverityError
+)
+
+func ParseSeverity(s string) (Severity, error) {
+	switch Severity(s) {
+	case SeverityOff, SeverityInfo, SeverityWarning, SeverityError:
+		return Severity(s), nil
+	}
+	return "", fmt.Errorf("unknown severity %s, expected one of off|info|warning|error", s)
+}
+
+// Node is a schema visited by the rules, Name is set when the schema is
+// the value of a property
+type Node struct {
+	Path   []any
+	Name   string
+	Schema map[string]any
+}
+
+type Rule struct {
+	Id          string
+	Description string
+	Severity    Severity
+	Check       func(n Node) []Violation
+}
+
+// Violation is reported by a rule, Path is relative to the visited node
+type Violation struct {
+	Path    []any
+	Message string
+}
+
+type Finding struct {
+	File     string
+	Rule     string
+	Severity Severity
+	Path     string
+	Message  string
+}
+
+func (f Finding) String() string {
+	if f.Path == "" {
+		return fmt.Sprintf("[%s] %s", f.Rule, f.Message)
+	}
+	return fmt.Sprintf("[%s] %s: %s", f.Rule, f.Path, f.Message)
+}
+
+type Config struct {
+	Rules      map[string]Severity
+	Governance Governance
+	Policies   []policy.Policy
+}
+
+type rawConfig struct {
+	Rules      map[string]string `yaml:"rules"`
+	Governance Governance        `yaml:"governance"`
+	Policies   string            `yaml:"policies"`
+}
+
+// LoadConfig reads rule severities from a lint config file, a missing
+// file results in the built in defaults. The policies directory is
+// relative to the config file
+func LoadConfig(fileName string) (Config, error) {
+	config := Config{Rules: map[string]Severity{}}
+
+	file, err := os.ReadFile(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+
+	config, policiesDir, err := parseConfig(file)
+	if err != nil {
+		return config, err
+	}
+
+	if policiesDir != "" {
+		if !filepath.IsAbs(policiesDir) {
+			policiesDir = filepath.Join(filepath.Dir(fileName), policiesDir)
+		}
+		config.Policies, err = policy.LoadDir(policiesDir)
+		if err != nil {
+			return config, err
+		}
+	}
+
+	return config, nil
+}
+
+func ParseConfig(file []byte) (Config, error) {
+	config, _, err := parseConfig(file)
+	return config, err
+}
+
+func parseConfig(file []byte) (Config, string, error) {
+	config := Config{Rules: map[string]Severity{}}
+
+	var raw rawConfig
+	if err := yaml.Unmarshal(file, &raw); err != nil {
+		return config, "", fmt.Errorf("failed to parse lint config: %w", err)
+	}
+
+	for id, s := range raw.Rules {
+		if !knownRule(id) {
+			return config, "", fmt.Errorf("unknown lint rule %s", id)
+		}
+		severity, err := ParseSeverity(s)
+		if err != nil {
+			return config, "", fmt.Errorf("lint rule %s: %w", id, err)
+		}
+		config.Rules[id] = severity
+	}
+	config.Governance = raw.Governance
+
+	return config, raw.Policies, nil
+}
+
+func (c Config) severityOf(id string, defaultSeverity Severity) Severity {
+	if s, ok := c.Rules[id]; ok {
+		return s
+	}
+	return defaultSeverity
+}
+
+// Run lints every data structure, findings are sorted by file and path
+func (c Config) Run(dss map[string]model.DataStructure) ([]Finding, error) {
+	var findings []Finding
+
+	for fileName, ds := range dss {
+		disabled, err := disabledFromFile(fileName)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, c.lint(fileName, ds, disabled)...)
+
+		policyFindings, err := c.runPolicies(fileName, ds)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, policyFindings...)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		if findings[i].Path != findings[j].Path {
+			return findings[i].Path < findings[j].Path
+		}
+		return findings[i].Rule < findings[j].Rule
+	})
+
+	return findings, nil
+}
+
+// RunFile lints a single data structure, taking inline disable comments
+// from file rather than reading it from disk, eg: an unsaved editor buffer
+func (c Config) RunFile(fileName string, file []byte, ds model.DataStructure) ([]Finding, error) {
+	var disabled []disable
+	if strings.HasSuffix(fileName, ".yaml") || strings.HasSuffix(fileName, ".yml") {
+		var err error
+		disabled, err = disabledFromYaml(file)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	findings := c.lint(fileName, ds, disabled)
+	policyFindings, err := c.runPolicies(fileName, ds)
+	if err != nil {
+		return nil, err
+	}
+	return append(findings, policyFindings...), nil
+}
+
+func (c Config) lint(fileName string, ds model.DataStructure, disabled []disable) []Finding {
+	var findings []Finding
+
+	walk(Node{Path: []any{"data"}, Schema: ds.Data}, func(n Node) {
+		for _, r := range BuiltInRules {
+			severity := c.severityOf(r.Id, r.Severity)
+			if severity == SeverityOff {
+				continue
+			}
+			for _, v := range r.Check(n) {
+				path := append(slices.Clone(n.Path), v.Path...)
+				if isDisabled(disabled, r.Id, path) {
+					continue
+				}
+				findings = append(findings, Finding{
+					File:     fileName,
+					Rule:     r.Id,
+					Severity: severity,
+					Path:     jsonPointer(path),
+					Message:  v.Message,
+				})
+			}
+		}
+	})
+
+	return findings
+}
+
+func (c Config) runPolicies(fileName string, ds model.DataStructure) ([]Finding, error) {
+	if len(c.Policies) == 0 {
+		return nil, nil
+	}
+
+	// policies see the data structure as written in its file
+	b, err := json.Marshal(ds)
+	if err != nil {
+		return nil, err
+	}
+	var resource map[string]any
+	if err := json.Unmarshal(b, &resource); err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, v := range policy.CheckAll(c.Policies, policy.DataStructure, resource) {
+		findings = append(findings, Finding{
+			File:     fileName,
+			Rule:     v.Policy,
+			Severity: Severity(v.Severity),
+			Path:     v.Path,
+			Message:  v.Message,
+		})
+	}
+	return findings, nil
+}
+
+func walk(n Node, visit func(n Node)) {
+	visit(n)
+
+	child := func(schema any, name string, path ...any) {
+		if s, ok := schema.(map[string]any); ok {
+			walk(Node{Path: append(slices.Clone(n.Path), path...), Name: name, Schema: s}, visit)
+		}
+	}
+
+	if props, ok := n.Schema["properties"].(map[string]any); ok {
+		names := make([]string, 0, len(props))
+		for k := range props {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, k := range names {
+			child(props[k], k, "properties", k)
+		}
+	}
+
+	switch items := n.Schema["items"].(type) {
+	case map[string]any:
+		child(items, "", "items")
+	case []any:
+		for i, item := range items {
+			child(item, "", "items", i)
+		}
+	}
+
+	child(n.Schema["additionalProperties"], "", "additionalProperties")
+
+	for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+		if schemas, ok := n.Schema[key].([]any); ok {
+			for i, s := range schemas {
+				child(s, "", key, i)
+			}
+		}
+	}
+}
+
+type disable struct {
+	path  string
+	rules []string
+}
+
+// disabledFromFile collects the snowplow-lint-disable comments of a yaml
+// file. A comment attached to a top level key disables rules for the whole
+// file, anywhere else it disables them for the commented node and below
+func disabledFromFile(fileName string) ([]disable, error) {
+	if !strings.HasSuffix(fileName, ".yaml") && !strings.HasSuffix(fileName, ".yml") {
+		return nil, nil
+	}
+
+	file, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	return disabledFromYaml(file)
+}
+
+func disabledFromYaml(file []byte) ([]disable, error) {
+	comments := yaml.CommentMap{}
+	var node any
+	if err := yaml.UnmarshalWithOptions(file, &node, yaml.CommentToMap(comments)); err != nil {
+		return nil, err
+	}
+
+	var result []disable
+	for path, cs := range comments {
+		for _, c := range cs {
+			for _, text := range c.Texts {
+				text = strings.TrimSpace(text)
+				if !strings.HasPrefix(text, disableDirective) {
+					continue
+				}
+				rest := strings.TrimPrefix(text, disableDirective)
+				if rest != "" && !strings.HasPrefix(rest, " ") {
+					continue
+				}
+				d := disable{path: path}
+				if strings.Count(path, ".") == 1 && !strings.Contains(path, "[") {
+					d.path = "$"
+				}
+				d.rules = strings.FieldsFunc(rest, func(r rune) bool {
+					return r == ',' || r == ' '
+				})
+				result = append(result, d)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func isDisabled(disabled []disable, rule string, path []any) bool {
+	target := yamlPath(path)
+	for _, d := range disabled {
+		if len(d.rules) > 0 && !slices.Contains(d.rules, rule) {
+			continue
+		}
+		if d.path == "$" || covers(d.path, target) {
+			return true
+		}
+		// a comment on a keyword, eg: type, applies to the schema holding it
+		if i := strings.LastIndexAny(d.path, ".["); i > 0 && d.path[:i] == target {
+			return true
+		}
+	}
+	return false
+}
+
+func covers(parent string, path string) bool {
+	if !strings.HasPrefix(path, parent) {
+		return false
+	}
+	rest := path[len(parent):]
+	return rest == "" || strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "[")
+}
+
+func yamlPath(path []any) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, p := range path {
+		switch v := p.(type) {
+		case int:
+			fmt.Fprintf(&b, "[%d]", v)
+		case string:
+			if strings.ContainsAny(v, ".*") {
+				fmt.Fprintf(&b, ".'%s'", strings.ReplaceAll(v, "'", `\'`))
+			} else {
+				b.WriteString("." + v)
+			}
+		}
+	}
+	return b.String()
+}
+
+func jsonPointer(path []any) string {
+	var b strings.Builder
+	for _, p := range path {
+		b.WriteString("/")
+		switch v := p.(type) {
+		case int:
+			fmt.Fprintf(&b, "%d", v)
+		case string:
+			b.WriteString(strings.ReplaceAll(strings.ReplaceAll(v, "~", "~0"), "/", "~1"))
+		}
+	}
+	return b.String()
+}
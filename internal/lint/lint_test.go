@@ -0,0 +1,178 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/util"
+)
+
+const testDs = `# snowplow-lint-disable no-additional-properties
+apiVersion: v1
+resourceType: data-structure
+meta:
+  hidden: false
+  schemaType: event
+  customData: {}
+data:
+  $schema: http://iglucentral.com/schemas/com.snowplowanalytics.self-desc/schema/jsonschema/1-0-0#
+  self:
+    vendor: com.acme
+    name: checkout
+    format: jsonschema
+    version: 1-0-0
+  type: object
+  additionalProperties: true
+  properties:
+    order_id:
+      description: the order
+      type: string
+      maxLength: 36
+    # snowplow-lint-disable property-snake-case
+    legacyId:
+      description: kept for compatibility
+      type: string # snowplow-lint-disable string-max-length
+    totalPrice:
+      type: number
+    status:
+      description: order status
+      type: [string, "null"]
+      enum: [open, closed]
+    address:
+      description: shipping address
+      type: object
+      properties:
+        city:
+          description: city name
+          type: [string, "null"]
+`
+
+func writeTestDs(t *testing.T) string {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "checkout.yaml")
+	if err := os.WriteFile(fileName, []byte(testDs), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func Test_Run(t *testing.T) {
+	dir := writeTestDs(t)
+	dss, _, err := util.DataStructuresFromPaths([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := ParseConfig([]byte("rules:\n  property-snake-case: error\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err := config.Run(dss)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, f := range findings {
+		got = append(got, string(f.Severity)+" "+f.String())
+	}
+
+	expected := []string{
+		"warning [string-max-length] /data/properties/address/properties/city: string without maxLength",
+		"warning [property-description] /data/properties/totalPrice: property totalPrice has no description",
+		"error [property-snake-case] /data/properties/totalPrice: property name totalPrice should be snake_case",
+	}
+
+	if !slices.Equal(got, expected) {
+		t.Fatalf("unexpected findings\n%#v", got)
+	}
+}
+
+func Test_RunRuleOff(t *testing.T) {
+	dir := writeTestDs(t)
+	dss, _, err := util.DataStructuresFromPaths([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := ParseConfig([]byte("rules:\n  property-description: off\n  string-max-length: off\n  property-snake-case: off\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err := config.Run(dss)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(findings) != 0 {
+		t.Fatalf("expected no findings got %v", findings)
+	}
+}
+
+func Test_ParseConfigErrors(t *testing.T) {
+	if _, err := ParseConfig([]byte("rules:\n  no-such-rule: error\n")); err == nil {
+		t.Fatal("expected unknown rule error")
+	}
+	if _, err := ParseConfig([]byte("rules:\n  property-snake-case: fatal\n")); err == nil {
+		t.Fatal("expected unknown severity error")
+	}
+}
+
+func Test_LoadConfigMissing(t *testing.T) {
+	config, err := LoadConfig(filepath.Join(t.TempDir(), ConfigFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Rules) != 0 {
+		t.Fatal("expected default config")
+	}
+}
+
+func Test_LoadConfigPolicies(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "policies"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "policies", "owner.yaml"), []byte("expression: has(meta.customData.owner)\nmessage: owner is required\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ConfigFile), []byte("policies: ./policies\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfig(filepath.Join(dir, ConfigFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Policies) != 1 {
+		t.Fatalf("expected a policy got %v", config.Policies)
+	}
+
+	dss, _, err := util.DataStructuresFromPaths([]string{writeTestDs(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings, err := config.Run(dss)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.ContainsFunc(findings, func(f Finding) bool {
+		return f.Rule == "owner" && f.Severity == SeverityError && f.String() == "[owner] /data: owner is required"
+	}) {
+		t.Fatalf("expected a policy finding got %v", findings)
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var snakeCase = regexp.MustCompile("^[a-z][a-z0-9]*(_[a-z0-9]+)*$")
+
+var BuiltInRules = []Rule{
+	{
+		Id:          "property-snake-case",
+		Description: "Property names are snake_case",
+		Severity:    SeverityWarning,
+		Check: func(n Node) []Violation {
+			if n.Name == "" || snakeCase.MatchString(n.Name) {
+				return nil
+			}
+			return []Violation{{Message: fmt.Sprintf("property name %s should be snake_case", n.Name)}}
+		},
+	},
+	{
+		Id:          "property-description",
+		Description: "Every property has a description",
+		Severity:    SeverityWarning,
+		Check: func(n Node) []Violation {
+			if n.Name == "" {
+				return nil
+			}
+			if d, ok := n.Schema["description"].(string); ok && strings.TrimSpace(d) != "" {
+				return nil
+			}
+			return []Violation{{Message: fmt.Sprintf("property %s has no description", n.Name)}}
+		},
+	},
+	{
+		Id:          "string-max-length",
+		Description: "String properties have a maxLength, unless restricted by enum or const",
+		Severity:    SeverityWarning,
+		Check: func(n Node) []Violation {
+			if !hasType(n.Schema, "string") {
+				return nil
+			}
+			for _, k := range []string{"maxLength", "enum", "const"} {
+				if _, ok := n.Schema[k]; ok {
+					return nil
+				}
+			}
+			return []Violation{{Message: "string without maxLength"}}
+		},
+	},
+	{
+		Id:          "no-additional-properties",
+		Description: "Objects do not allow additionalProperties: true",
+		Severity:    SeverityWarning,
+		Check: func(n Node) []Violation {
+			if ap, ok := n.Schema["additionalProperties"].(bool); ok && ap {
+				return []Violation{{Path: []any{"additionalProperties"}, Message: "additionalProperties should not be true"}}
+			}
+			return nil
+		},
+	},
+}
+
+func knownRule(id string) bool {
+	return slices.ContainsFunc(BuiltInRules, func(r Rule) bool { return r.Id == id }) ||
+		slices.ContainsFunc(DataProductRules, func(r DataProductRule) bool { return r.Id == id }) ||
+		slices.ContainsFunc(SourceAppRules, func(r SourceAppRule) bool { return r.Id == id })
+}
+
+func hasType(schema map[string]any, t string) bool {
+	switch v := schema["type"].(type) {
+	case string:
+		return v == t
+	case []any:
+		return slices.Contains(v, any(t))
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package lsp
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/snowplow/snowplow-cli/internal/lint"
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"github.com/snowplow/snowplow-cli/internal/validation"
+)
+
+const diagnosticSource = "snowplow-cli"
+
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+// diagnostics runs the local validators against a document, the console
+// is not queried so compatibility checks are left to validate
+func (s *server) diagnostics(fileName string) []Diagnostic {
+	content, _ := s.workspace.content(fileName)
+
+	var resource map[string]any
+	if err := decode(fileName, content, &resource); err != nil {
+		line := 0
+		if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+			line--
+		}
+		return []Diagnostic{{Range: lineRange(content, line, 0), Severity: SeverityError, Source: diagnosticSource, Message: err.Error()}}
+	}
+
+	positions, _ := util.NewPositionMap(content)
+
+	var result []Diagnostic
+	switch resource["resourceType"] {
+	case util.DataStructureResourceType:
+		result = s.dataStructureDiagnostics(fileName, content, positions)
+	case util.DataProductResourceType, util.SourceApplicationResourceType:
+		result = s.dataProductDiagnostics(fileName, content, positions)
+	default:
+		return []Diagnostic{}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Range.Start.Line < result[j].Range.Start.Line
+	})
+	return result
+}
+
+func (s *server) dataStructureDiagnostics(fileName string, content []byte, positions util.PositionMap) []Diagnostic {
+	result := []Diagnostic{}
+
+	var ds model.DataStructure
+	if err := decode(fileName, content, &ds); err != nil {
+		return append(result, Diagnostic{Range: lineRange(content, 0, 0), Severity: SeverityError, Source: diagnosticSource, Message: err.Error()})
+	}
+
+	// other files are needed to report data structures defined twice
+	dss := s.workspace.dataStructures()
+	dss[fileName] = ds
+	for _, err := range validation.ValidateLocalDs(dss) {
+		msg := err.Error()
+		if !strings.Contains(msg, fileName) {
+			continue
+		}
+		msg = strings.TrimPrefix(msg, fmt.Sprintf("validation failed for %s\n", fileName))
+		result = append(result, Diagnostic{Range: lineRange(content, 0, 0), Severity: SeverityError, Source: diagnosticSource, Message: msg})
+	}
+
+	findings, err := s.lintConfig.RunFile(fileName, content, ds)
+	if err != nil {
+		return append(result, Diagnostic{Range: lineRange(content, 0, 0), Severity: SeverityError, Source: diagnosticSource, Message: err.Error()})
+	}
+	for _, f := range findings {
+		severity := SeverityInformation
+		switch f.Severity {
+		case lint.SeverityError:
+			severity = SeverityError
+		case lint.SeverityWarning:
+			severity = SeverityWarning
+		}
+		result = append(result, Diagnostic{
+			Range:    pointerRange(content, positions, f.Path),
+			Severity: severity,
+			Code:     f.Rule,
+			Source:   diagnosticSource,
+			Message:  f.Message,
+		})
+	}
+
+	return result
+}
+
+func (s *server) dataProductDiagnostics(fileName string, content []byte, positions util.PositionMap) []Diagnostic {
+	result := []Diagnostic{}
+
+	resources := s.workspace.dpResources()
+	lookup, err := validation.NewDPLookup(nil, s.workspace.localSchemas(), resources, nil, false, 1)
+	if err != nil {
+		return append(result, Diagnostic{Range: lineRange(content, 0, 0), Severity: SeverityError, Source: diagnosticSource, Message: err.Error()})
+	}
+	lookup.ValidateGovernance(s.lintConfig)
+	lookup.ValidatePolicies(s.lintConfig.Policies, resources)
+
+	v := lookup.Validations[fileName]
+	add := func(severity int, pointer string, msgs ...string) {
+		r := lineRange(content, 0, 0)
+		if pointer != "" {
+			r = pointerRange(content, positions, pointer)
+		}
+		for _, m := range msgs {
+			result = append(result, Diagnostic{Range: r, Severity: severity, Source: diagnosticSource, Message: m})
+		}
+	}
+
+	add(SeverityError, "", v.Errors...)
+	add(SeverityWarning, "", v.Warnings...)
+	add(SeverityInformation, "", v.Info...)
+	for _, k := range sortedKeys(v.ErrorsWithPaths) {
+		add(SeverityError, k, v.ErrorsWithPaths[k]...)
+	}
+	for _, k := range sortedKeys(v.WarningsWithPaths) {
+		add(SeverityWarning, k, v.WarningsWithPaths[k]...)
+	}
+
+	return result
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// pointerRange spans from the position of pointer to the end of its line
+func pointerRange(content []byte, positions util.PositionMap, pointer string) Range {
+	p := positions.Lookup(pointer)
+	return lineRange(content, p.Line-1, p.Column-1)
+}
+
+func lineRange(content []byte, line int, character int) Range {
+	end := utf8.RuneCountInString(lineAt(content, line))
+	return Range{Start: Position{line, min(character, end)}, End: Position{line, end}}
+}
+
+func lineAt(content []byte, line int) string {
+	lines := strings.Split(string(content), "\n")
+	if line < 0 || line >= len(lines) {
+		return ""
+	}
+	return strings.TrimSuffix(lines[line], "\r")
+}
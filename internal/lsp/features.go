@@ -0,0 +1,294 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package lsp
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/util"
+)
+
+// reference matches `$ref` and `source` keys, in yaml or json, with the
+// value following them
+var reference = regexp.MustCompile(`^(\s*(?:-\s*)?["']?(\$ref|source)["']?\s*:\s*["']?)([^"'\s,}#]*)`)
+
+type referenceAt struct {
+	fileName string
+	key      string
+	value    string
+	// value range on the line
+	valueRange Range
+}
+
+// referenceAtPosition finds a `$ref` or `source` reference on the line of
+// position, completion passes the line up to the cursor only
+func (s *server) referenceAtPosition(uri string, position Position, upToCursor bool) (referenceAt, bool) {
+	fileName, ok := uriToPath(uri)
+	if !ok {
+		return referenceAt{}, false
+	}
+	content, ok := s.workspace.content(fileName)
+	if !ok {
+		return referenceAt{}, false
+	}
+
+	line := lineAt(content, position.Line)
+	if upToCursor {
+		line = line[:byteOffset(line, position.Character)]
+	}
+
+	m := reference.FindStringSubmatch(line)
+	if m == nil || (upToCursor && len(m[0]) != len(line)) {
+		return referenceAt{}, false
+	}
+
+	start := utf8.RuneCountInString(m[1])
+	end := start + utf8.RuneCountInString(m[3])
+	return referenceAt{
+		fileName:   fileName,
+		key:        m[2],
+		value:      m[3],
+		valueRange: Range{Position{position.Line, start}, Position{position.Line, end}},
+	}, true
+}
+
+func byteOffset(line string, character int) int {
+	offset := 0
+	for i := range line {
+		if offset == character {
+			return i
+		}
+		offset++
+	}
+	return len(line)
+}
+
+// definition resolves source application `$ref`s to their file and iglu
+// `source` uris to the local data structure file defining them
+func (s *server) definition(params TextDocumentPositionParams) *Location {
+	ref, ok := s.referenceAtPosition(params.TextDocument.Uri, params.Position, false)
+	if !ok || ref.value == "" {
+		return nil
+	}
+
+	var target, pointer string
+	switch ref.key {
+	case "$ref":
+		target = filepath.Join(filepath.Dir(ref.fileName), filepath.FromSlash(ref.value))
+	case "source":
+		target = s.workspace.igluIndex()[ref.value]
+		pointer = "/data/self"
+	}
+
+	content, ok := s.workspace.content(target)
+	if target == "" || !ok {
+		return nil
+	}
+
+	r := lineRange(content, 0, 0)
+	if pointer != "" {
+		if positions, err := util.NewPositionMap(content); err == nil {
+			r = pointerRange(content, positions, pointer)
+		}
+	}
+	return &Location{Uri: pathToUri(target), Range: r}
+}
+
+// completion offers the iglu uris of local data structures for `source`
+// and the source application files for `$ref`
+func (s *server) completion(params TextDocumentPositionParams) CompletionList {
+	result := CompletionList{Items: []CompletionItem{}}
+
+	ref, ok := s.referenceAtPosition(params.TextDocument.Uri, params.Position, true)
+	if !ok {
+		return result
+	}
+
+	switch ref.key {
+	case "source":
+		dss := s.workspace.dataStructures()
+		for uri, f := range s.workspace.igluIndex() {
+			ds := dss[f]
+			description, _ := ds.Data["description"].(string)
+			result.Items = append(result.Items, CompletionItem{
+				Label:         uri,
+				Kind:          completionKindReference,
+				Detail:        ds.Meta.SchemaType,
+				Documentation: description,
+				TextEdit:      &TextEdit{ref.valueRange, uri},
+			})
+		}
+	case "$ref":
+		for f, r := range s.workspace.dpResources() {
+			if r["resourceType"] != util.SourceApplicationResourceType {
+				continue
+			}
+			rel, err := filepath.Rel(filepath.Dir(ref.fileName), f)
+			if err != nil {
+				continue
+			}
+			rel = filepath.ToSlash(rel)
+			if !strings.HasPrefix(rel, "../") {
+				rel = "./" + rel
+			}
+			name, _ := nested(r, "data", "name").(string)
+			result.Items = append(result.Items, CompletionItem{
+				Label:    rel,
+				Kind:     completionKindFile,
+				Detail:   name,
+				TextEdit: &TextEdit{ref.valueRange, rel},
+			})
+		}
+	}
+
+	sort.Slice(result.Items, func(i, j int) bool {
+		return result.Items[i].Label < result.Items[j].Label
+	})
+	return result
+}
+
+// hover describes the data structure behind an iglu `source` uri, or the
+// source application behind a `$ref`
+func (s *server) hover(params TextDocumentPositionParams) *Hover {
+	ref, ok := s.referenceAtPosition(params.TextDocument.Uri, params.Position, false)
+	if !ok || ref.value == "" {
+		return nil
+	}
+
+	var value string
+	switch ref.key {
+	case "source":
+		f, ok := s.workspace.igluIndex()[ref.value]
+		if !ok {
+			return nil
+		}
+		value = dataStructureMarkdown(ref.value, s.workspace.dataStructures()[f])
+	case "$ref":
+		target := filepath.Join(filepath.Dir(ref.fileName), filepath.FromSlash(ref.value))
+		r, ok := s.workspace.dpResources()[target]
+		if !ok || r["resourceType"] != util.SourceApplicationResourceType {
+			return nil
+		}
+		value = sourceAppMarkdown(r)
+	}
+
+	return &Hover{Contents: MarkupContent{Kind: "markdown", Value: value}, Range: &ref.valueRange}
+}
+
+func dataStructureMarkdown(uri string, ds model.DataStructure) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s**", uri)
+	if ds.Meta.SchemaType != "" {
+		fmt.Fprintf(&b, " (%s)", ds.Meta.SchemaType)
+	}
+	b.WriteString("\n\n")
+
+	if description, ok := ds.Data["description"].(string); ok && description != "" {
+		b.WriteString(description + "\n\n")
+	}
+
+	if ds.Meta.IsDeprecated() {
+		b.WriteString("_Deprecated")
+		if ds.Meta.ReplacedBy != "" {
+			fmt.Fprintf(&b, ", replaced by %s", ds.Meta.ReplacedBy)
+		}
+		if ds.Meta.SunsetDate != "" {
+			fmt.Fprintf(&b, ", sunset on %s", ds.Meta.SunsetDate)
+		}
+		b.WriteString("_\n\n")
+	}
+
+	properties, _ := ds.Data["properties"].(map[string]any)
+	if len(properties) == 0 {
+		return b.String()
+	}
+
+	required := map[string]bool{}
+	if rs, ok := ds.Data["required"].([]any); ok {
+		for _, r := range rs {
+			if name, ok := r.(string); ok {
+				required[name] = true
+			}
+		}
+	}
+
+	var names []string
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b.WriteString("| Property | Type | Required | Description |\n| --- | --- | --- | --- |\n")
+	for _, name := range names {
+		p, _ := properties[name].(map[string]any)
+		description, _ := p["description"].(string)
+		requiredText := ""
+		if required[name] {
+			requiredText = "yes"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", name, typeText(p["type"]), requiredText, tableCell(description))
+	}
+
+	return b.String()
+}
+
+func sourceAppMarkdown(r map[string]any) string {
+	var b strings.Builder
+	name, _ := nested(r, "data", "name").(string)
+	fmt.Fprintf(&b, "**%s** (source application)\n\n", name)
+	if description, ok := nested(r, "data", "description").(string); ok && description != "" {
+		b.WriteString(description + "\n\n")
+	}
+	if appIds, ok := nested(r, "data", "appIds").([]any); ok && len(appIds) > 0 {
+		var ids []string
+		for _, id := range appIds {
+			ids = append(ids, fmt.Sprint(id))
+		}
+		fmt.Fprintf(&b, "App ids: %s\n", strings.Join(ids, ", "))
+	}
+	return b.String()
+}
+
+func typeText(t any) string {
+	switch v := t.(type) {
+	case string:
+		return v
+	case []any:
+		var types []string
+		for _, e := range v {
+			types = append(types, fmt.Sprint(e))
+		}
+		return strings.Join(types, ", ")
+	}
+	return ""
+}
+
+func tableCell(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", "\\|"), "\n", " ")
+}
+
+func nested(m map[string]any, keys ...string) any {
+	var current any = m
+	for _, k := range keys {
+		next, ok := current.(map[string]any)
+		if !ok {
+			return nil
+		}
+		current = next[k]
+	}
+	return current
+}
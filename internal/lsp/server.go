@@ -0,0 +1,289 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/snowplow/snowplow-cli/internal/lint"
+	"github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/util"
+)
+
+// Options locate the tracking plan, relative paths are resolved against
+// the workspace root the client initializes the server with
+type Options struct {
+	DataStructuresDir string
+	DataProductsDir   string
+	LintConfig        string
+}
+
+type server struct {
+	options    Options
+	conn       *conn
+	logger     *slog.Logger
+	root       string
+	workspace  *workspace
+	lintConfig lint.Config
+	shutdown   bool
+}
+
+type rpcError struct {
+	code int
+	msg  string
+}
+
+func (e *rpcError) Error() string {
+	return e.msg
+}
+
+// Serve speaks the language server protocol over in and out until the
+// client asks it to exit or closes the input
+func Serve(ctx context.Context, in io.Reader, out io.Writer, options Options) error {
+	root, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	s := &server{
+		options: options,
+		conn:    newConn(in, out),
+		logger:  logging.LoggerFromContext(ctx),
+	}
+	s.setRoot(root)
+
+	for {
+		req, err := s.conn.read()
+		if err != nil {
+			var pe *parseError
+			if errors.As(err, &pe) {
+				s.logger.Warn("lsp", "msg", pe.Error())
+				if err := s.conn.replyError(json.RawMessage("null"), codeParseError, pe.Error()); err != nil {
+					return err
+				}
+				continue
+			}
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("exit requested before shutdown")
+			}
+			return nil
+		}
+
+		result, err := s.handle(req)
+		if req.isNotification() {
+			if err != nil {
+				s.logger.Warn("lsp", "method", req.Method, "error", err.Error())
+			}
+			continue
+		}
+
+		if err != nil {
+			var re *rpcError
+			if !errors.As(err, &re) {
+				re = &rpcError{codeInternalError, err.Error()}
+			}
+			err = s.conn.replyError(req.Id, re.code, re.msg)
+		} else {
+			err = s.conn.reply(req.Id, result)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *server) handle(req request) (any, error) {
+	s.logger.Debug("lsp", "method", req.Method)
+
+	switch req.Method {
+	case "initialize":
+		var params InitializeParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		return s.initialize(params), nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		if fileName, ok := uriToPath(params.TextDocument.Uri); ok {
+			s.workspace.documents[fileName] = []byte(params.TextDocument.Text)
+		}
+		return nil, s.publishDiagnostics()
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		fileName, ok := uriToPath(params.TextDocument.Uri)
+		if !ok || len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		// the server only supports full document sync
+		s.workspace.documents[fileName] = []byte(params.ContentChanges[len(params.ContentChanges)-1].Text)
+		return nil, s.publishDiagnostics()
+	case "textDocument/didSave":
+		var params DidSaveTextDocumentParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		if fileName, ok := uriToPath(params.TextDocument.Uri); ok && params.Text != nil {
+			s.workspace.documents[fileName] = []byte(*params.Text)
+		}
+		s.workspace.reload()
+		s.loadLintConfig()
+		return nil, s.publishDiagnostics()
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		if fileName, ok := uriToPath(params.TextDocument.Uri); ok {
+			delete(s.workspace.documents, fileName)
+		}
+		if err := s.conn.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{params.TextDocument.Uri, []Diagnostic{}}); err != nil {
+			return nil, err
+		}
+		return nil, s.publishDiagnostics()
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		if l := s.definition(params); l != nil {
+			return l, nil
+		}
+		return nil, nil
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		return s.completion(params), nil
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		if h := s.hover(params); h != nil {
+			return h, nil
+		}
+		return nil, nil
+	}
+
+	return nil, &rpcError{codeMethodNotFound, fmt.Sprintf("method not supported: %s", req.Method)}
+}
+
+func unmarshalParams(req request, v any) error {
+	if err := json.Unmarshal(req.Params, v); err != nil {
+		return &rpcError{codeInvalidParams, fmt.Sprintf("invalid params for %s: %s", req.Method, err)}
+	}
+	return nil
+}
+
+func (s *server) initialize(params InitializeParams) InitializeResult {
+	switch {
+	case params.RootUri != nil:
+		if root, ok := uriToPath(*params.RootUri); ok {
+			s.setRoot(root)
+		}
+	case len(params.WorkspaceFolders) > 0:
+		if root, ok := uriToPath(params.WorkspaceFolders[0].Uri); ok {
+			s.setRoot(root)
+		}
+	case params.RootPath != nil:
+		s.setRoot(*params.RootPath)
+	}
+
+	return InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync: TextDocumentSyncOptions{
+				OpenClose: true,
+				Change:    textDocumentSyncFull,
+				Save:      SaveOptions{IncludeText: true},
+			},
+			DefinitionProvider: true,
+			HoverProvider:      true,
+			CompletionProvider: CompletionOptions{TriggerCharacters: []string{":", " ", "/", "."}},
+		},
+		ServerInfo: ServerInfo{Name: "snowplow-cli", Version: util.VersionInfo},
+	}
+}
+
+func (s *server) setRoot(root string) {
+	s.root = root
+	documents := map[string][]byte{}
+	if s.workspace != nil {
+		documents = s.workspace.documents
+	}
+	s.workspace = newWorkspace(s.resolve(s.options.DataStructuresDir), s.resolve(s.options.DataProductsDir))
+	s.workspace.documents = documents
+	s.loadLintConfig()
+}
+
+func (s *server) resolve(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(s.root, path)
+}
+
+func (s *server) loadLintConfig() {
+	if s.options.LintConfig == "" {
+		s.lintConfig = lint.Config{}
+		return
+	}
+	c, err := lint.LoadConfig(s.resolve(s.options.LintConfig))
+	if err != nil {
+		s.logger.Warn("lsp", "msg", "failed to load lint config", "error", err.Error())
+		c = lint.Config{}
+	}
+	s.lintConfig = c
+}
+
+// publishDiagnostics validates every open document, the validity of a
+// document may depend on others, eg: source applications referenced by
+// a data product
+func (s *server) publishDiagnostics() error {
+	var files []string
+	for f := range s.workspace.documents {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+
+	for _, f := range files {
+		if err := s.conn.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{pathToUri(f), s.diagnostics(f)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+const testDs = `apiVersion: v1
+resourceType: data-structure
+meta:
+  hidden: false
+  schemaType: entity
+  customData: {}
+data:
+  $schema: http://iglucentral.com/schemas/com.snowplowanalytics.self-desc/schema/jsonschema/1-0-0#
+  self:
+    vendor: com.acme
+    name: user
+    format: jsonschema
+    version: 1-0-0
+  description: the signed in user
+  type: object
+  required: [id]
+  properties:
+    id:
+      description: user id
+      type: string
+      maxLength: 36
+  additionalProperties: false
+`
+
+const testSa = `apiVersion: v1
+resourceType: source-application
+resourceName: e066a9a7-e6c4-4d72-9a93-1418746f5279
+data:
+  name: Web
+  description: the web app
+  appIds: [web]
+  entities:
+    tracked:
+      - source: iglu:com.acme/user/jsonschema/9-0-0
+    enriched: []
+`
+
+const testDp = `apiVersion: v1
+resourceType: data-product
+resourceName: d066a9a7-e6c4-4d72-9a93-1418746f5279
+data:
+  name: Checkout
+  sourceApplications:
+    - $ref: ./source-apps/web.yaml
+  eventSpecifications:
+    - resourceName: e066a9a7-e6c4-4d72-9a93-1418746f5278
+      name: checkout
+      event:
+        source: iglu:com.snowplowanalytics.snowplow/button_click/jsonschema/1-0-0
+      entities:
+        tracked:
+          - source: iglu:com.acme/user/jsonschema/1-0-0
+`
+
+type testClient struct {
+	t     *testing.T
+	in    io.Writer
+	out   *textproto.Reader
+	id    int
+	done  chan error
+	files map[string]string
+}
+
+func newTestClient(t *testing.T) *testClient {
+	dir := t.TempDir()
+	files := map[string]string{
+		"data-structures/com.acme/user.yaml": testDs,
+		"data-products/source-apps/web.yaml": testSa,
+		"data-products/checkout.yaml":        testDp,
+	}
+	paths := map[string]string{}
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths[name] = p
+	}
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := &testClient{t: t, in: inW, out: textproto.NewReader(bufio.NewReader(outR)), done: make(chan error, 1), files: paths}
+
+	go func() {
+		c.done <- Serve(context.Background(), inR, outW, Options{DataStructuresDir: "data-structures", DataProductsDir: "data-products"})
+		_ = outW.Close()
+	}()
+
+	rootUri := pathToUri(dir)
+	var result InitializeResult
+	c.request("initialize", InitializeParams{RootUri: &rootUri}, &result)
+	if !result.Capabilities.DefinitionProvider || result.Capabilities.TextDocumentSync.Change != textDocumentSyncFull {
+		t.Fatalf("unexpected capabilities %#v", result.Capabilities)
+	}
+	c.send("initialized", nil, struct{}{})
+
+	return c
+}
+
+func (c *testClient) send(method string, id any, params any) {
+	msg := map[string]any{"jsonrpc": "2.0", "method": method, "params": params}
+	if id != nil {
+		msg["id"] = id
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if _, err := fmt.Fprintf(c.in, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *testClient) read() map[string]json.RawMessage {
+	header, err := c.out.ReadMIMEHeader()
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	length, _ := strconv.Atoi(header.Get("Content-Length"))
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.out.R, body); err != nil {
+		c.t.Fatal(err)
+	}
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		c.t.Fatal(err)
+	}
+	return msg
+}
+
+func (c *testClient) request(method string, params any, result any) {
+	c.id++
+	c.send(method, c.id, params)
+	for {
+		msg := c.read()
+		if _, isResponse := msg["id"]; !isResponse {
+			continue
+		}
+		if e, ok := msg["error"]; ok {
+			c.t.Fatalf("%s failed %s", method, e)
+		}
+		if err := json.Unmarshal(msg["result"], result); err != nil {
+			c.t.Fatal(err)
+		}
+		return
+	}
+}
+
+func (c *testClient) open(name string) string {
+	uri := pathToUri(c.files[name])
+	content, err := os.ReadFile(c.files[name])
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	c.send("textDocument/didOpen", nil, DidOpenTextDocumentParams{TextDocumentItem{Uri: uri, LanguageId: "yaml", Version: 1, Text: string(content)}})
+	return uri
+}
+
+func (c *testClient) diagnostics(uri string) []Diagnostic {
+	for {
+		msg := c.read()
+		var method string
+		_ = json.Unmarshal(msg["method"], &method)
+		if method != "textDocument/publishDiagnostics" {
+			continue
+		}
+		var params PublishDiagnosticsParams
+		if err := json.Unmarshal(msg["params"], &params); err != nil {
+			c.t.Fatal(err)
+		}
+		if params.Uri == uri {
+			return params.Diagnostics
+		}
+	}
+}
+
+func (c *testClient) close() {
+	var result any
+	c.request("shutdown", nil, &result)
+	c.send("exit", nil, nil)
+	if err := <-c.done; err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func Test_Diagnostics(t *testing.T) {
+	c := newTestClient(t)
+
+	uri := c.open("data-products/source-apps/web.yaml")
+	diagnostics := c.diagnostics(uri)
+
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected a single diagnostic got %#v", diagnostics)
+	}
+	d := diagnostics[0]
+	if d.Severity != SeverityError || d.Range.Start != (Position{9, 8}) || !strings.Contains(d.Message, "available versions (1-0-0)") {
+		t.Fatalf("unexpected diagnostic %#v", d)
+	}
+
+	fixed := strings.Replace(testSa, "9-0-0", "1-0-0", 1)
+	c.send("textDocument/didChange", nil, DidChangeTextDocumentParams{TextDocumentIdentifier{uri}, []TextDocumentContentChangeEvent{{fixed}}})
+	if diagnostics := c.diagnostics(uri); len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics got %#v", diagnostics)
+	}
+
+	c.close()
+}
+
+func Test_DefinitionHoverCompletion(t *testing.T) {
+	c := newTestClient(t)
+
+	uri := c.open("data-products/checkout.yaml")
+	c.diagnostics(uri)
+
+	var location Location
+	c.request("textDocument/definition", TextDocumentPositionParams{TextDocumentIdentifier{uri}, Position{14, 25}}, &location)
+	if location.Uri != pathToUri(c.files["data-structures/com.acme/user.yaml"]) || location.Range.Start != (Position{8, 2}) {
+		t.Fatalf("unexpected iglu definition %#v", location)
+	}
+
+	c.request("textDocument/definition", TextDocumentPositionParams{TextDocumentIdentifier{uri}, Position{6, 14}}, &location)
+	if location.Uri != pathToUri(c.files["data-products/source-apps/web.yaml"]) {
+		t.Fatalf("unexpected $ref definition %#v", location)
+	}
+
+	var hover Hover
+	c.request("textDocument/hover", TextDocumentPositionParams{TextDocumentIdentifier{uri}, Position{14, 25}}, &hover)
+	if !strings.Contains(hover.Contents.Value, "the signed in user") || !strings.Contains(hover.Contents.Value, "| id | string | yes | user id |") {
+		t.Fatalf("unexpected hover %s", hover.Contents.Value)
+	}
+
+	// complete a source being typed
+	edited := strings.Replace(testDp, "source: iglu:com.acme/user/jsonschema/1-0-0", "source: iglu:com.ac", 1)
+	c.send("textDocument/didChange", nil, DidChangeTextDocumentParams{TextDocumentIdentifier{uri}, []TextDocumentContentChangeEvent{{edited}}})
+	c.diagnostics(uri)
+
+	var list CompletionList
+	c.request("textDocument/completion", TextDocumentPositionParams{TextDocumentIdentifier{uri}, Position{14, 29}}, &list)
+	if len(list.Items) != 1 || list.Items[0].Label != "iglu:com.acme/user/jsonschema/1-0-0" {
+		t.Fatalf("unexpected completion %#v", list)
+	}
+	if edit := list.Items[0].TextEdit; edit == nil || edit.Range.Start != (Position{14, 20}) || edit.Range.End != (Position{14, 29}) {
+		t.Fatalf("unexpected completion edit %#v", list.Items[0].TextEdit)
+	}
+
+	c.request("textDocument/completion", TextDocumentPositionParams{TextDocumentIdentifier{uri}, Position{6, 12}}, &list)
+	if len(list.Items) != 1 || list.Items[0].Label != "./source-apps/web.yaml" || list.Items[0].Detail != "Web" {
+		t.Fatalf("unexpected $ref completion %#v", list)
+	}
+
+	c.close()
+}
+
+func Test_localSchemasDeprecatedMeta(t *testing.T) {
+	deprecated := true
+	schemas := localSchemas{
+		"iglu:com.acme/user/jsonschema/1-0-0": {Meta: model.DataStructureMeta{}},
+		"iglu:com.acme/user/jsonschema/2-0-0": {Meta: model.DataStructureMeta{Deprecated: &deprecated, ReplacedBy: "iglu:com.acme/user/jsonschema/2-0-0"}},
+	}
+
+	for i := 0; i < 10; i++ {
+		if meta, deprecated := schemas.DeprecatedMeta("iglu:com.acme/user/jsonschema/1-0-0"); !deprecated || !meta.IsDeprecated() {
+			t.Fatalf("expected the meta of the latest version to deprecate 1-0-0, got %+v", meta)
+		}
+	}
+	if _, deprecated := schemas.DeprecatedMeta("iglu:com.acme/user/jsonschema/2-0-1"); deprecated {
+		t.Fatal("versions after the replacement should not be deprecated")
+	}
+}
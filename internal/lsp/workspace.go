@@ -0,0 +1,235 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package lsp
+
+import (
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"gopkg.in/yaml.v3"
+	kjson "k8s.io/apimachinery/pkg/util/json"
+)
+
+var resourceExts = []string{".yaml", ".yml", ".json"}
+
+// workspace holds the files of the tracking plan, open documents take
+// precedence over the content on disk
+type workspace struct {
+	dataStructuresDir string
+	dataProductsDir   string
+
+	// disk content of the tracking plan files, keyed by absolute path
+	disk map[string][]byte
+	// content of the documents open in the editor
+	documents map[string][]byte
+}
+
+func newWorkspace(dataStructuresDir string, dataProductsDir string) *workspace {
+	w := &workspace{
+		dataStructuresDir: dataStructuresDir,
+		dataProductsDir:   dataProductsDir,
+		documents:         map[string][]byte{},
+	}
+	w.reload()
+	return w
+}
+
+// reload reads the tracking plan files from disk, unreadable files and
+// missing directories are skipped
+func (w *workspace) reload() {
+	w.disk = map[string][]byte{}
+	for _, dir := range []string{w.dataStructuresDir, w.dataProductsDir} {
+		_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !slices.Contains(resourceExts, filepath.Ext(path)) {
+				return nil
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil
+			}
+			if abs, err := filepath.Abs(path); err == nil {
+				w.disk[abs] = content
+			}
+			return nil
+		})
+	}
+}
+
+func (w *workspace) content(fileName string) ([]byte, bool) {
+	if c, ok := w.documents[fileName]; ok {
+		return c, true
+	}
+	c, ok := w.disk[fileName]
+	return c, ok
+}
+
+func (w *workspace) files() []string {
+	var files []string
+	for f := range w.disk {
+		files = append(files, f)
+	}
+	for f := range w.documents {
+		if _, ok := w.disk[f]; !ok {
+			files = append(files, f)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// resources decodes every file of the workspace, files that fail to
+// decode are left out
+func (w *workspace) resources() map[string]map[string]any {
+	result := map[string]map[string]any{}
+	for _, f := range w.files() {
+		content, _ := w.content(f)
+		var r map[string]any
+		if err := decode(f, content, &r); err == nil && r != nil {
+			result[f] = r
+		}
+	}
+	return result
+}
+
+func (w *workspace) dataStructures() map[string]model.DataStructure {
+	result := map[string]model.DataStructure{}
+	for f, r := range w.resources() {
+		if r["resourceType"] != util.DataStructureResourceType {
+			continue
+		}
+		content, _ := w.content(f)
+		var ds model.DataStructure
+		if err := decode(f, content, &ds); err == nil {
+			result[f] = ds
+		}
+	}
+	return result
+}
+
+// dpResources are the data products and source applications
+func (w *workspace) dpResources() map[string]map[string]any {
+	result := map[string]map[string]any{}
+	for f, r := range w.resources() {
+		switch r["resourceType"] {
+		case util.DataProductResourceType, util.SourceApplicationResourceType:
+			result[f] = r
+		}
+	}
+	return result
+}
+
+// igluIndex maps the iglu uri of every data structure to its file
+func (w *workspace) igluIndex() map[string]string {
+	result := map[string]string{}
+	for f, ds := range w.dataStructures() {
+		data, err := ds.ParseData()
+		if err != nil || data.Self.Vendor == "" {
+			continue
+		}
+		result[data.Self.IgluUri()] = f
+	}
+	return result
+}
+
+func (w *workspace) localSchemas() localSchemas {
+	result := localSchemas{}
+	for _, ds := range w.dataStructures() {
+		data, err := ds.ParseData()
+		if err != nil || data.Self.Vendor == "" {
+			continue
+		}
+		result[data.Self.IgluUri()] = ds
+	}
+	return result
+}
+
+func decode(fileName string, content []byte, v any) error {
+	if filepath.Ext(fileName) == ".json" {
+		return kjson.Unmarshal(content, v)
+	}
+	return yaml.Unmarshal(content, v)
+}
+
+func uriToPath(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", false
+	}
+	return filepath.FromSlash(u.Path), true
+}
+
+func pathToUri(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// localSchemas resolves deployments against the data structures of the
+// workspace. Data structures the workspace does not define, eg: from iglu
+// central, are assumed to be deployed as the console is not queried
+type localSchemas map[string]model.DataStructure
+
+func (l localSchemas) IsDSDeployed(uri string) (bool, []string, error) {
+	self, err := model.ParseIgluUri(uri)
+	if err != nil {
+		return false, nil, err
+	}
+
+	known := false
+	var versions []string
+	for u := range l {
+		s, _ := model.ParseIgluUri(u)
+		if s.Vendor != self.Vendor || s.Name != self.Name || s.Format != self.Format {
+			continue
+		}
+		if s.Version == self.Version {
+			return true, nil, nil
+		}
+		known = true
+		versions = append(versions, s.Version)
+	}
+	sort.Strings(versions)
+
+	return !known, versions, nil
+}
+
+func (l localSchemas) DeprecatedMeta(uri string) (model.DataStructureMeta, bool) {
+	self, err := model.ParseIgluUri(uri)
+	if err != nil {
+		return model.DataStructureMeta{}, false
+	}
+
+	// meta is not versioned, the latest local version holds it
+	var latest *model.SemVersion
+	var meta model.DataStructureMeta
+	for u, ds := range l {
+		s, _ := model.ParseIgluUri(u)
+		if s.Vendor != self.Vendor || s.Name != self.Name || s.Format != self.Format {
+			continue
+		}
+		v, err := model.ParseSemVer(s.Version)
+		if err != nil {
+			continue
+		}
+		if latest == nil || model.SemVerCmp(*v, *latest) > 0 {
+			latest, meta = v, ds.Meta
+		}
+	}
+	if latest == nil {
+		return model.DataStructureMeta{}, false
+	}
+
+	return meta, meta.Deprecates(self)
+}
@@ -15,6 +15,8 @@ import (
 	"crypto"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
 
 	"github.com/go-viper/mapstructure/v2"
 )
@@ -25,6 +27,9 @@ type DataStructureMeta struct {
 	Hidden     bool              `yaml:"hidden" json:"hidden"`
 	SchemaType string            `yaml:"schemaType" json:"schemaType" validate:"required,oneof=event entity"`
 	CustomData map[string]string `yaml:"customData" json:"customData" validate:"required"`
+	Deprecated *bool             `yaml:"deprecated,omitempty" json:"deprecated,omitempty"`
+	ReplacedBy string            `yaml:"replacedBy,omitempty" json:"replacedBy,omitempty" validate:"omitempty,startswith=iglu:"`
+	SunsetDate string            `yaml:"sunsetDate,omitempty" json:"sunsetDate,omitempty" validate:"omitempty,datetime=2006-01-02"`
 }
 
 type DataStructure struct {
@@ -77,3 +82,53 @@ type DSChangeContext struct {
 	LocalContentHash  string
 	RemoteContentHash string
 }
+
+func (s DataStructureSelf) IgluUri() string {
+	return fmt.Sprintf("iglu:%s/%s/%s/%s", s.Vendor, s.Name, s.Format, s.Version)
+}
+
+func ParseIgluUri(uri string) (DataStructureSelf, error) {
+	parts := strings.Split(strings.TrimPrefix(uri, "iglu:"), "/")
+	if !strings.HasPrefix(uri, "iglu:") || len(parts) != 4 {
+		return DataStructureSelf{}, fmt.Errorf("invalid iglu uri got: %s", uri)
+	}
+	return DataStructureSelf{Vendor: parts[0], Name: parts[1], Format: parts[2], Version: parts[3]}, nil
+}
+
+// IsDeprecated reports whether deprecated is set to true, a nil Deprecated
+// means the field is not set
+func (m DataStructureMeta) IsDeprecated() bool {
+	return m.Deprecated != nil && *m.Deprecated
+}
+
+// ChangedFrom reports whether meta as written locally differs from the
+// remote meta. Deprecation left unset locally follows the remote
+func (m DataStructureMeta) ChangedFrom(remote DataStructureMeta) bool {
+	if m.Deprecated != nil && m.IsDeprecated() != remote.IsDeprecated() {
+		return true
+	}
+	m.Deprecated, remote.Deprecated = nil, nil
+	return !reflect.DeepEqual(m, remote)
+}
+
+// Deprecates reports whether the meta of a data structure deprecates the
+// version self. A data structure superseded by one of its own versions only
+// deprecates the versions before the replacement
+func (m DataStructureMeta) Deprecates(self DataStructureSelf) bool {
+	if !m.IsDeprecated() {
+		return false
+	}
+	replacement, err := ParseIgluUri(m.ReplacedBy)
+	if err != nil || replacement.Vendor != self.Vendor || replacement.Name != self.Name || replacement.Format != self.Format {
+		return true
+	}
+	v, err := ParseSemVer(self.Version)
+	if err != nil {
+		return true
+	}
+	r, err := ParseSemVer(replacement.Version)
+	if err != nil {
+		return true
+	}
+	return SemVerCmp(*v, *r) < 0
+}
@@ -270,3 +270,18 @@ func TestDefaultApiVersion(t *testing.T) {
 	}
 
 }
+
+func TestDataStructureMetaChangedFrom(t *testing.T) {
+	deprecated, notDeprecated := true, false
+	remote := DataStructureMeta{SchemaType: "event", CustomData: map[string]string{}, Deprecated: &deprecated}
+
+	if (DataStructureMeta{SchemaType: "event", CustomData: map[string]string{}}).ChangedFrom(remote) {
+		t.Fatal("expected deprecation left unset to follow the remote")
+	}
+	if !(DataStructureMeta{SchemaType: "event", CustomData: map[string]string{}, Deprecated: &notDeprecated}).ChangedFrom(remote) {
+		t.Fatal("expected deprecated: false to change a deprecated data structure")
+	}
+	if !(DataStructureMeta{SchemaType: "entity", CustomData: map[string]string{}}).ChangedFrom(remote) {
+		t.Fatal("expected a schema type change")
+	}
+}
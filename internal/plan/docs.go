@@ -0,0 +1,545 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package plan
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/util"
+)
+
+//go:embed templates
+var templates embed.FS
+
+type DocsFormat string
+
+const (
+	DocsHtml     DocsFormat = "html"
+	DocsMarkdown DocsFormat = "markdown"
+)
+
+type docLink struct {
+	Title string
+	Href  string
+}
+
+type docSchema struct {
+	Uri         string
+	Href        string
+	Kind        string
+	Cardinality string
+}
+
+type docTrigger struct {
+	Description string
+	AppIds      []string
+	Url         string
+	Image       string
+}
+
+type docSpec struct {
+	Name        string
+	Anchor      string
+	Description string
+	Schemas     []docSchema
+	Triggers    []docTrigger
+	SourceApps  []docLink
+}
+
+type docProperty struct {
+	Name        string
+	Type        string
+	Required    bool
+	Description string
+}
+
+type docPage struct {
+	Root        string
+	Title       string
+	Description string
+	File        string
+	Fields      [][2]string
+	SourceApps  []docLink
+	Specs       []docSpec
+	SpecLinks   []docLink
+	Schemas     []docSchema
+	Properties  []docProperty
+	AppIds      []string
+
+	DataProducts   []docLink
+	DataStructures []docLink
+}
+
+type searchEntry struct {
+	Title string `json:"title"`
+	Kind  string `json:"kind"`
+	Url   string `json:"url"`
+	Text  string `json:"text"`
+}
+
+type executor interface {
+	ExecuteTemplate(w io.Writer, name string, data any) error
+}
+
+type docsSite struct {
+	plan   *TrackingPlan
+	format DocsFormat
+	outDir string
+	ext    string
+	tpl    executor
+
+	dpPages map[string]string
+	saPages map[string]string
+	dsPages map[string]string
+	images  map[string]string
+	search  []searchEntry
+}
+
+// WriteDocs renders the tracking plan as a static site with one page per
+// data product, source application and data structure
+func WriteDocs(p *TrackingPlan, outDir string, format DocsFormat) error {
+	tpl, err := parseTemplates(format)
+	if err != nil {
+		return err
+	}
+
+	ext := ".html"
+	if format == DocsMarkdown {
+		ext = ".md"
+	}
+
+	site := &docsSite{
+		plan:    p,
+		format:  format,
+		outDir:  outDir,
+		ext:     ext,
+		tpl:     tpl,
+		dpPages: map[string]string{},
+		saPages: map[string]string{},
+		dsPages: map[string]string{},
+		images:  map[string]string{},
+	}
+
+	for _, dir := range []string{"data-products", "source-apps", "data-structures", util.ImagesFolder} {
+		if err := os.MkdirAll(filepath.Join(outDir, dir), os.ModePerm); err != nil {
+			return err
+		}
+	}
+
+	site.assignPaths()
+
+	if err := site.copyImages(); err != nil {
+		return err
+	}
+
+	for _, dp := range p.DataProducts {
+		if err := site.writePage("data-product", site.dpPages[dp.ResourceName], site.dataProductPage(dp)); err != nil {
+			return err
+		}
+	}
+	for _, sa := range p.SourceApps {
+		if err := site.writePage("source-app", site.saPages[sa.ResourceName], site.sourceAppPage(sa)); err != nil {
+			return err
+		}
+	}
+	for _, ds := range p.DataStructures {
+		if err := site.writePage("data-structure", site.dsPages[ds.Self.IgluUri()], site.dataStructurePage(ds)); err != nil {
+			return err
+		}
+	}
+	if err := site.writePage("index", "index"+ext, site.indexPage()); err != nil {
+		return err
+	}
+
+	return site.writeSearchIndex()
+}
+
+func parseTemplates(format DocsFormat) (executor, error) {
+	if format == DocsMarkdown {
+		return texttemplate.ParseFS(templates, "templates/markdown/*.tmpl")
+	}
+	return htmltemplate.ParseFS(templates, "templates/html/*.tmpl")
+}
+
+func uniqueSlug(used map[string]bool, name string) string {
+	slug := util.ResourceNameToFileName(name)
+	candidate := slug
+	for i := 2; used[candidate]; i++ {
+		candidate = fmt.Sprintf("%s-%d", slug, i)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+func (s *docsSite) assignPaths() {
+	used := map[string]bool{}
+	for _, dp := range s.plan.DataProducts {
+		s.dpPages[dp.ResourceName] = "data-products/" + uniqueSlug(used, dp.Data.Name) + s.ext
+	}
+	used = map[string]bool{}
+	for _, sa := range s.plan.SourceApps {
+		s.saPages[sa.ResourceName] = "source-apps/" + uniqueSlug(used, sa.Data.Name) + s.ext
+	}
+	used = map[string]bool{}
+	for _, ds := range s.plan.DataStructures {
+		name := fmt.Sprintf("%s-%s-%s", ds.Self.Vendor, ds.Self.Name, ds.Self.Version)
+		s.dsPages[ds.Self.IgluUri()] = "data-structures/" + uniqueSlug(used, name) + s.ext
+	}
+}
+
+func (s *docsSite) copyImages() error {
+	used := map[string]bool{}
+	for _, dp := range s.plan.DataProducts {
+		for _, es := range dp.Data.EventSpecifications {
+			for _, t := range es.Triggers {
+				src := s.plan.TriggerImagePath(es, t)
+				if src == "" {
+					continue
+				}
+				if _, ok := s.images[src]; ok {
+					continue
+				}
+				if _, err := os.Stat(src); err != nil {
+					slog.Warn("docs", "msg", "trigger image not found, skipping", "file", src)
+					continue
+				}
+				ext := filepath.Ext(src)
+				name := uniqueSlug(used, strings.TrimSuffix(filepath.Base(src), ext)) + ext
+				if err := copyFile(src, filepath.Join(s.outDir, util.ImagesFolder, name)); err != nil {
+					return err
+				}
+				s.images[src] = util.ImagesFolder + "/" + name
+			}
+		}
+	}
+	return nil
+}
+
+func copyFile(src string, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = out.Close() }()
+
+	_, err = io.Copy(out, in)
+	return err
+}
+
+func specAnchor(es model.EventSpec) string {
+	return "es-" + es.ResourceName
+}
+
+func (s *docsSite) schemaLink(root string, ref model.SchemaRef, kind string) docSchema {
+	cardinality := ""
+	if ref.MinCardinality != nil || ref.MaxCardinality != nil {
+		lower, upper := "0", "*"
+		if ref.MinCardinality != nil {
+			lower = strconv.Itoa(*ref.MinCardinality)
+		}
+		if ref.MaxCardinality != nil {
+			upper = strconv.Itoa(*ref.MaxCardinality)
+		}
+		cardinality = fmt.Sprintf("%s..%s", lower, upper)
+	}
+	href := ""
+	if page, ok := s.dsPages[ref.Source]; ok {
+		href = root + page
+	}
+	return docSchema{Uri: ref.Source, Href: href, Kind: kind, Cardinality: cardinality}
+}
+
+func (s *docsSite) entityLinks(root string, entities model.EntitiesDef) []docSchema {
+	var schemas []docSchema
+	for _, e := range entities.Tracked {
+		schemas = append(schemas, s.schemaLink(root, e, "tracked entity"))
+	}
+	for _, e := range entities.Enriched {
+		schemas = append(schemas, s.schemaLink(root, e, "enriched entity"))
+	}
+	return schemas
+}
+
+func (s *docsSite) specLinks(root string, refs []SpecRef) []docLink {
+	var links []docLink
+	for _, r := range refs {
+		links = append(links, docLink{
+			Title: fmt.Sprintf("%s / %s", r.DataProduct.Data.Name, r.Spec.Name),
+			Href:  fmt.Sprintf("%s%s#%s", root, s.dpPages[r.DataProduct.ResourceName], specAnchor(r.Spec)),
+		})
+	}
+	return links
+}
+
+func (s *docsSite) sourceAppLinks(root string, ids []string) []docLink {
+	var links []docLink
+	for _, id := range ids {
+		if sa, ok := s.plan.SourceApp(id); ok {
+			links = append(links, docLink{Title: sa.Data.Name, Href: root + s.saPages[id]})
+		}
+	}
+	return links
+}
+
+func relFile(f string) string {
+	if f == "" {
+		return ""
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(cwd, f); err == nil {
+			return rel
+		}
+	}
+	return f
+}
+
+func (s *docsSite) dataProductPage(dp model.DataProduct) docPage {
+	root := "../"
+
+	var saIds []string
+	for _, sa := range dp.Data.SourceApplications {
+		saIds = append(saIds, sa["id"])
+	}
+
+	var specs []docSpec
+	for _, es := range dp.Data.EventSpecifications {
+		var schemas []docSchema
+		if es.Event.Source != "" {
+			schemas = append(schemas, s.schemaLink(root, es.Event, "event"))
+		}
+		schemas = append(schemas, s.entityLinks(root, es.Entities)...)
+
+		var triggers []docTrigger
+		for _, t := range es.Triggers {
+			image := ""
+			if img, ok := s.images[s.plan.TriggerImagePath(es, t)]; ok {
+				image = root + img
+			}
+			triggers = append(triggers, docTrigger{Description: t.Description, AppIds: t.AppIds, Url: t.Url, Image: image})
+		}
+
+		specs = append(specs, docSpec{
+			Name:        es.Name,
+			Anchor:      specAnchor(es),
+			Description: es.Description,
+			Schemas:     schemas,
+			Triggers:    triggers,
+			SourceApps:  s.sourceAppLinks(root, SourceAppIds(dp, es)),
+		})
+
+		s.search = append(s.search, searchEntry{
+			Title: es.Name,
+			Kind:  "event specification",
+			Url:   fmt.Sprintf("%s#%s", s.dpPages[dp.ResourceName], specAnchor(es)),
+			Text:  strings.Join(append([]string{dp.Data.Name, es.Description}, SchemaUris(es)...), " "),
+		})
+	}
+
+	s.search = append(s.search, searchEntry{
+		Title: dp.Data.Name,
+		Kind:  "data product",
+		Url:   s.dpPages[dp.ResourceName],
+		Text:  strings.Join([]string{dp.Data.Description, dp.Data.Domain, dp.Data.Owner}, " "),
+	})
+
+	return docPage{
+		Root:        root,
+		Title:       dp.Data.Name,
+		Description: dp.Data.Description,
+		File:        relFile(s.plan.IdToFileName[dp.ResourceName]),
+		Fields:      nonEmptyFields([2]string{"Domain", dp.Data.Domain}, [2]string{"Owner", dp.Data.Owner}),
+		SourceApps:  s.sourceAppLinks(root, saIds),
+		Specs:       specs,
+	}
+}
+
+func (s *docsSite) sourceAppPage(sa model.SourceApp) docPage {
+	root := "../"
+
+	var schemas []docSchema
+	if sa.Data.Entities != nil {
+		schemas = s.entityLinks(root, *sa.Data.Entities)
+	}
+
+	s.search = append(s.search, searchEntry{
+		Title: sa.Data.Name,
+		Kind:  "source application",
+		Url:   s.saPages[sa.ResourceName],
+		Text:  strings.Join(append([]string{sa.Data.Description, sa.Data.Owner}, sa.Data.AppIds...), " "),
+	})
+
+	return docPage{
+		Root:        root,
+		Title:       sa.Data.Name,
+		Description: sa.Data.Description,
+		File:        relFile(s.plan.IdToFileName[sa.ResourceName]),
+		Fields:      nonEmptyFields([2]string{"Owner", sa.Data.Owner}),
+		AppIds:      sa.Data.AppIds,
+		Schemas:     schemas,
+		SpecLinks:   s.specLinks(root, s.plan.SpecsForSourceApp(sa.ResourceName)),
+	}
+}
+
+func (s *docsSite) dataStructurePage(ds DataStructure) docPage {
+	root := "../"
+	uri := ds.Self.IgluUri()
+	description, _ := ds.DS.Data["description"].(string)
+
+	var saIds []string
+	for _, sa := range s.plan.SourceAppsUsingSchema(uri) {
+		saIds = append(saIds, sa.ResourceName)
+	}
+
+	s.search = append(s.search, searchEntry{
+		Title: uri,
+		Kind:  "data structure",
+		Url:   s.dsPages[uri],
+		Text:  description,
+	})
+
+	return docPage{
+		Root:        root,
+		Title:       fmt.Sprintf("%s/%s", ds.Self.Vendor, ds.Self.Name),
+		Description: description,
+		File:        relFile(ds.FileName),
+		Fields:      nonEmptyFields([2]string{"Iglu URI", uri}, [2]string{"Type", ds.DS.Meta.SchemaType}),
+		Properties:  schemaProperties(ds.DS.Data),
+		SpecLinks:   s.specLinks(root, s.plan.SpecsUsingSchema(uri)),
+		SourceApps:  s.sourceAppLinks(root, saIds),
+	}
+}
+
+func (s *docsSite) indexPage() docPage {
+	page := docPage{Title: "Tracking plan"}
+	for _, dp := range s.plan.DataProducts {
+		page.DataProducts = append(page.DataProducts, docLink{Title: dp.Data.Name, Href: s.dpPages[dp.ResourceName]})
+	}
+	for _, sa := range s.plan.SourceApps {
+		page.SourceApps = append(page.SourceApps, docLink{Title: sa.Data.Name, Href: s.saPages[sa.ResourceName]})
+	}
+	for _, ds := range s.plan.DataStructures {
+		page.DataStructures = append(page.DataStructures, docLink{Title: ds.Self.IgluUri(), Href: s.dsPages[ds.Self.IgluUri()]})
+	}
+	return page
+}
+
+func nonEmptyFields(fields ...[2]string) [][2]string {
+	var res [][2]string
+	for _, f := range fields {
+		if f[1] != "" {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+func schemaProperties(schema map[string]any) []docProperty {
+	props, _ := schema["properties"].(map[string]any)
+	required := map[string]bool{}
+	if req, ok := schema["required"].([]any); ok {
+		for _, r := range req {
+			if rs, ok := r.(string); ok {
+				required[rs] = true
+			}
+		}
+	}
+
+	var res []docProperty
+	for name, p := range props {
+		prop, _ := p.(map[string]any)
+		description, _ := prop["description"].(string)
+		res = append(res, docProperty{Name: name, Type: propertyType(prop), Required: required[name], Description: description})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+func propertyType(prop map[string]any) string {
+	if enum, ok := prop["enum"].([]any); ok {
+		b, _ := json.Marshal(enum)
+		return "enum " + string(b)
+	}
+	switch t := prop["type"].(type) {
+	case string:
+		return t
+	case []any:
+		var types []string
+		for _, v := range t {
+			types = append(types, fmt.Sprint(v))
+		}
+		return strings.Join(types, " | ")
+	}
+	return ""
+}
+
+func (s *docsSite) writePage(name string, path string, page docPage) error {
+	var buf bytes.Buffer
+	if err := s.tpl.ExecuteTemplate(&buf, name, page); err != nil {
+		return err
+	}
+
+	dest := filepath.Join(s.outDir, filepath.FromSlash(path))
+	if err := os.WriteFile(dest, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	slog.Debug("wrote", "file", dest)
+	return nil
+}
+
+func (s *docsSite) writeSearchIndex() error {
+	index, err := json.MarshalIndent(s.search, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(s.outDir, "search-index.json"), index, 0644); err != nil {
+		return err
+	}
+	if s.format == DocsMarkdown {
+		return nil
+	}
+
+	// loaded with a script tag so search also works when opened from disk
+	script := append([]byte("window.SEARCH_INDEX = "), index...)
+	script = append(script, []byte(";\n")...)
+	if err := os.WriteFile(filepath.Join(s.outDir, "search-index.js"), script, 0644); err != nil {
+		return err
+	}
+
+	for _, asset := range []string{"style.css", "search.js"} {
+		content, err := templates.ReadFile("templates/html/" + asset)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(s.outDir, asset), content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
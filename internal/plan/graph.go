@@ -0,0 +1,282 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+type NodeKind string
+
+const (
+	DataProductNode   NodeKind = "data-product"
+	EventSpecNode     NodeKind = "event-specification"
+	SourceAppNode     NodeKind = "source-application"
+	DataStructureNode NodeKind = "data-structure"
+)
+
+type Node struct {
+	Id    string   `json:"id"`
+	Kind  NodeKind `json:"kind"`
+	Label string   `json:"label"`
+	// Local is false for data structures referenced but not found locally
+	Local bool `json:"local"`
+}
+
+type Edge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Label string `json:"label"`
+}
+
+// Graph holds every reference in the tracking plan. Data structure nodes
+// are identified by their iglu uri
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+func dataProductId(dp model.DataProduct) string { return "dp:" + dp.ResourceName }
+func eventSpecId(es model.EventSpec) string     { return "es:" + es.ResourceName }
+func sourceAppId(id string) string              { return "sa:" + id }
+
+func (g *Graph) hasNode(id string) bool {
+	return slices.ContainsFunc(g.Nodes, func(n Node) bool { return n.Id == id })
+}
+
+func (g *Graph) addSchemaEdge(p *TrackingPlan, from string, uri string, label string) {
+	if !g.hasNode(uri) {
+		_, local := p.DataStructure(uri)
+		g.Nodes = append(g.Nodes, Node{Id: uri, Kind: DataStructureNode, Label: uri, Local: local})
+	}
+	g.Edges = append(g.Edges, Edge{From: from, To: uri, Label: label})
+}
+
+func (g *Graph) addEntityEdges(p *TrackingPlan, from string, entities model.EntitiesDef) {
+	for _, e := range entities.Tracked {
+		g.addSchemaEdge(p, from, e.Source, "tracked entity")
+	}
+	for _, e := range entities.Enriched {
+		g.addSchemaEdge(p, from, e.Source, "enriched entity")
+	}
+}
+
+func BuildGraph(p *TrackingPlan) *Graph {
+	g := &Graph{}
+
+	for _, ds := range p.DataStructures {
+		uri := ds.Self.IgluUri()
+		g.Nodes = append(g.Nodes, Node{Id: uri, Kind: DataStructureNode, Label: uri, Local: true})
+	}
+
+	for _, sa := range p.SourceApps {
+		id := sourceAppId(sa.ResourceName)
+		g.Nodes = append(g.Nodes, Node{Id: id, Kind: SourceAppNode, Label: sa.Data.Name, Local: true})
+		if sa.Data.Entities != nil {
+			g.addEntityEdges(p, id, *sa.Data.Entities)
+		}
+	}
+
+	for _, dp := range p.DataProducts {
+		dpId := dataProductId(dp)
+		g.Nodes = append(g.Nodes, Node{Id: dpId, Kind: DataProductNode, Label: dp.Data.Name, Local: true})
+
+		for _, sa := range dp.Data.SourceApplications {
+			g.Edges = append(g.Edges, Edge{From: dpId, To: sourceAppId(sa["id"]), Label: "source application"})
+		}
+
+		for _, es := range dp.Data.EventSpecifications {
+			esId := eventSpecId(es)
+			g.Nodes = append(g.Nodes, Node{Id: esId, Kind: EventSpecNode, Label: es.Name, Local: true})
+			g.Edges = append(g.Edges, Edge{From: dpId, To: esId, Label: "event specification"})
+
+			for _, sa := range es.ExcludedSourceApplications {
+				g.Edges = append(g.Edges, Edge{From: esId, To: sourceAppId(sa["id"]), Label: "excludes"})
+			}
+			if es.Event.Source != "" {
+				g.addSchemaEdge(p, esId, es.Event.Source, "event")
+			}
+			g.addEntityEdges(p, esId, es.Entities)
+		}
+	}
+
+	return g
+}
+
+// Subgraph keeps only the given nodes and the edges between them
+func (g *Graph) Subgraph(ids []string) *Graph {
+	sub := &Graph{}
+	for _, n := range g.Nodes {
+		if slices.Contains(ids, n.Id) {
+			sub.Nodes = append(sub.Nodes, n)
+		}
+	}
+	for _, e := range g.Edges {
+		if slices.Contains(ids, e.From) && slices.Contains(ids, e.To) {
+			sub.Edges = append(sub.Edges, e)
+		}
+	}
+	return sub
+}
+
+func (g *Graph) Json() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
+
+var dotShapes = map[NodeKind]string{
+	DataProductNode:   "folder",
+	EventSpecNode:     "box",
+	SourceAppNode:     "component",
+	DataStructureNode: "note",
+}
+
+func (g *Graph) Dot() string {
+	var b strings.Builder
+	b.WriteString("digraph tracking_plan {\n")
+	b.WriteString("  rankdir=LR;\n")
+	for _, n := range g.Nodes {
+		style := ""
+		if !n.Local {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(&b, "  %q [label=%q, shape=%s%s];\n", n.Id, n.Label, dotShapes[n.Kind], style)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", e.From, e.To, e.Label)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+var mermaidShapes = map[NodeKind][2]string{
+	DataProductNode:   {"[[", "]]"},
+	EventSpecNode:     {"[", "]"},
+	SourceAppNode:     {"([", "])"},
+	DataStructureNode: {"{{", "}}"},
+}
+
+func (g *Graph) Mermaid() string {
+	// mermaid ids are restricted to simple identifiers
+	ids := map[string]string{}
+	for i, n := range g.Nodes {
+		ids[n.Id] = fmt.Sprintf("n%d", i)
+	}
+
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, n := range g.Nodes {
+		shape := mermaidShapes[n.Kind]
+		label := strings.ReplaceAll(n.Label, `"`, "#quot;")
+		fmt.Fprintf(&b, "  %s%s\"%s\"%s\n", ids[n.Id], shape[0], label, shape[1])
+	}
+	for _, e := range g.Edges {
+		from, ok := ids[e.From]
+		if !ok {
+			continue
+		}
+		to, ok := ids[e.To]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&b, "  %s -->|%s| %s\n", from, e.Label, to)
+	}
+	return b.String()
+}
+
+type ImpactedSpec struct {
+	DataProduct string `json:"dataProduct"`
+	EventSpec   string `json:"eventSpecification"`
+	Reason      string `json:"reason"`
+
+	dataProductId string
+	eventSpecId   string
+}
+
+type Impact struct {
+	Uri          string         `json:"uri"`
+	EventSpecs   []ImpactedSpec `json:"eventSpecifications"`
+	DataProducts []string       `json:"dataProducts"`
+	SourceApps   []string       `json:"sourceApplications"`
+
+	sourceAppIds   []string
+	dataProductIds []string
+}
+
+// FindImpact lists every event specification, data product and source
+// application affected by a change to the data structure at uri. Entities
+// of a source application are attached to every event specification it emits
+func FindImpact(p *TrackingPlan, uri string) Impact {
+	impact := Impact{Uri: uri}
+
+	addSpec := func(dp model.DataProduct, es model.EventSpec, reason string) {
+		impact.EventSpecs = append(impact.EventSpecs, ImpactedSpec{
+			DataProduct:   dp.Data.Name,
+			EventSpec:     es.Name,
+			Reason:        reason,
+			dataProductId: dataProductId(dp),
+			eventSpecId:   eventSpecId(es),
+		})
+		if !slices.Contains(impact.dataProductIds, dataProductId(dp)) {
+			impact.dataProductIds = append(impact.dataProductIds, dataProductId(dp))
+			impact.DataProducts = append(impact.DataProducts, dp.Data.Name)
+		}
+	}
+
+	for _, sa := range p.SourceAppsUsingSchema(uri) {
+		impact.SourceApps = append(impact.SourceApps, sa.Data.Name)
+		impact.sourceAppIds = append(impact.sourceAppIds, sa.ResourceName)
+	}
+
+	for _, dp := range p.DataProducts {
+		for _, es := range dp.Data.EventSpecifications {
+			var reasons []string
+			if es.Event.Source == uri {
+				reasons = append(reasons, "event")
+			}
+			if slices.ContainsFunc(es.Entities.Tracked, func(e model.SchemaRef) bool { return e.Source == uri }) {
+				reasons = append(reasons, "tracked entity")
+			}
+			if slices.ContainsFunc(es.Entities.Enriched, func(e model.SchemaRef) bool { return e.Source == uri }) {
+				reasons = append(reasons, "enriched entity")
+			}
+			for _, saId := range SourceAppIds(dp, es) {
+				if slices.Contains(impact.sourceAppIds, saId) {
+					sa, _ := p.SourceApp(saId)
+					reasons = append(reasons, fmt.Sprintf("entity of source application %s", sa.Data.Name))
+				}
+			}
+			if len(reasons) > 0 {
+				addSpec(dp, es, strings.Join(reasons, ", "))
+			}
+		}
+	}
+
+	return impact
+}
+
+// NodeIds lists the graph nodes taking part in the impact, including the
+// changed data structure itself
+func (i Impact) NodeIds() []string {
+	ids := []string{i.Uri}
+	for _, es := range i.EventSpecs {
+		ids = append(ids, es.eventSpecId)
+	}
+	ids = append(ids, i.dataProductIds...)
+	for _, sa := range i.sourceAppIds {
+		ids = append(ids, sourceAppId(sa))
+	}
+	return ids
+}
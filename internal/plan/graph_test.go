@@ -0,0 +1,88 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package plan
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_BuildGraph(t *testing.T) {
+	g := BuildGraph(testPlan(t, "dps"))
+
+	expected := []Edge{
+		{From: "dp:dp-1", To: "es:es-1", Label: "event specification"},
+		{From: "dp:dp-1", To: "sa:sa-ios", Label: "source application"},
+		{From: "es:es-1", To: checkoutUri, Label: "event"},
+		{From: "es:es-1", To: userUri, Label: "tracked entity"},
+		{From: "sa:sa-ios", To: userUri, Label: "tracked entity"},
+	}
+	for _, e := range expected {
+		found := false
+		for _, ge := range g.Edges {
+			if ge == e {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("expected edge %v in %v", e, g.Edges)
+		}
+	}
+
+	for _, n := range g.Nodes {
+		if n.Id == userUri && n.Local {
+			t.Fatal("expected user data structure to not be local")
+		}
+		if n.Id == checkoutUri && !n.Local {
+			t.Fatal("expected checkout data structure to be local")
+		}
+	}
+
+	dot := g.Dot()
+	if !strings.Contains(dot, `"es:es-1" -> "iglu:com.acme/checkout_started/jsonschema/1-0-0" [label="event"];`) {
+		t.Fatalf("unexpected dot\n%s", dot)
+	}
+
+	mermaid := g.Mermaid()
+	if !strings.HasPrefix(mermaid, "flowchart LR\n") || !strings.Contains(mermaid, `{{"iglu:com.acme/user/jsonschema/1-0-0"}}`) {
+		t.Fatalf("unexpected mermaid\n%s", mermaid)
+	}
+}
+
+func Test_FindImpact(t *testing.T) {
+	p := testPlan(t, "dps")
+
+	impact := FindImpact(p, checkoutUri)
+	if len(impact.EventSpecs) != 1 || impact.EventSpecs[0].Reason != "event" {
+		t.Fatalf("unexpected impact %+v", impact)
+	}
+	if len(impact.DataProducts) != 1 || impact.DataProducts[0] != "Checkout" {
+		t.Fatalf("unexpected impacted data products %v", impact.DataProducts)
+	}
+
+	// the spec excludes the iOS source app so only its own entity counts
+	impact = FindImpact(p, userUri)
+	if len(impact.SourceApps) != 1 || impact.SourceApps[0] != "iOS" {
+		t.Fatalf("unexpected impacted source apps %v", impact.SourceApps)
+	}
+	if len(impact.EventSpecs) != 1 || impact.EventSpecs[0].Reason != "tracked entity" {
+		t.Fatalf("unexpected impact %+v", impact.EventSpecs)
+	}
+
+	sub := BuildGraph(p).Subgraph(impact.NodeIds())
+	if len(sub.Nodes) != 4 {
+		t.Fatalf("expected 4 nodes in impact subgraph, got %v", sub.Nodes)
+	}
+
+	if impact := FindImpact(p, "iglu:com.acme/other/jsonschema/1-0-0"); len(impact.EventSpecs) != 0 {
+		t.Fatalf("expected no impact, got %+v", impact)
+	}
+}
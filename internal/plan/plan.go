@@ -0,0 +1,228 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package plan
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/release"
+	"github.com/snowplow/snowplow-cli/internal/util"
+)
+
+// TrackingPlan is every local data product, source application and data
+// structure with references between them resolved
+type TrackingPlan struct {
+	DataProducts   []model.DataProduct
+	SourceApps     []model.SourceApp
+	DataStructures []DataStructure
+	IdToFileName   map[string]string
+}
+
+type DataStructure struct {
+	FileName string
+	DS       model.DataStructure
+	Self     model.DataStructureSelf
+}
+
+type SpecRef struct {
+	DataProduct model.DataProduct
+	Spec        model.EventSpec
+}
+
+// Load reads the tracking plan of the data products under dpPaths, their
+// templates rendered with vars, and of the data structures under dsPaths
+func Load(ctx context.Context, dpPaths []string, dsPaths []string, vars map[string]any) (*TrackingPlan, error) {
+	files := map[string]map[string]any{}
+	for _, p := range dpPaths {
+		if _, err := os.Stat(p); err != nil {
+			slog.Debug("plan", "msg", "data products path not found, skipping", "path", p)
+			continue
+		}
+		found, _, err := util.MaybeResourcesfromPaths([]string{p}, vars)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range found {
+			files[k] = v
+		}
+	}
+
+	local, err := release.ReadLocalDataProducts(ctx, files)
+	if err != nil {
+		return nil, err
+	}
+
+	var existingDsPaths []string
+	for _, p := range dsPaths {
+		if _, err := os.Stat(p); err != nil {
+			slog.Debug("plan", "msg", "data structures path not found, skipping", "path", p)
+			continue
+		}
+		existingDsPaths = append(existingDsPaths, p)
+	}
+
+	dss := map[string]model.DataStructure{}
+	if len(existingDsPaths) > 0 {
+		dss, _, err = util.DataStructuresFromPaths(existingDsPaths)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return New(local, dss)
+}
+
+func New(local *release.LocalFilesRefsResolved, dss map[string]model.DataStructure) (*TrackingPlan, error) {
+	var dataStructures []DataStructure
+	for f, ds := range dss {
+		data, err := ds.ParseData()
+		if err != nil {
+			return nil, err
+		}
+		dataStructures = append(dataStructures, DataStructure{FileName: f, DS: ds, Self: data.Self})
+	}
+	sort.Slice(dataStructures, func(i, j int) bool {
+		return dataStructures[i].Self.IgluUri() < dataStructures[j].Self.IgluUri()
+	})
+
+	dps := slices.Clone(local.DataProudcts)
+	sort.SliceStable(dps, func(i, j int) bool {
+		return dps[i].Data.Name < dps[j].Data.Name
+	})
+
+	sas := slices.Clone(local.SourceApps)
+	sort.SliceStable(sas, func(i, j int) bool {
+		return sas[i].Data.Name < sas[j].Data.Name
+	})
+
+	return &TrackingPlan{
+		DataProducts:   dps,
+		SourceApps:     sas,
+		DataStructures: dataStructures,
+		IdToFileName:   local.IdToFileName,
+	}, nil
+}
+
+// SchemaUris lists the event and every entity an event specification references
+func SchemaUris(es model.EventSpec) []string {
+	var uris []string
+	if es.Event.Source != "" {
+		uris = append(uris, es.Event.Source)
+	}
+	for _, e := range es.Entities.Tracked {
+		uris = append(uris, e.Source)
+	}
+	for _, e := range es.Entities.Enriched {
+		uris = append(uris, e.Source)
+	}
+	return uris
+}
+
+func SourceAppSchemaUris(sa model.SourceApp) []string {
+	var uris []string
+	if sa.Data.Entities == nil {
+		return uris
+	}
+	for _, e := range sa.Data.Entities.Tracked {
+		uris = append(uris, e.Source)
+	}
+	for _, e := range sa.Data.Entities.Enriched {
+		uris = append(uris, e.Source)
+	}
+	return uris
+}
+
+// SourceAppIds lists the source applications emitting an event
+// specification, those of its data product minus the excluded ones
+func SourceAppIds(dp model.DataProduct, es model.EventSpec) []string {
+	var excluded []string
+	for _, esa := range es.ExcludedSourceApplications {
+		excluded = append(excluded, esa["id"])
+	}
+	var ids []string
+	for _, sa := range dp.Data.SourceApplications {
+		if !slices.Contains(excluded, sa["id"]) {
+			ids = append(ids, sa["id"])
+		}
+	}
+	return ids
+}
+
+func (p *TrackingPlan) SpecsUsingSchema(uri string) []SpecRef {
+	var res []SpecRef
+	for _, dp := range p.DataProducts {
+		for _, es := range dp.Data.EventSpecifications {
+			if slices.Contains(SchemaUris(es), uri) {
+				res = append(res, SpecRef{dp, es})
+			}
+		}
+	}
+	return res
+}
+
+func (p *TrackingPlan) SourceAppsUsingSchema(uri string) []model.SourceApp {
+	var res []model.SourceApp
+	for _, sa := range p.SourceApps {
+		if slices.Contains(SourceAppSchemaUris(sa), uri) {
+			res = append(res, sa)
+		}
+	}
+	return res
+}
+
+func (p *TrackingPlan) SpecsForSourceApp(saId string) []SpecRef {
+	var res []SpecRef
+	for _, dp := range p.DataProducts {
+		for _, es := range dp.Data.EventSpecifications {
+			if slices.Contains(SourceAppIds(dp, es), saId) {
+				res = append(res, SpecRef{dp, es})
+			}
+		}
+	}
+	return res
+}
+
+func (p *TrackingPlan) SourceApp(id string) (model.SourceApp, bool) {
+	for _, sa := range p.SourceApps {
+		if sa.ResourceName == id {
+			return sa, true
+		}
+	}
+	return model.SourceApp{}, false
+}
+
+func (p *TrackingPlan) DataStructure(uri string) (DataStructure, bool) {
+	for _, ds := range p.DataStructures {
+		if ds.Self.IgluUri() == uri {
+			return ds, true
+		}
+	}
+	return DataStructure{}, false
+}
+
+// TriggerImagePath resolves a trigger image reference relative to the
+// data product file it is defined in
+func (p *TrackingPlan) TriggerImagePath(es model.EventSpec, t model.Trigger) string {
+	if t.Image == nil || t.Image.Ref == "" {
+		return ""
+	}
+	dpFile, ok := p.IdToFileName[es.ResourceName]
+	if !ok {
+		return ""
+	}
+	return filepath.Clean(filepath.Join(filepath.Dir(dpFile), t.Image.Ref))
+}
@@ -0,0 +1,177 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/release"
+)
+
+const (
+	checkoutUri = "iglu:com.acme/checkout_started/jsonschema/1-0-0"
+	userUri     = "iglu:com.acme/user/jsonschema/1-0-0"
+)
+
+func testPlan(t *testing.T, dir string) *TrackingPlan {
+	local := &release.LocalFilesRefsResolved{
+		SourceApps: []model.SourceApp{
+			{ResourceName: "sa-web", Data: model.SourceAppData{Name: "Web", AppIds: []string{"web"}}},
+			{ResourceName: "sa-ios", Data: model.SourceAppData{
+				Name:     "iOS",
+				AppIds:   []string{"ios"},
+				Entities: &model.EntitiesDef{Tracked: []model.SchemaRef{{Source: userUri}}},
+			}},
+		},
+		DataProudcts: []model.DataProduct{{
+			ResourceName: "dp-1",
+			Data: model.DataProductData{
+				Name:               "Checkout",
+				SourceApplications: []map[string]string{{"id": "sa-web"}, {"id": "sa-ios"}},
+				EventSpecifications: []model.EventSpec{{
+					ResourceName:               "es-1",
+					Name:                       "checkout started",
+					ExcludedSourceApplications: []map[string]string{{"id": "sa-ios"}},
+					Triggers:                   []model.Trigger{{Description: "user clicks checkout", Image: &model.Ref{Ref: "./images/checkout.png"}}},
+					Event:                      model.SchemaRef{Source: checkoutUri},
+					Entities:                   model.EntitiesDef{Tracked: []model.SchemaRef{{Source: userUri}}},
+				}},
+			},
+		}},
+		IdToFileName: map[string]string{
+			"dp-1": filepath.Join(dir, "checkout.yaml"),
+			"es-1": filepath.Join(dir, "checkout.yaml"),
+		},
+	}
+
+	dss := map[string]model.DataStructure{
+		filepath.Join(dir, "checkout.yaml"): {
+			Meta: model.DataStructureMeta{SchemaType: "event"},
+			Data: map[string]any{
+				"$schema":     "http://iglucentral.com/schemas/com.snowplowanalytics.self-desc/schema/jsonschema/1-0-0#",
+				"self":        map[string]any{"vendor": "com.acme", "name": "checkout_started", "format": "jsonschema", "version": "1-0-0"},
+				"description": "a checkout was started",
+				"type":        "object",
+				"required":    []any{"total"},
+				"properties":  map[string]any{"total": map[string]any{"type": "number", "description": "basket total"}},
+			},
+		},
+	}
+
+	p, err := New(local, dss)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func Test_Relations(t *testing.T) {
+	p := testPlan(t, "dps")
+
+	if p.SourceApps[0].Data.Name != "Web" || p.SourceApps[1].Data.Name != "iOS" {
+		t.Fatalf("expected source apps sorted by name, got %s, %s", p.SourceApps[0].Data.Name, p.SourceApps[1].Data.Name)
+	}
+
+	if specs := p.SpecsUsingSchema(userUri); len(specs) != 1 || specs[0].Spec.ResourceName != "es-1" {
+		t.Fatalf("unexpected specs using %s: %v", userUri, specs)
+	}
+
+	if sas := p.SourceAppsUsingSchema(userUri); len(sas) != 1 || sas[0].ResourceName != "sa-ios" {
+		t.Fatalf("unexpected source apps using %s: %v", userUri, sas)
+	}
+
+	if specs := p.SpecsForSourceApp("sa-ios"); len(specs) != 0 {
+		t.Fatalf("expected excluded source app to emit no specs, got %v", specs)
+	}
+
+	if specs := p.SpecsForSourceApp("sa-web"); len(specs) != 1 {
+		t.Fatalf("expected web to emit 1 spec, got %v", specs)
+	}
+
+	es := p.DataProducts[0].Data.EventSpecifications[0]
+	if img := p.TriggerImagePath(es, es.Triggers[0]); img != filepath.Join("dps", "images", "checkout.png") {
+		t.Fatalf("unexpected image path %s", img)
+	}
+}
+
+func Test_WriteDocs(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "images"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "images", "checkout.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "site")
+	if err := WriteDocs(testPlan(t, dir), out, DocsHtml); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(name string) string {
+		b, err := os.ReadFile(filepath.Join(out, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	dp := read("data-products/checkout.html")
+	for _, e := range []string{
+		`href="../data-structures/com.acme-checkout_started-1-0-0.html"`,
+		`<code>` + userUri + `</code>`,
+		`href="../source-apps/web.html"`,
+		`src="../images/checkout.png"`,
+		`id="es-es-1"`,
+	} {
+		if !strings.Contains(dp, e) {
+			t.Fatalf("expected data product page to contain %q\n%s", e, dp)
+		}
+	}
+
+	ds := read("data-structures/com.acme-checkout_started-1-0-0.html")
+	for _, e := range []string{`basket total`, `href="../data-products/checkout.html#es-es-1"`} {
+		if !strings.Contains(ds, e) {
+			t.Fatalf("expected data structure page to contain %q\n%s", e, ds)
+		}
+	}
+
+	sa := read("source-apps/ios.html")
+	if !strings.Contains(sa, userUri) {
+		t.Fatalf("expected source app page to list its entities\n%s", sa)
+	}
+
+	if !strings.Contains(read("search-index.json"), `"kind": "event specification"`) {
+		t.Fatal("expected search index to contain event specifications")
+	}
+	if read("images/checkout.png") != "png" {
+		t.Fatal("expected trigger image to be copied")
+	}
+}
+
+func Test_WriteDocsMarkdown(t *testing.T) {
+	out := t.TempDir()
+	if err := WriteDocs(testPlan(t, "dps"), out, DocsMarkdown); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(filepath.Join(out, "index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "- [Checkout](data-products/checkout.md)") {
+		t.Fatalf("unexpected index\n%s", b)
+	}
+}
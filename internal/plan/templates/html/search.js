@@ -0,0 +1,28 @@
+document.addEventListener('DOMContentLoaded', function () {
+  var input = document.getElementById('search');
+  var results = document.getElementById('search-results');
+  var root = document.body.getAttribute('data-root') || '';
+  var index = window.SEARCH_INDEX || [];
+
+  input.addEventListener('input', function () {
+    var terms = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    results.innerHTML = '';
+    if (terms.length === 0) {
+      return;
+    }
+    index.filter(function (entry) {
+      var haystack = (entry.title + ' ' + entry.kind + ' ' + entry.text).toLowerCase();
+      return terms.every(function (t) { return haystack.indexOf(t) !== -1; });
+    }).slice(0, 20).forEach(function (entry) {
+      var li = document.createElement('li');
+      var a = document.createElement('a');
+      a.href = root + entry.url;
+      a.textContent = entry.title;
+      var kind = document.createElement('small');
+      kind.textContent = entry.kind;
+      a.appendChild(kind);
+      li.appendChild(a);
+      results.appendChild(li);
+    });
+  });
+});
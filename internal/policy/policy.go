@@ -0,0 +1,249 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-yaml"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/ext"
+)
+
+const (
+	DataStructure     = "data-structure"
+	DataProduct       = "data-product"
+	SourceApplication = "source-application"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Path points at the part of a resource a violation is reported on,
+// policies check resources as a whole
+const Path = "/data"
+
+// topLevelKeys of resource files, declared as variables of expressions
+// along with resource for the whole document
+var topLevelKeys = []string{"apiVersion", "resourceType", "resourceName", "meta", "data"}
+
+var env = sync.OnceValues(func() (*cel.Env, error) {
+	opts := []cel.EnvOption{
+		cel.Variable("resource", cel.MapType(cel.StringType, cel.DynType)),
+		ext.Strings(),
+	}
+	for _, k := range topLevelKeys {
+		opts = append(opts, cel.Variable(k, cel.DynType))
+	}
+	return cel.NewEnv(opts...)
+})
+
+// Policy is a CEL expression (https://cel.dev) evaluated against every
+// resource it applies to, resources for which the expression is false
+// violate the policy. The CEL string extensions are available
+type Policy struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Resources   []string `yaml:"resources"`
+	Severity    string   `yaml:"severity"`
+	Expression  string   `yaml:"expression"`
+	Message     string   `yaml:"message"`
+
+	program cel.Program
+}
+
+// Violation of a policy by a resource, or a failure to evaluate the policy
+type Violation struct {
+	Policy   string
+	Severity string
+	Path     string
+	Message  string
+}
+
+// LoadDir reads every .yaml, .yml and .json policy file of a directory
+func LoadDir(dir string) ([]Policy, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy directory: %w", err)
+	}
+
+	var policies []Policy
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || !slices.Contains([]string{".yaml", ".yml", ".json"}, ext) {
+			continue
+		}
+		fileName := filepath.Join(dir, e.Name())
+		file, err := os.ReadFile(fileName)
+		if err != nil {
+			return nil, err
+		}
+		p, err := Load(file, strings.TrimSuffix(e.Name(), ext))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fileName, err)
+		}
+		policies = append(policies, p)
+	}
+
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].Name < policies[j].Name
+	})
+
+	return policies, nil
+}
+
+// Load parses a policy, defaultName is used when it does not declare a name
+func Load(file []byte, defaultName string) (Policy, error) {
+	var p Policy
+	if err := yaml.Unmarshal(file, &p); err != nil {
+		return p, fmt.Errorf("failed to parse policy: %w", err)
+	}
+
+	if p.Name == "" {
+		p.Name = defaultName
+	}
+	if p.Severity == "" {
+		p.Severity = SeverityError
+	}
+	if p.Severity != SeverityError && p.Severity != SeverityWarning {
+		return p, fmt.Errorf("policy %s: unknown severity %s, expected one of %s|%s", p.Name, p.Severity, SeverityError, SeverityWarning)
+	}
+	for _, r := range p.Resources {
+		if !slices.Contains([]string{DataStructure, DataProduct, SourceApplication}, r) {
+			return p, fmt.Errorf("policy %s: unknown resource %s, expected one of %s|%s|%s", p.Name, r, DataStructure, DataProduct, SourceApplication)
+		}
+	}
+	if strings.TrimSpace(p.Expression) == "" {
+		return p, fmt.Errorf("policy %s: expression is required", p.Name)
+	}
+
+	e, err := env()
+	if err != nil {
+		return p, err
+	}
+	ast, issues := e.Compile(p.Expression)
+	if issues.Err() != nil {
+		return p, fmt.Errorf("policy %s: %w", p.Name, issues.Err())
+	}
+	if out := ast.OutputType(); !out.IsExactType(cel.BoolType) && !out.IsExactType(cel.DynType) {
+		return p, fmt.Errorf("policy %s: expression should evaluate to a bool, got %s", p.Name, out)
+	}
+	p.program, err = e.Program(ast)
+	if err != nil {
+		return p, fmt.Errorf("policy %s: %w", p.Name, err)
+	}
+
+	return p, nil
+}
+
+func (p Policy) AppliesTo(resourceType string) bool {
+	return len(p.Resources) == 0 || slices.Contains(p.Resources, resourceType)
+}
+
+func (p Policy) eval(resource map[string]any) (ref.Val, error) {
+	resource, err := normalize(resource)
+	if err != nil {
+		return nil, err
+	}
+	vars := map[string]any{"resource": resource}
+	for _, k := range topLevelKeys {
+		if v, ok := resource[k]; ok {
+			vars[k] = v
+		}
+	}
+	v, _, err := p.program.Eval(vars)
+	return v, err
+}
+
+// Check evaluates the policy against a resource as written in its file.
+// Top level keys, eg: data, meta, are in scope as well as resource for
+// the whole document
+func (p Policy) Check(resource map[string]any) (Violation, bool) {
+	v, err := p.eval(resource)
+	if err != nil {
+		return Violation{p.Name, p.Severity, Path, fmt.Sprintf("failed to evaluate: %s", err)}, false
+	}
+
+	ok, isBool := v.Value().(bool)
+	if !isBool {
+		return Violation{p.Name, p.Severity, Path, fmt.Sprintf("expression should evaluate to a bool, got %s", v.Type().TypeName())}, false
+	}
+	if ok {
+		return Violation{}, true
+	}
+
+	msg := p.Message
+	if msg == "" {
+		msg = fmt.Sprintf("violates %s", p.Expression)
+	}
+	return Violation{p.Name, p.Severity, Path, msg}, false
+}
+
+// CheckAll evaluates every policy applying to the resource type
+func CheckAll(policies []Policy, resourceType string, resource map[string]any) []Violation {
+	var result []Violation
+	for _, p := range policies {
+		if !p.AppliesTo(resourceType) {
+			continue
+		}
+		if v, ok := p.Check(resource); !ok {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// normalize converts a resource decoded from yaml or json to the json
+// values CEL works with, whole numbers are ints
+func normalize(resource map[string]any) (map[string]any, error) {
+	b, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var result map[string]any
+	if err := d.Decode(&result); err != nil {
+		return nil, err
+	}
+	return numbers(result).(map[string]any), nil
+}
+
+func numbers(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		for k, item := range x {
+			x[k] = numbers(item)
+		}
+	case []any:
+		for i, item := range x {
+			x[i] = numbers(item)
+		}
+	case json.Number:
+		if i, err := x.Int64(); err == nil {
+			return i
+		}
+		f, _ := x.Float64()
+		return f
+	}
+	return v
+}
@@ -0,0 +1,148 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+)
+
+var testResource = map[string]any{
+	"apiVersion":   "v1",
+	"resourceType": "data-product",
+	"data": map[string]any{
+		"name":   "Checkout",
+		"domain": "sales",
+		"owner":  "team@acme.com",
+		"eventSpecifications": []any{
+			map[string]any{"name": "Order placed", "description": "placed", "triggers": []any{map[string]any{"description": "x"}}},
+			map[string]any{"name": "order_cancelled"},
+		},
+		"tags":     []string{"core", "revenue"},
+		"priority": 2,
+	},
+}
+
+func load(t *testing.T, expression string) (Policy, error) {
+	t.Helper()
+	file, err := yaml.Marshal(map[string]string{"expression": expression})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Load(file, "p")
+}
+
+func Test_Check(t *testing.T) {
+	for _, src := range []string{
+		`1 + 2 * 3 == 7`,
+		`10 / 4 == 2 && 10.0 / 4.0 == 2.5`,
+		`-data.priority == -2`,
+		`"a" + 'b' == "ab"`,
+		`data.name == "Checkout"`,
+		`data.domain in ["sales", "marketing"]`,
+		`"core" in data.tags`,
+		`"owner" in data`,
+		`has(data.owner) && !has(data.missing)`,
+		`size(data.eventSpecifications) == 2`,
+		`!data.eventSpecifications.all(es, has(es.description))`,
+		`data.eventSpecifications.exists(es, es.name.startsWith("order"))`,
+		`data.eventSpecifications.exists_one(es, has(es.triggers))`,
+		`data.eventSpecifications.filter(es, !has(es.description)).size() == 1`,
+		`data.eventSpecifications.map(es, es.name.lowerAscii()) == ["order placed", "order_cancelled"]`,
+		`data.eventSpecifications[1].name.matches("^[a-z_]+$")`,
+		`(data["owner"].endsWith("@acme.com") ? "acme" : "other") == "acme"`,
+		`data.priority >= 2 && data.priority < 3.5`,
+		`resource.resourceType == resourceType`,
+		`data.name.contains("out") || data.missing`,
+	} {
+		p, err := load(t, src)
+		if err != nil {
+			t.Fatalf("%s: %s", src, err)
+		}
+		if v, ok := p.Check(testResource); !ok {
+			t.Fatalf("%s: %s", src, v.Message)
+		}
+	}
+}
+
+func Test_CheckErrors(t *testing.T) {
+	for _, src := range []string{`data.missing == 1`, `data.name + 1 == 2`, `data.eventSpecifications[5].name == ""`, `data.priority`} {
+		p, err := load(t, src)
+		if err != nil {
+			t.Fatalf("%s: %s", src, err)
+		}
+		v, ok := p.Check(testResource)
+		if ok || v.Severity != SeverityError || v.Path != Path {
+			t.Fatalf("%s: expected an evaluation error got %#v", src, v)
+		}
+	}
+
+	for _, src := range []string{`1 +`, `(1`, `unknown`, `size(1)`, `nope()`, `"open`, `a = b`, `1 + 2`} {
+		if _, err := load(t, src); err == nil {
+			t.Fatalf("%s: expected a compile error", src)
+		}
+	}
+}
+
+func Test_LoadDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"owner.yaml": `resources: [data-product]
+expression: has(data.owner)
+message: owner is required
+`,
+		"described.yml": `name: described
+severity: warning
+expression: data.eventSpecifications.all(es, has(es.description))
+`,
+		"ignored.txt": "not a policy",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	policies, err := LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(policies) != 2 || policies[0].Name != "described" || policies[1].Name != "owner" {
+		t.Fatalf("unexpected policies %v", policies)
+	}
+
+	violations := CheckAll(policies, DataProduct, testResource)
+	expected := []Violation{{"described", "warning", Path, "violates data.eventSpecifications.all(es, has(es.description))"}}
+	if !reflect.DeepEqual(violations, expected) {
+		t.Fatalf("unexpected violations %#v", violations)
+	}
+
+	if v := CheckAll(policies, SourceApplication, map[string]any{}); len(v) != 1 || v[0].Policy != "described" || v[0].Severity != "warning" {
+		t.Fatalf("expected an evaluation error got %#v", v)
+	}
+}
+
+func Test_LoadErrors(t *testing.T) {
+	for _, p := range []string{
+		"expression: ''",
+		"expression: 'true'\nseverity: fatal",
+		"expression: 'true'\nresources: [event]",
+		"expression: '1 +'",
+	} {
+		if _, err := Load([]byte(p), "p"); err == nil {
+			t.Fatalf("expected error for %q", p)
+		}
+	}
+}
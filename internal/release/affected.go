@@ -0,0 +1,83 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package release
+
+import (
+	"path/filepath"
+	"slices"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/snowplow/snowplow-cli/internal/git"
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+// AffectedFiles are the files for which changed is true and the data
+// products referencing a source application or a trigger image for which it
+// is. changed is called with absolute paths
+func AffectedFiles(files map[string]map[string]any, changed func(fileName string) bool) map[string]bool {
+	result := map[string]bool{}
+	changedRef := func(f string, ref string) bool {
+		abs, err := filepath.Abs(filepath.Join(filepath.Dir(f), ref))
+		return err == nil && changed(abs)
+	}
+
+	for f := range files {
+		if abs, err := filepath.Abs(f); err == nil && changed(abs) {
+			result[f] = true
+		}
+	}
+
+	for f, r := range files {
+		if r["resourceType"] != "data-product" || result[f] {
+			continue
+		}
+		var dp model.DataProduct
+		if err := mapstructure.Decode(r, &dp); err != nil {
+			continue
+		}
+		for _, ref := range dp.Data.SourceApplications {
+			if saRef, ok := ref["$ref"]; ok && changedRef(f, saRef) {
+				result[f] = true
+			}
+		}
+		for _, es := range dp.Data.EventSpecifications {
+			for _, ref := range es.ExcludedSourceApplications {
+				if saRef, ok := ref["$ref"]; ok && changedRef(f, saRef) {
+					result[f] = true
+				}
+			}
+			for _, t := range es.Triggers {
+				if t.Image != nil && t.Image.Ref != "" && changedRef(f, t.Image.Ref) {
+					result[f] = true
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// ChangedSince are the files changed in Git since ref and the data products
+// depending on them. A file patched by an overlay, see util.ApplyOverlay,
+// changes with its patch files
+func ChangedSince(files map[string]map[string]any, ref string, patches map[string][]string) (map[string]bool, error) {
+	changed, err := git.ChangedFiles(ref)
+	if err != nil {
+		return nil, err
+	}
+	isChanged := func(fileName string) bool {
+		abs, err := git.AbsPath(fileName)
+		return err == nil && changed[abs]
+	}
+	return AffectedFiles(files, func(fileName string) bool {
+		return isChanged(fileName) || slices.ContainsFunc(patches[fileName], isChanged)
+	}), nil
+}
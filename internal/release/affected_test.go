@@ -0,0 +1,119 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package release
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func Test_AffectedFiles(t *testing.T) {
+	dir := t.TempDir()
+	web := filepath.Join(dir, "source-apps", "web.yaml")
+	app := filepath.Join(dir, "source-apps", "app.yaml")
+	image := filepath.Join(dir, "data-products", "images", "cart.png")
+	checkout := filepath.Join(dir, "data-products", "checkout.yaml")
+	signup := filepath.Join(dir, "data-products", "signup.yaml")
+
+	files := map[string]map[string]any{
+		web: {"resourceType": "source-application"},
+		app: {"resourceType": "source-application"},
+		checkout: {"resourceType": "data-product", "data": map[string]any{
+			"eventSpecifications": []any{map[string]any{
+				"excludedSourceApplications": []any{map[string]any{"$ref": "../source-apps/app.yaml"}},
+				"triggers":                   []any{map[string]any{"image": map[string]any{"$ref": "./images/cart.png"}}},
+			}},
+		}},
+		signup: {"resourceType": "data-product", "data": map[string]any{
+			"sourceApplications": []any{map[string]any{"$ref": "../source-apps/web.yaml"}},
+		}},
+	}
+
+	for changed, expected := range map[string][]string{
+		web:   {web, signup},
+		app:   {app, checkout},
+		image: {checkout},
+	} {
+		affected := AffectedFiles(files, func(f string) bool { return f == changed })
+		if len(affected) != len(expected) {
+			t.Fatalf("change of %s affected %v, want %v", changed, affected, expected)
+		}
+		for _, f := range expected {
+			if !affected[f] {
+				t.Fatalf("change of %s affected %v, want %v", changed, affected, expected)
+			}
+		}
+	}
+}
+
+func Test_ChangedSince(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	git := func(args ...string) {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v %s", args, err, out)
+		}
+	}
+	write := func(name string, content string) string {
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		abs, err := filepath.Abs(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return abs
+	}
+
+	git("init", "-q")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "test")
+	sa := write("source-apps/web.yaml", "name: web")
+	dp := write("data-products/checkout.yaml", "name: checkout")
+	other := write("data-products/signup.yaml", "name: signup")
+	git("add", ".")
+	git("commit", "-q", "-m", "base")
+	write("source-apps/web.yaml", "name: web app")
+
+	files := map[string]map[string]any{
+		sa: {"resourceType": "source-application"},
+		dp: {"resourceType": "data-product", "data": map[string]any{
+			"sourceApplications": []any{map[string]any{"$ref": "../source-apps/web.yaml"}},
+		}},
+		other: {"resourceType": "data-product", "data": map[string]any{}},
+	}
+	affected, err := ChangedSince(files, "HEAD", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !affected[sa] || !affected[dp] || affected[other] {
+		t.Fatalf("unexpected affected files %v", affected)
+	}
+
+	// a changed overlay patch changes the file it patches
+	patch := write("overlays/prod/signup.yaml", "name: signup")
+	affected, err = ChangedSince(files, "HEAD", map[string][]string{other: {patch}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !affected[other] {
+		t.Fatalf("expected the patched file affected, got %v", affected)
+	}
+}
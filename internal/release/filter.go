@@ -0,0 +1,321 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package release
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/snowplow/snowplow-cli/internal/console"
+)
+
+const (
+	SelectDataProduct = "dp"
+	SelectDomain      = "domain"
+	SelectOwner       = "owner"
+	SelectEventSpec   = "es"
+	SelectSourceApp   = "sa"
+)
+
+var selectorKinds = []string{SelectDataProduct, SelectDomain, SelectOwner, SelectEventSpec, SelectSourceApp}
+
+// Selector picks resources by one of their attributes. Names and resource
+// names are both matched, patterns use path.Match syntax
+type Selector struct {
+	Kind    string
+	Pattern string
+}
+
+// ParseSelector parses a selector written kind=pattern, a bare pattern
+// selects data products
+func ParseSelector(s string) (Selector, error) {
+	kind, pattern, found := strings.Cut(s, "=")
+	if !found {
+		kind, pattern = SelectDataProduct, s
+	}
+	kind = strings.TrimSpace(kind)
+	if !slices.Contains(selectorKinds, kind) {
+		return Selector{}, fmt.Errorf("invalid selector %q, kind must be one of %s", s, strings.Join(selectorKinds, ", "))
+	}
+	if pattern == "" {
+		return Selector{}, fmt.Errorf("invalid selector %q, pattern is empty", s)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return Selector{}, fmt.Errorf("invalid selector %q: %w", s, err)
+	}
+	return Selector{Kind: kind, Pattern: pattern}, nil
+}
+
+// Filter limits a change set to the resources selected by Only, less the
+// ones selected by Exclude. The zero value keeps everything
+type Filter struct {
+	Only    []Selector
+	Exclude []Selector
+	// Files limits the changes to resources defined in these files when not
+	// nil, see ChangedSince
+	Files map[string]bool
+}
+
+func ParseFilter(only []string, exclude []string) (Filter, error) {
+	var f Filter
+	for _, s := range only {
+		sel, err := ParseSelector(s)
+		if err != nil {
+			return f, err
+		}
+		f.Only = append(f.Only, sel)
+	}
+	for _, s := range exclude {
+		sel, err := ParseSelector(s)
+		if err != nil {
+			return f, err
+		}
+		f.Exclude = append(f.Exclude, sel)
+	}
+	return f, nil
+}
+
+func (f Filter) isEmpty() bool {
+	return len(f.Only) == 0 && len(f.Exclude) == 0 && f.Files == nil
+}
+
+// attributes of a resource by selector kind
+type attributes map[string][]string
+
+func (a attributes) add(kind string, values ...string) {
+	for _, v := range values {
+		if v != "" {
+			a[kind] = append(a[kind], v)
+		}
+	}
+}
+
+func (a attributes) matches(selectors []Selector) bool {
+	for _, s := range selectors {
+		for _, v := range a[s.Kind] {
+			if ok, _ := path.Match(s.Pattern, v); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (f Filter) keeps(file string, a attributes) bool {
+	if f.Files != nil && !f.Files[file] {
+		return false
+	}
+	if len(f.Only) > 0 && !a.matches(f.Only) {
+		return false
+	}
+	return !a.matches(f.Exclude)
+}
+
+type filterContext struct {
+	dataProducts map[string]console.RemoteDataProduct
+	sourceApps   map[string]console.RemoteSourceApplication
+	saUsers      map[string][]console.RemoteDataProduct
+}
+
+func newFilterContext(local LocalFilesRefsResolved, remote console.DataProductsAndRelatedResources) filterContext {
+	fc := filterContext{
+		dataProducts: map[string]console.RemoteDataProduct{},
+		sourceApps:   map[string]console.RemoteSourceApplication{},
+		saUsers:      map[string][]console.RemoteDataProduct{},
+	}
+	for _, dp := range remote.DataProducts {
+		fc.dataProducts[dp.Id] = dp
+	}
+	for _, sa := range remote.SourceApplication {
+		fc.sourceApps[sa.Id] = sa
+	}
+	for _, dp := range local.DataProudcts {
+		fc.dataProducts[dp.ResourceName] = LocalDpToRemote(dp)
+	}
+	for _, sa := range local.SourceApps {
+		fc.sourceApps[sa.ResourceName] = localSaToRemote(sa)
+	}
+	for _, dp := range fc.dataProducts {
+		for _, id := range dp.SourceApplicationIds {
+			fc.saUsers[id] = append(fc.saUsers[id], dp)
+		}
+	}
+	return fc
+}
+
+func (fc filterContext) addSourceApps(a attributes, ids []string) {
+	for _, id := range ids {
+		a.add(SelectSourceApp, id, fc.sourceApps[id].Name)
+	}
+}
+
+func (fc filterContext) dataProduct(dp console.RemoteDataProduct) attributes {
+	a := attributes{}
+	a.add(SelectDataProduct, dp.Id, dp.Name)
+	a.add(SelectDomain, dp.Domain)
+	a.add(SelectOwner, dp.Owner)
+	fc.addSourceApps(a, dp.SourceApplicationIds)
+	return a
+}
+
+// event specs belong to their data product
+func (fc filterContext) eventSpec(es console.RemoteEventSpec) attributes {
+	a := attributes{}
+	a.add(SelectEventSpec, es.Id, es.Name)
+	dp := fc.dataProducts[es.DataProductId]
+	a.add(SelectDataProduct, dp.Id, dp.Name)
+	a.add(SelectDomain, dp.Domain)
+	a.add(SelectOwner, dp.Owner)
+	fc.addSourceApps(a, es.SourceApplicationIds)
+	return a
+}
+
+// source apps belong to every data product that uses them
+func (fc filterContext) sourceApp(sa console.RemoteSourceApplication) attributes {
+	a := attributes{}
+	a.add(SelectSourceApp, sa.Id, sa.Name)
+	a.add(SelectOwner, sa.Owner)
+	for _, dp := range fc.saUsers[sa.Id] {
+		a.add(SelectDataProduct, dp.Id, dp.Name)
+		a.add(SelectDomain, dp.Domain)
+		a.add(SelectOwner, dp.Owner)
+	}
+	return a
+}
+
+func filterResources[T any](resources []T, id func(T) string, keep map[string]bool, skip func(T)) []T {
+	var kept []T
+	for _, r := range resources {
+		if keep[id(r)] {
+			kept = append(kept, r)
+		} else {
+			skip(r)
+		}
+	}
+	return kept
+}
+
+// apply drops the changes to resources the filter does not keep. Creates
+// that kept changes depend on are kept regardless
+func (f Filter) apply(changeSet *DataProductChangeSet, local LocalFilesRefsResolved, remote console.DataProductsAndRelatedResources) {
+	if f.isEmpty() {
+		return
+	}
+	fc := newFilterContext(local, remote)
+
+	keepSa := map[string]bool{}
+	keepDp := map[string]bool{}
+	keepEs := map[string]bool{}
+
+	for _, sa := range local.SourceApps {
+		keepSa[sa.ResourceName] = f.keeps(local.IdToFileName[sa.ResourceName], fc.sourceApp(localSaToRemote(sa)))
+	}
+	for _, localDp := range local.DataProudcts {
+		dp := LocalDpToRemote(localDp)
+		keepDp[dp.Id] = f.keeps(local.IdToFileName[dp.Id], fc.dataProduct(dp))
+		for _, localEs := range localDp.Data.EventSpecifications {
+			es := LocalEventSpecToRemote(localEs, dp.SourceApplicationIds, dp.Id)
+			keepEs[es.Id] = f.keeps(local.IdToFileName[dp.Id], fc.eventSpec(es))
+		}
+	}
+	for _, es := range changeSet.esDelete {
+		keepEs[es.Id] = f.keeps(local.IdToFileName[es.DataProductId], fc.eventSpec(es))
+	}
+
+	// kept changes may need resources that do not exist yet
+	creatingSa := map[string]bool{}
+	for _, sa := range changeSet.saCreate {
+		creatingSa[sa.Id] = true
+	}
+	creatingDp := map[string]bool{}
+	for _, dp := range changeSet.dpCreate {
+		creatingDp[dp.Id] = true
+	}
+	requireSas := func(ids []string) {
+		for _, id := range ids {
+			if creatingSa[id] {
+				keepSa[id] = true
+			}
+		}
+	}
+	for _, es := range changeSet.esCreate {
+		if keepEs[es.Id] {
+			if creatingDp[es.DataProductId] {
+				keepDp[es.DataProductId] = true
+			}
+			requireSas(es.SourceApplicationIds)
+		}
+	}
+	for _, dp := range slices.Concat(changeSet.dpCreate, changeSet.dpUpdate) {
+		if keepDp[dp.Id] {
+			requireSas(dp.SourceApplicationIds)
+		}
+	}
+
+	if changeSet.skippedFiles == nil {
+		changeSet.skippedFiles = map[string]bool{}
+	}
+	skipped := 0
+	skip := func(id string, file string) {
+		skipped++
+		changeSet.skippedFiles[file] = true
+		slog.Debug("sync", "msg", "skipping change excluded by filters", "resource name", id, "file", file)
+	}
+
+	saId := func(sa console.RemoteSourceApplication) string { return sa.Id }
+	skipSa := func(sa console.RemoteSourceApplication) { skip(sa.Id, local.IdToFileName[sa.Id]) }
+	changeSet.saCreate = filterResources(changeSet.saCreate, saId, keepSa, skipSa)
+	changeSet.saUpdate = filterResources(changeSet.saUpdate, saId, keepSa, skipSa)
+
+	dpId := func(dp console.RemoteDataProduct) string { return dp.Id }
+	skipDp := func(dp console.RemoteDataProduct) { skip(dp.Id, local.IdToFileName[dp.Id]) }
+	changeSet.dpCreate = filterResources(changeSet.dpCreate, dpId, keepDp, skipDp)
+	changeSet.dpUpdate = filterResources(changeSet.dpUpdate, dpId, keepDp, skipDp)
+
+	esId := func(es console.RemoteEventSpec) string { return es.Id }
+	skipEs := func(es console.RemoteEventSpec) { skip(es.Id, local.IdToFileName[es.DataProductId]) }
+	changeSet.esCreate = filterResources(changeSet.esCreate, esId, keepEs, skipEs)
+	changeSet.esUpdate = filterResources(changeSet.esUpdate, esId, keepEs, skipEs)
+	changeSet.esDelete = filterResources(changeSet.esDelete, esId, keepEs, skipEs)
+
+	changeSet.imageCreate = slices.DeleteFunc(changeSet.imageCreate, func(i TriggerImageReference) bool {
+		return !keepEs[i.eventSpecId]
+	})
+	changeSet.localEventSpecIds = slices.DeleteFunc(changeSet.localEventSpecIds, func(id string) bool {
+		return !keepEs[id]
+	})
+
+	// validation only checks compatibility of the kept changes and state is
+	// only recorded for what was synced
+	kept := func(idToFileName map[string]string) map[string]string {
+		res := map[string]string{}
+		for id, f := range idToFileName {
+			if keepSa[id] || keepDp[id] || keepEs[id] {
+				res[id] = f
+			}
+		}
+		return res
+	}
+	changeSet.IdToFileName = kept(changeSet.IdToFileName)
+	changeSet.localIdToFileName = kept(changeSet.localIdToFileName)
+
+	if skipped > 0 {
+		slog.Info("sync", "msg", "skipping changes excluded by filters", "count", skipped)
+	}
+}
+
+// Skipped reports whether changes in file were left out by a filter
+func (cs *DataProductChangeSet) Skipped(file string) bool {
+	return cs.skippedFiles[file]
+}
@@ -0,0 +1,164 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package release
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+func Test_ParseSelector(t *testing.T) {
+	sel, err := ParseSelector("checkout")
+	if err != nil || sel != (Selector{Kind: SelectDataProduct, Pattern: "checkout"}) {
+		t.Fatalf("unexpected selector %v %v", sel, err)
+	}
+
+	sel, err = ParseSelector("es=add to *")
+	if err != nil || sel != (Selector{Kind: SelectEventSpec, Pattern: "add to *"}) {
+		t.Fatalf("unexpected selector %v %v", sel, err)
+	}
+
+	for _, invalid := range []string{"team=payments", "dp=", "dp=[a"} {
+		if _, err := ParseSelector(invalid); err == nil {
+			t.Fatalf("expected %q to be invalid", invalid)
+		}
+	}
+}
+
+func filterFixture() (LocalFilesRefsResolved, console.DataProductsAndRelatedResources) {
+	local := LocalFilesRefsResolved{
+		SourceApps: []model.SourceApp{
+			{ResourceName: "sa-web", Data: model.SourceAppData{Name: "web"}},
+			{ResourceName: "sa-app", Data: model.SourceAppData{Name: "app", Description: "edited"}},
+		},
+		DataProudcts: []model.DataProduct{
+			{ResourceName: "dp-checkout", Data: model.DataProductData{
+				Name:               "checkout",
+				Domain:             "payments",
+				SourceApplications: []map[string]string{{"id": "sa-web"}},
+				EventSpecifications: []model.EventSpec{
+					{ResourceName: "es-add", Name: "add to cart"},
+					{ResourceName: "es-remove", Name: "remove from cart"},
+				},
+			}},
+			{ResourceName: "dp-search", Data: model.DataProductData{
+				Name:               "search",
+				Domain:             "discovery",
+				Description:        "edited",
+				SourceApplications: []map[string]string{{"id": "sa-app"}},
+			}},
+		},
+		IdToFileName: map[string]string{
+			"sa-web":      "source-apps/web.yaml",
+			"sa-app":      "source-apps/app.yaml",
+			"dp-checkout": "checkout.yaml",
+			"es-add":      "checkout.yaml",
+			"es-remove":   "checkout.yaml",
+			"dp-search":   "search.yaml",
+		},
+	}
+	remote := console.DataProductsAndRelatedResources{
+		SourceApplication: []console.RemoteSourceApplication{{Id: "sa-app", Name: "app"}},
+		DataProducts:      []console.RemoteDataProduct{{Id: "dp-search", Name: "search", Domain: "discovery", SourceApplicationIds: []string{"sa-app"}}},
+	}
+	return local, remote
+}
+
+func filteredChanges(t *testing.T, only []string, exclude []string) *DataProductChangeSet {
+	local, remote := filterFixture()
+	changes, err := findChanges(local, remote, map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes.localIdToFileName = local.IdToFileName
+	filter, err := ParseFilter(only, exclude)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filter.apply(changes, local, remote)
+	return changes
+}
+
+func ids[T any](resources []T, id func(T) string) []string {
+	var res []string
+	for _, r := range resources {
+		res = append(res, id(r))
+	}
+	slices.Sort(res)
+	return res
+}
+
+func Test_Filter_Only(t *testing.T) {
+	changes := filteredChanges(t, []string{"domain=payments"}, nil)
+
+	if got := ids(changes.dpCreate, func(dp console.RemoteDataProduct) string { return dp.Id }); !slices.Equal(got, []string{"dp-checkout"}) {
+		t.Fatalf("unexpected dp creates %v", got)
+	}
+	if len(changes.dpUpdate) != 0 || len(changes.saUpdate) != 0 {
+		t.Fatalf("expected search changes to be left out, got %v %v", changes.dpUpdate, changes.saUpdate)
+	}
+	if got := ids(changes.saCreate, func(sa console.RemoteSourceApplication) string { return sa.Id }); !slices.Equal(got, []string{"sa-web"}) {
+		t.Fatalf("unexpected sa creates %v", got)
+	}
+	if got := ids(changes.esCreate, func(es console.RemoteEventSpec) string { return es.Id }); !slices.Equal(got, []string{"es-add", "es-remove"}) {
+		t.Fatalf("unexpected es creates %v", got)
+	}
+	if !changes.Skipped("search.yaml") || !changes.Skipped("source-apps/app.yaml") || changes.Skipped("checkout.yaml") {
+		t.Fatalf("unexpected skipped files %v", changes.skippedFiles)
+	}
+	if _, ok := changes.localIdToFileName["dp-search"]; ok {
+		t.Fatal("expected skipped resources to be left out of the recorded state")
+	}
+}
+
+func Test_Filter_KeepsRequiredCreates(t *testing.T) {
+	changes := filteredChanges(t, []string{"es=add*"}, nil)
+
+	if got := ids(changes.esCreate, func(es console.RemoteEventSpec) string { return es.Id }); !slices.Equal(got, []string{"es-add"}) {
+		t.Fatalf("unexpected es creates %v", got)
+	}
+	if len(changes.dpCreate) != 1 || len(changes.saCreate) != 1 {
+		t.Fatalf("expected the data product and source app creates to be kept, got %v %v", changes.dpCreate, changes.saCreate)
+	}
+	if !slices.Equal(changes.localEventSpecIds, []string{"es-add"}) {
+		t.Fatalf("expected only the selected event spec to be released, got %v", changes.localEventSpecIds)
+	}
+}
+
+func Test_Filter_Exclude(t *testing.T) {
+	changes := filteredChanges(t, nil, []string{"dp=checkout"})
+
+	if len(changes.dpCreate) != 0 || len(changes.esCreate) != 0 || len(changes.saCreate) != 0 {
+		t.Fatalf("expected checkout changes to be left out, got %v %v %v", changes.dpCreate, changes.esCreate, changes.saCreate)
+	}
+	if len(changes.dpUpdate) != 1 || len(changes.saUpdate) != 1 {
+		t.Fatalf("expected search changes to be kept, got %v %v", changes.dpUpdate, changes.saUpdate)
+	}
+}
+
+func Test_Filter_Files(t *testing.T) {
+	local, remote := filterFixture()
+	changes, err := findChanges(local, remote, map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	Filter{Files: map[string]bool{"search.yaml": true}}.apply(changes, local, remote)
+
+	if len(changes.dpUpdate) != 1 || len(changes.dpCreate) != 0 || len(changes.esCreate) != 0 || len(changes.saCreate) != 0 || len(changes.saUpdate) != 0 {
+		t.Fatalf("expected only the search data product update, got %+v", changes)
+	}
+	if _, ok := changes.IdToFileName["dp-checkout"]; ok {
+		t.Fatalf("expected skipped changes not to be validated, got %v", changes.IdToFileName)
+	}
+}
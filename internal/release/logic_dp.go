@@ -53,6 +53,11 @@ type DataProductChangeSet struct {
 	imageCreate       []TriggerImageReference
 	IdToFileName      map[string]string
 	localEventSpecIds []string
+	// remote as found when computing the changes
+	remote            *console.DataProductsAndRelatedResources
+	localIdToFileName map[string]string
+	// files with changes left out by a filter
+	skippedFiles map[string]bool
 }
 
 func (cs DataProductChangeSet) isEmpty() bool {
@@ -575,7 +580,7 @@ func Purge(ctx context.Context, api DataProductPurger, dp map[string]map[string]
 	return nil
 }
 
-func FindChanges(cnx context.Context, client *console.ApiClient, dp map[string]map[string]any) (*DataProductChangeSet, error) {
+func FindChanges(cnx context.Context, client *console.ApiClient, dp map[string]map[string]any, filter Filter) (*DataProductChangeSet, error) {
 	localResolved, err := ReadLocalDataProducts(cnx, dp)
 	if err != nil {
 		return nil, err
@@ -592,6 +597,9 @@ func FindChanges(cnx context.Context, client *console.ApiClient, dp map[string]m
 	if err != nil {
 		return nil, err
 	}
+	changeSet.remote = remote
+	changeSet.localIdToFileName = localResolved.IdToFileName
+	filter.apply(changeSet, *localResolved, *remote)
 
 	return changeSet, err
 }
@@ -0,0 +1,126 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package release
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/snowplow/snowplow-cli/internal/console"
+)
+
+// RemapIds points the resource names of the source applications, data
+// products and event specifications in files at the matching resources of
+// target. Resources are matched by id, then by name, so resources created
+// in the target organization on their own are updated rather than
+// duplicated. Returns the remapped ids
+func RemapIds(files map[string]map[string]any, target console.DataProductsAndRelatedResources) map[string]string {
+	remapped := map[string]string{}
+
+	saIds := map[string]bool{}
+	saByName := map[string]string{}
+	for _, sa := range target.SourceApplication {
+		saIds[sa.Id] = true
+		saByName[sa.Name] = sa.Id
+	}
+	dpIds := map[string]bool{}
+	dpByName := map[string]string{}
+	for _, dp := range target.DataProducts {
+		dpIds[dp.Id] = true
+		dpByName[dp.Name] = dp.Id
+	}
+	esIds := map[string]bool{}
+	esByDpAndName := map[string]map[string]string{}
+	for _, es := range target.EventSpecs {
+		esIds[es.Id] = true
+		if esByDpAndName[es.DataProductId] == nil {
+			esByDpAndName[es.DataProductId] = map[string]string{}
+		}
+		esByDpAndName[es.DataProductId][es.Name] = es.Id
+	}
+
+	remap := func(r map[string]any, name any, ids map[string]bool, byName map[string]string) string {
+		id, _ := r["resourceName"].(string)
+		if ids[id] {
+			return id
+		}
+		n, _ := name.(string)
+		if targetId, ok := byName[n]; ok {
+			r["resourceName"] = targetId
+			remapped[id] = targetId
+			return targetId
+		}
+		return id
+	}
+
+	for _, r := range files {
+		data, _ := r["data"].(map[string]any)
+		if data == nil {
+			continue
+		}
+		switch r["resourceType"] {
+		case "source-application":
+			remap(r, data["name"], saIds, saByName)
+		case "data-product":
+			dpId := remap(r, data["name"], dpIds, dpByName)
+			eventSpecs, _ := data["eventSpecifications"].([]any)
+			for _, es := range eventSpecs {
+				if es, ok := es.(map[string]any); ok {
+					remap(es, es["name"], esIds, esByDpAndName[dpId])
+				}
+			}
+		}
+	}
+
+	for from, to := range remapped {
+		slog.Debug("promote", "msg", "matched by name", "source", from, "target", to)
+	}
+
+	return remapped
+}
+
+// MissingDataStructures lists the data structures referenced by the event
+// specifications and source applications the change set creates or updates
+// that are not deployed according to sdc
+func (cs *DataProductChangeSet) MissingDataStructures(sdc console.SchemaDeployChecker) ([]string, error) {
+	var uris []string
+	for _, sa := range slices.Concat(cs.saCreate, cs.saUpdate) {
+		for _, e := range slices.Concat(sa.Entities.Tracked, sa.Entities.Enriched) {
+			uris = append(uris, e.Source)
+		}
+	}
+	for _, es := range slices.Concat(cs.esCreate, cs.esUpdate) {
+		if es.Event != nil {
+			uris = append(uris, es.Event.Source)
+		}
+		for _, e := range slices.Concat(es.Entities.Tracked, es.Entities.Enriched) {
+			uris = append(uris, e.Source)
+		}
+	}
+	slices.Sort(uris)
+	uris = slices.Compact(uris)
+
+	var missing []string
+	for _, uri := range uris {
+		if uri == "" {
+			continue
+		}
+		found, _, err := sdc.IsDSDeployed(uri)
+		if err != nil {
+			return nil, fmt.Errorf("checking %s: %w", uri, err)
+		}
+		if !found {
+			missing = append(missing, uri)
+		}
+	}
+	return missing, nil
+}
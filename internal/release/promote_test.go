@@ -0,0 +1,111 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package release
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+func Test_RemapIds(t *testing.T) {
+	files := map[string]map[string]any{
+		"source-apps/web.yaml": {
+			"resourceType": "source-application",
+			"resourceName": "staging-sa",
+			"data":         map[string]any{"name": "web"},
+		},
+		"checkout.yaml": {
+			"resourceType": "data-product",
+			"resourceName": "staging-dp",
+			"data": map[string]any{
+				"name": "checkout",
+				"eventSpecifications": []any{
+					map[string]any{"resourceName": "staging-es-add", "name": "add to cart"},
+					map[string]any{"resourceName": "shared-es", "name": "remove from cart"},
+					map[string]any{"resourceName": "staging-es-new", "name": "checkout started"},
+				},
+			},
+		},
+		"signup.yaml": {
+			"resourceType": "data-product",
+			"resourceName": "staging-signup",
+			"data":         map[string]any{"name": "signup"},
+		},
+	}
+	target := console.DataProductsAndRelatedResources{
+		SourceApplication: []console.RemoteSourceApplication{{Id: "prod-sa", Name: "web"}},
+		DataProducts:      []console.RemoteDataProduct{{Id: "prod-dp", Name: "checkout"}},
+		EventSpecs: []console.RemoteEventSpec{
+			{Id: "prod-es-add", Name: "add to cart", DataProductId: "prod-dp"},
+			{Id: "shared-es", Name: "remove from cart", DataProductId: "prod-dp"},
+			{Id: "other-es", Name: "checkout started", DataProductId: "other-dp"},
+		},
+	}
+
+	remapped := RemapIds(files, target)
+
+	expected := map[string]string{"staging-sa": "prod-sa", "staging-dp": "prod-dp", "staging-es-add": "prod-es-add"}
+	if len(remapped) != len(expected) {
+		t.Fatalf("unexpected remapped ids %v", remapped)
+	}
+	for from, to := range expected {
+		if remapped[from] != to {
+			t.Fatalf("unexpected remapped ids %v", remapped)
+		}
+	}
+
+	if files["source-apps/web.yaml"]["resourceName"] != "prod-sa" || files["checkout.yaml"]["resourceName"] != "prod-dp" || files["signup.yaml"]["resourceName"] != "staging-signup" {
+		t.Fatalf("unexpected resource names %v", files)
+	}
+	var esIds []string
+	for _, es := range files["checkout.yaml"]["data"].(map[string]any)["eventSpecifications"].([]any) {
+		esIds = append(esIds, es.(map[string]any)["resourceName"].(string))
+	}
+	if !slices.Equal(esIds, []string{"prod-es-add", "shared-es", "staging-es-new"}) {
+		t.Fatalf("unexpected event spec resource names %v", esIds)
+	}
+}
+
+type deployedSchemas []string
+
+func (d deployedSchemas) IsDSDeployed(uri string) (bool, []string, error) {
+	return slices.Contains(d, uri), nil, nil
+}
+
+func (d deployedSchemas) DeprecatedMeta(uri string) (model.DataStructureMeta, bool) {
+	return model.DataStructureMeta{}, false
+}
+
+func Test_MissingDataStructures(t *testing.T) {
+	changes := DataProductChangeSet{
+		saCreate: []console.RemoteSourceApplication{{Entities: console.Entities{
+			Tracked: []console.Entity{{Source: "iglu:com.acme/user/jsonschema/1-0-0"}},
+		}}},
+		esCreate: []console.RemoteEventSpec{{
+			Event:    &console.EventWrapper{Event: console.Event{Source: "iglu:com.acme/add_to_cart/jsonschema/1-0-0"}},
+			Entities: console.Entities{Enriched: []console.Entity{{Source: "iglu:com.acme/product/jsonschema/1-0-0"}}},
+		}},
+		esUpdate: []console.RemoteEventSpec{{
+			Event: &console.EventWrapper{Event: console.Event{Source: "iglu:com.acme/add_to_cart/jsonschema/1-0-0"}},
+		}},
+	}
+
+	missing, err := changes.MissingDataStructures(deployedSchemas{"iglu:com.acme/user/jsonschema/1-0-0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(missing, []string{"iglu:com.acme/add_to_cart/jsonschema/1-0-0", "iglu:com.acme/product/jsonschema/1-0-0"}) {
+		t.Fatalf("unexpected missing data structures %v", missing)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package release
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/state"
+)
+
+type remoteRecords struct {
+	dataProducts        map[string]state.Resource
+	sourceApplications  map[string]state.Resource
+	eventSpecifications map[string]state.Resource
+}
+
+func recordsOf(remote console.DataProductsAndRelatedResources, syncedAt time.Time) (remoteRecords, error) {
+	r := remoteRecords{map[string]state.Resource{}, map[string]state.Resource{}, map[string]state.Resource{}}
+	for _, dp := range remote.DataProducts {
+		hash, err := state.Hash(dp)
+		if err != nil {
+			return r, err
+		}
+		r.dataProducts[dp.Id] = state.Resource{Name: dp.Name, Hash: hash, SyncedAt: syncedAt}
+	}
+	for _, sa := range remote.SourceApplication {
+		hash, err := state.Hash(sa)
+		if err != nil {
+			return r, err
+		}
+		r.sourceApplications[sa.Id] = state.Resource{Name: sa.Name, Hash: hash, SyncedAt: syncedAt}
+	}
+	for _, es := range remote.EventSpecs {
+		hash, err := state.Hash(es)
+		if err != nil {
+			return r, err
+		}
+		r.eventSpecifications[es.Id] = state.Resource{Name: es.Name, Version: strconv.Itoa(es.Version), Hash: hash, SyncedAt: syncedAt}
+	}
+	return r, nil
+}
+
+// WarnRemoteChanges warns about the resources the change set updates or
+// deletes that were changed in Console since the last sync
+func WarnRemoteChanges(changeSet *DataProductChangeSet, st *state.State) error {
+	if changeSet.remote == nil {
+		return nil
+	}
+	current, err := recordsOf(*changeSet.remote, time.Time{})
+	if err != nil {
+		return err
+	}
+
+	warn := func(kind string, id string, records map[string]state.Resource, remote map[string]state.Resource) {
+		if synced, changed := state.Changed(records, id, remote[id].Hash); changed {
+			slog.Warn("sync", "msg", kind+" was changed in Console since the last sync, the changes will be overwritten",
+				"name", remote[id].Name, "file", changeSet.IdToFileName[id], "last synced", synced.SyncedAt.Format(time.RFC3339))
+		}
+	}
+	for _, sa := range changeSet.saUpdate {
+		warn("source application", sa.Id, st.SourceApplications, current.sourceApplications)
+	}
+	for _, dp := range changeSet.dpUpdate {
+		warn("data product", dp.Id, st.DataProducts, current.dataProducts)
+	}
+	for _, es := range slices.Concat(changeSet.esUpdate, changeSet.esDelete) {
+		warn("event specification", es.Id, st.EventSpecifications, current.eventSpecifications)
+	}
+
+	return nil
+}
+
+// RecordState records the remote version of every local resource after a
+// sync
+func RecordState(cnx context.Context, client *console.ApiClient, changeSet *DataProductChangeSet, st *state.State) error {
+	remote, err := console.GetDataProductsAndRelatedResources(cnx, client)
+	if err != nil {
+		return err
+	}
+	return RecordRemote(*remote, changeSet.localIdToFileName, st)
+}
+
+// RecordRemote records the remote version of the resources found in
+// idToFileName
+func RecordRemote(remote console.DataProductsAndRelatedResources, idToFileName map[string]string, st *state.State) error {
+	current, err := recordsOf(remote, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	record := func(records map[string]state.Resource, remote map[string]state.Resource) {
+		for id, r := range remote {
+			f, ok := idToFileName[id]
+			if !ok {
+				continue
+			}
+			if rel, err := filepath.Rel(cwd, f); err == nil && filepath.IsAbs(f) {
+				f = rel
+			}
+			r.File = filepath.ToSlash(f)
+			records[id] = r
+		}
+	}
+	record(st.DataProducts, current.dataProducts)
+	record(st.SourceApplications, current.sourceApplications)
+	record(st.EventSpecifications, current.eventSpecifications)
+
+	return nil
+}
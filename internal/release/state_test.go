@@ -0,0 +1,59 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+package release
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/state"
+)
+
+func Test_RecordRemoteAndWarnRemoteChanges(t *testing.T) {
+	remote := console.DataProductsAndRelatedResources{
+		DataProducts: []console.RemoteDataProduct{{Id: "dp-id", Name: "checkout", Owner: "a@example.com"}},
+		EventSpecs:   []console.RemoteEventSpec{{Id: "es-id", Name: "add to cart", Version: 3, DataProductId: "dp-id"}},
+	}
+
+	st, err := state.Load(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordRemote(remote, map[string]string{"dp-id": "checkout.yaml", "es-id": "checkout.yaml"}, st); err != nil {
+		t.Fatal(err)
+	}
+	if st.DataProducts["dp-id"].File != "checkout.yaml" || st.EventSpecifications["es-id"].Version != "3" {
+		t.Fatalf("unexpected state %+v %+v", st.DataProducts, st.EventSpecifications)
+	}
+
+	var logOutput bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logOutput, nil)))
+	defer slog.SetDefault(defaultLogger)
+
+	edited := remote
+	edited.DataProducts = []console.RemoteDataProduct{{Id: "dp-id", Name: "checkout", Owner: "b@example.com"}}
+	changeSet := &DataProductChangeSet{
+		dpUpdate:     []console.RemoteDataProduct{{Id: "dp-id", Name: "checkout", Owner: "c@example.com"}},
+		esUpdate:     []console.RemoteEventSpec{{Id: "es-id", Name: "add item to cart"}},
+		IdToFileName: map[string]string{"dp-id": "checkout.yaml", "es-id": "checkout.yaml"},
+		remote:       &edited,
+	}
+
+	if err := WarnRemoteChanges(changeSet, st); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(logOutput.String(), "changed in Console since the last sync") != 1 || !strings.Contains(logOutput.String(), "data product") {
+		t.Fatalf("expected a single data product warning got %s", logOutput.String())
+	}
+}
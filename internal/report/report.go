@@ -0,0 +1,381 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package report
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type Format string
+
+const (
+	Sarif       Format = "sarif"
+	JUnit       Format = "junit"
+	CodeQuality Format = "gitlab-codequality"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case Sarif, JUnit, CodeQuality:
+		return Format(s), nil
+	}
+	return "", fmt.Errorf("unknown report format %s, expected one of %s|%s|%s", s, Sarif, JUnit, CodeQuality)
+}
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityNote    Severity = "note"
+)
+
+// Issue is a single validation message, Line is 0 when the position in
+// the file is unknown
+type Issue struct {
+	File     string
+	Line     int
+	Column   int
+	Severity Severity
+	Rule     string
+	Message  string
+}
+
+// Report of a validation run, Files lists every validated file so files
+// without issues are reported as passing where the format supports it
+type Report struct {
+	Tool   string
+	Files  []string
+	Issues []Issue
+}
+
+func (r Report) sortedIssues() []Issue {
+	issues := slices.Clone(r.Issues)
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		return issues[i].Line < issues[j].Line
+	})
+	return issues
+}
+
+func (r Report) files() []string {
+	files := slices.Clone(r.Files)
+	for _, i := range r.Issues {
+		if i.File != "" && !slices.Contains(files, i.File) {
+			files = append(files, i.File)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// DefaultFile is the file a report is written to when none is given,
+// following the names CI systems usually pick up
+func DefaultFile(format Format) string {
+	switch format {
+	case Sarif:
+		return "snowplow-cli.sarif"
+	case JUnit:
+		return "snowplow-cli-junit.xml"
+	case CodeQuality:
+		return "gl-code-quality-report.json"
+	}
+	return ""
+}
+
+// WriteFile writes the report to fileName, to DefaultFile when it is empty
+// and to stdout when it is -
+func WriteFile(fileName string, format Format, r Report) error {
+	if fileName == "-" {
+		return Write(os.Stdout, format, r)
+	}
+	if fileName == "" {
+		fileName = DefaultFile(format)
+	}
+
+	f, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+
+	if err := Write(f, format, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func Write(w io.Writer, format Format, r Report) error {
+	switch format {
+	case Sarif:
+		return writeSarif(w, r)
+	case JUnit:
+		return writeJUnit(w, r)
+	case CodeQuality:
+		return writeCodeQuality(w, r)
+	}
+	return fmt.Errorf("unknown report format %s", format)
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationUri string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	Id string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleId    string          `json:"ruleId,omitempty"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	Uri string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+func writeSarif(w io.Writer, r Report) error {
+	var rules []sarifRule
+	results := []sarifResult{}
+	for _, i := range r.sortedIssues() {
+		if i.Rule != "" && !slices.ContainsFunc(rules, func(sr sarifRule) bool { return sr.Id == i.Rule }) {
+			rules = append(rules, sarifRule{i.Rule})
+		}
+		result := sarifResult{RuleId: i.Rule, Level: string(i.Severity), Message: sarifMessage{i.Message}}
+		if i.File != "" {
+			loc := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{i.File}}
+			if i.Line > 0 {
+				loc.Region = &sarifRegion{i.Line, i.Column}
+			}
+			result.Locations = []sarifLocation{{loc}}
+		}
+		results = append(results, result)
+	}
+	sort.Slice(rules, func(a, b int) bool { return rules[a].Id < rules[b].Id })
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{sarifDriver{
+				Name:           r.Tool,
+				InformationUri: "https://github.com/snowplow/snowplow-cli",
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(log)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit reports every file as a test case failing when it has errors,
+// warnings and notes are kept in the test case output
+func writeJUnit(w io.Writer, r Report) error {
+	byFile := map[string][]Issue{}
+	for _, i := range r.sortedIssues() {
+		byFile[i.File] = append(byFile[i.File], i)
+	}
+
+	files := r.files()
+	if _, ok := byFile[""]; ok {
+		files = append([]string{""}, files...)
+	}
+
+	suite := junitTestSuite{Name: r.Tool}
+	for _, f := range files {
+		name := f
+		if name == "" {
+			name = r.Tool
+		}
+		tc := junitTestCase{Name: name, Classname: r.Tool, File: f}
+		var errs, other []string
+		for _, i := range byFile[f] {
+			line := issueLine(i)
+			if i.Severity == SeverityError {
+				errs = append(errs, line)
+			} else {
+				other = append(other, line)
+			}
+		}
+		if len(errs) > 0 {
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d validation errors", len(errs)),
+				Type:    "error",
+				Text:    strings.Join(errs, "\n"),
+			}
+			suite.Failures++
+		}
+		tc.SystemOut = strings.Join(other, "\n")
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Tests = len(suite.Cases)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(junitTestSuites{Name: r.Tool, Tests: suite.Tests, Failures: suite.Failures, Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func issueLine(i Issue) string {
+	var b strings.Builder
+	if i.File != "" {
+		b.WriteString(i.File)
+		if i.Line > 0 {
+			fmt.Fprintf(&b, ":%d:%d", i.Line, i.Column)
+		}
+		b.WriteString(": ")
+	}
+	b.WriteString(string(i.Severity))
+	if i.Rule != "" {
+		fmt.Fprintf(&b, " [%s]", i.Rule)
+	}
+	b.WriteString(" " + i.Message)
+	return b.String()
+}
+
+type codeQualityIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    codeQualityLocation `json:"location"`
+}
+
+type codeQualityLocation struct {
+	Path  string           `json:"path"`
+	Lines codeQualityLines `json:"lines"`
+}
+
+type codeQualityLines struct {
+	Begin int `json:"begin"`
+}
+
+func writeCodeQuality(w io.Writer, r Report) error {
+	issues := []codeQualityIssue{}
+	seen := map[string]int{}
+	for _, i := range r.sortedIssues() {
+		severity := "info"
+		switch i.Severity {
+		case SeverityError:
+			severity = "major"
+		case SeverityWarning:
+			severity = "minor"
+		}
+		checkName := i.Rule
+		if checkName == "" {
+			checkName = "validation"
+		}
+		line := max(i.Line, 1)
+
+		// fingerprints ignore the line so moving an issue does not make it
+		// new, identical issues in a file are told apart by occurrence
+		key := fmt.Sprintf("%s:%s:%s", i.File, checkName, i.Message)
+		seen[key]++
+		fingerprint := fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf("%s:%d", key, seen[key]))))
+
+		issues = append(issues, codeQualityIssue{
+			Description: i.Message,
+			CheckName:   checkName,
+			Fingerprint: fingerprint,
+			Severity:    severity,
+			Location:    codeQualityLocation{Path: i.File, Lines: codeQualityLines{Begin: line}},
+		})
+	}
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(issues)
+}
@@ -0,0 +1,131 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testReport = Report{
+	Tool:  "snowplow-cli dp validate",
+	Files: []string{"data-products/checkout.yaml", "data-products/clean.yaml"},
+	Issues: []Issue{
+		{File: "data-products/checkout.yaml", Line: 12, Column: 7, Severity: SeverityError, Rule: "dp-owner-required", Message: "/data/owner: owner is required"},
+		{File: "data-products/checkout.yaml", Severity: SeverityWarning, Message: "no triggers"},
+		{File: "source-apps/web.yaml", Line: 3, Column: 3, Severity: SeverityNote, Message: "info"},
+	},
+}
+
+func Test_Sarif(t *testing.T) {
+	var b bytes.Buffer
+	if err := Write(&b, Sarif, testReport); err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(b.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Results) != 3 {
+		t.Fatalf("unexpected sarif\n%s", b.String())
+	}
+	first := log.Runs[0].Results[1]
+	if first.RuleId != "dp-owner-required" || first.Level != "error" {
+		t.Fatalf("unexpected result %#v", first)
+	}
+	region := first.Locations[0].PhysicalLocation.Region
+	if region == nil || region.StartLine != 12 || region.StartColumn != 7 {
+		t.Fatalf("unexpected region %#v", region)
+	}
+	if log.Runs[0].Results[0].Locations[0].PhysicalLocation.Region != nil {
+		t.Fatal("expected no region without a line")
+	}
+	if len(log.Runs[0].Tool.Driver.Rules) != 1 {
+		t.Fatalf("unexpected rules %#v", log.Runs[0].Tool.Driver.Rules)
+	}
+}
+
+func Test_JUnit(t *testing.T) {
+	var b bytes.Buffer
+	if err := Write(&b, JUnit, testReport); err != nil {
+		t.Fatal(err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(b.Bytes(), &suites); err != nil {
+		t.Fatal(err)
+	}
+
+	if suites.Tests != 3 || suites.Failures != 1 {
+		t.Fatalf("unexpected junit\n%s", b.String())
+	}
+	cases := suites.Suites[0].Cases
+	if cases[0].Name != "data-products/checkout.yaml" || cases[0].Failure == nil {
+		t.Fatalf("expected a failing checkout test case %#v", cases[0])
+	}
+	if !strings.Contains(cases[0].Failure.Text, "data-products/checkout.yaml:12:7: error [dp-owner-required] /data/owner: owner is required") {
+		t.Fatalf("unexpected failure %s", cases[0].Failure.Text)
+	}
+	if cases[1].Name != "data-products/clean.yaml" || cases[1].Failure != nil {
+		t.Fatalf("expected a passing clean test case %#v", cases[1])
+	}
+}
+
+func Test_CodeQuality(t *testing.T) {
+	var b bytes.Buffer
+	if err := Write(&b, CodeQuality, testReport); err != nil {
+		t.Fatal(err)
+	}
+
+	var issues []codeQualityIssue
+	if err := json.Unmarshal(b.Bytes(), &issues); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(issues) != 3 {
+		t.Fatalf("unexpected code quality report\n%s", b.String())
+	}
+	if issues[0].Severity != "minor" || issues[0].Location.Lines.Begin != 1 || issues[0].CheckName != "validation" {
+		t.Fatalf("unexpected issue %#v", issues[0])
+	}
+	if issues[1].Severity != "major" || issues[1].Location.Lines.Begin != 12 {
+		t.Fatalf("unexpected issue %#v", issues[1])
+	}
+	if issues[0].Fingerprint == issues[1].Fingerprint {
+		t.Fatal("expected distinct fingerprints")
+	}
+}
+
+func Test_WriteFileDefault(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	if err := WriteFile("", CodeQuality, testReport); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "gl-code-quality-report.json")); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// Dir keeps what the CLI knows of the last sync with Console, relative to
+// the working directory
+const Dir = ".snowplow"
+
+// BasePath is the file holding the base of a resource, see SaveBase
+func BasePath(dir string, resourceName string) string {
+	return filepath.Join(dir, "base", url.PathEscape(resourceName)+".json")
+}
+
+// SaveBase records a resource as it was when last synced with Console, the
+// common ancestor of three-way merges
+func SaveBase(dir string, resourceName string, resource any) error {
+	b, err := json.MarshalIndent(resource, "", "  ")
+	if err != nil {
+		return err
+	}
+	fileName := BasePath(dir, resourceName)
+	if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(fileName, b, 0644); err != nil {
+		return fmt.Errorf("failed to record merge base %s: %w", fileName, err)
+	}
+	return nil
+}
+
+// LoadBase reads the resource recorded by SaveBase, it reports false when
+// the resource was never synced
+func LoadBase(dir string, resourceName string) (any, bool, error) {
+	b, err := os.ReadFile(BasePath(dir, resourceName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var resource any
+	if err := json.Unmarshal(b, &resource); err != nil {
+		return nil, false, fmt.Errorf("failed to read merge base of %s: %w", resourceName, err)
+	}
+	return resource, true, nil
+}
@@ -0,0 +1,37 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package state
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Base(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, ok, err := LoadBase(dir, "dp-id"); err != nil || ok {
+		t.Fatalf("expected no base got %v %v", ok, err)
+	}
+
+	resource := map[string]any{"resourceName": "dp-id", "data": map[string]any{"name": "checkout"}}
+	if err := SaveBase(dir, "dp-id", resource); err != nil {
+		t.Fatal(err)
+	}
+
+	base, ok, err := LoadBase(dir, "dp-id")
+	if err != nil || !ok {
+		t.Fatalf("expected a base got %v %v", ok, err)
+	}
+	if !reflect.DeepEqual(base, any(resource)) {
+		t.Fatalf("unexpected base %v", base)
+	}
+}
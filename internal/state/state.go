@@ -0,0 +1,111 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+// Package state keeps what the CLI knows of the last sync with Console under
+// Dir, in two stores:
+//
+//   - state.json, see State, holds a hash and version per remote resource. It
+//     is loaded and saved whole by publish, sync and download to warn about
+//     remote changes, so it only holds what that comparison needs.
+//   - base/<resource name>.json, see SaveBase, holds the full body of each
+//     resource as last synced, the common ancestor of three-way merges. Bodies
+//     are read one at a time for the file being merged and written per
+//     resource, so they are kept out of state.json rather than rewriting every
+//     body on each save.
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const StateFile = "state.json"
+
+// State records the remote resources as of the last successful publish,
+// sync or download, keyed by resource id. Data structures are keyed by
+// environment and vendor/name/format, see DataStructureKey
+type State struct {
+	DataStructures      map[string]Resource `json:"dataStructures"`
+	DataProducts        map[string]Resource `json:"dataProducts"`
+	SourceApplications  map[string]Resource `json:"sourceApplications"`
+	EventSpecifications map[string]Resource `json:"eventSpecifications"`
+}
+
+type Resource struct {
+	Name     string    `json:"name,omitempty"`
+	File     string    `json:"file,omitempty"`
+	Version  string    `json:"version,omitempty"`
+	Hash     string    `json:"hash"`
+	SyncedAt time.Time `json:"syncedAt"`
+}
+
+func DataStructureKey(env string, vendor string, name string, format string) string {
+	return fmt.Sprintf("%s:%s/%s/%s", env, vendor, name, format)
+}
+
+// Load reads the state file of dir, the state is empty when there is none
+func Load(dir string) (*State, error) {
+	s := &State{}
+	b, err := os.ReadFile(filepath.Join(dir, StateFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(b, s); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filepath.Join(dir, StateFile), err)
+		}
+	}
+
+	for _, m := range []*map[string]Resource{&s.DataStructures, &s.DataProducts, &s.SourceApplications, &s.EventSpecifications} {
+		if *m == nil {
+			*m = map[string]Resource{}
+		}
+	}
+
+	return s, nil
+}
+
+func (s *State) Save(dir string) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, StateFile), append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to record sync state: %w", err)
+	}
+	return nil
+}
+
+// Changed reports the recorded resource when it differs from the remote
+// hash, resources never synced are not considered changed
+func Changed(records map[string]Resource, key string, hash string) (Resource, bool) {
+	r, ok := records[key]
+	if !ok || r.Hash == hash {
+		return Resource{}, false
+	}
+	return r, true
+}
+
+// Hash fingerprints a resource by its json encoding
+func Hash(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b)), nil
+}
@@ -0,0 +1,52 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package state
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_State(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.DataProducts) != 0 {
+		t.Fatalf("expected an empty state got %+v", s)
+	}
+
+	syncedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	s.DataProducts["dp-id"] = Resource{Name: "checkout", File: "data-products/checkout.yaml", Hash: "abc", SyncedAt: syncedAt}
+	if err := s.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.DataProducts["dp-id"] != s.DataProducts["dp-id"] {
+		t.Fatalf("unexpected state %+v", loaded.DataProducts)
+	}
+
+	if _, changed := Changed(loaded.DataProducts, "dp-id", "abc"); changed {
+		t.Fatal("expected the same hash not to be a change")
+	}
+	if r, changed := Changed(loaded.DataProducts, "dp-id", "def"); !changed || r.Hash != "abc" {
+		t.Fatal("expected a different hash to be a change")
+	}
+	if _, changed := Changed(loaded.DataProducts, "other", "def"); changed {
+		t.Fatal("expected resources never synced not to be a change")
+	}
+}
@@ -14,6 +14,7 @@ const DataStructuresFolder = "data-structures"
 const DataProductsFolder = "data-products"
 const SourceAppsFolder = "source-apps"
 const ImagesFolder = "images"
+const OverlaysFolder = "overlays"
 
 const DataStructureResourceType = "data-structure"
 const DataProductResourceType = "data-product"
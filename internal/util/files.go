@@ -11,15 +11,20 @@ OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
 package util
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"sort"
+	"strings"
 
+	"github.com/snowplow/snowplow-cli/internal/amend"
 	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/state"
 
 	"gopkg.in/yaml.v3"
 )
@@ -30,6 +35,44 @@ type Files struct {
 	SourceAppsLocation     string
 	ImagesLocation         string
 	ExtentionPreference    string
+	// VersionedDataStructures writes every version of a data structure
+	// side by side as vendor/name/version files
+	VersionedDataStructures bool
+	// StateDir records the written data products and source applications
+	// as the base of later merges, nothing is recorded when empty
+	StateDir string
+	// Merge, when set, merges remote data products and source applications
+	// into existing files three-way, against the base recorded in StateDir
+	Merge *MergeReport
+	// TemplateVars render the templated resource files, to find the
+	// resources they generate
+	TemplateVars map[string]any
+}
+
+// MergeReport collects the fields changed differently in Console and in
+// the files, the local value is kept for them
+type MergeReport struct {
+	Conflicts []FileConflict
+}
+
+type FileConflict struct {
+	File           string `yaml:"file" json:"file"`
+	amend.Conflict `yaml:",inline"`
+}
+
+// IsVersionedLayout reports whether a data structure file follows the
+// vendor/name/version layout, where several versions of the same data
+// structure may live next to each other
+func IsVersionedLayout(fileName string, self model.DataStructureSelf) bool {
+	base := filepath.Base(fileName)
+	version := strings.TrimSuffix(base, filepath.Ext(base))
+	if version != self.Version {
+		return false
+	}
+	// directories may carry a numeric suffix, see createUniqueNames
+	dir := filepath.Base(filepath.Dir(fileName))
+	name := regexp.QuoteMeta(ResourceNameToFileName(self.Name))
+	return dir == self.Name || regexp.MustCompile(`^`+name+`(-\d+)?$`).MatchString(dir)
 }
 
 func (f Files) CreateDataStructures(dss []model.DataStructure, isPlain bool) error {
@@ -53,6 +96,8 @@ func (f Files) CreateDataStructures(dss []model.DataStructure, isPlain bool) err
 		}
 	}
 
+	existing := indexResourceFiles(f.DataStructuresLocation, f.TemplateVars)
+
 	uniqueVendors := createUniqueNames(vendorIds)
 	vendorMapping := make(map[string]string)
 	for _, uv := range uniqueVendors {
@@ -63,30 +108,59 @@ func (f Files) CreateDataStructures(dss []model.DataStructure, isPlain bool) err
 		uniqueVendorName := vendorMapping[originalVendor]
 		vendorPath := filepath.Join(f.DataStructuresLocation, uniqueVendorName)
 
-		err := os.MkdirAll(vendorPath, os.ModePerm)
-		if err != nil {
-			return err
-		}
-
 		var schemaIds []idFileName
-		idToDs := map[string]model.DataStructure{}
+		idToDs := map[string][]model.DataStructure{}
 		for _, ds := range schemas {
 			data, _ := ds.ParseData()
+			if fileName, ok := existing.dataStructure(data.Self, f.VersionedDataStructures); ok {
+				if err := MergeResourceIntoFile(ds, fileName, isPlain, DataStructureResourceType); err != nil {
+					return err
+				}
+				continue
+			}
 			id := fmt.Sprintf("%s/%s", originalVendor, data.Self.Name)
-			schemaIds = append(schemaIds, idFileName{
-				Id:       id,
-				FileName: data.Self.Name,
-			})
-			idToDs[id] = ds
+			if len(idToDs[id]) == 0 {
+				schemaIds = append(schemaIds, idFileName{
+					Id:       id,
+					FileName: data.Self.Name,
+				})
+			}
+			idToDs[id] = append(idToDs[id], ds)
+		}
+
+		if len(schemaIds) == 0 {
+			continue
+		}
+
+		err := os.MkdirAll(vendorPath, os.ModePerm)
+		if err != nil {
+			return err
 		}
 
 		uniqueSchemas := createUniqueNames(schemaIds)
 
 		for _, schemaFile := range uniqueSchemas {
-			_, err = WriteResourceToFile(idToDs[schemaFile.Id], vendorPath, schemaFile.FileName, f.ExtentionPreference, isPlain, DataStructureResourceType)
-			if err != nil {
+			if !f.VersionedDataStructures {
+				for _, ds := range idToDs[schemaFile.Id] {
+					_, err = WriteResourceToFile(ds, vendorPath, schemaFile.FileName, f.ExtentionPreference, isPlain, DataStructureResourceType)
+					if err != nil {
+						return err
+					}
+				}
+				continue
+			}
+
+			schemaPath := filepath.Join(vendorPath, schemaFile.FileName)
+			if err := os.MkdirAll(schemaPath, os.ModePerm); err != nil {
 				return err
 			}
+			for _, ds := range idToDs[schemaFile.Id] {
+				data, _ := ds.ParseData()
+				_, err = WriteResourceToFile(ds, schemaPath, data.Self.Version, f.ExtentionPreference, isPlain, DataStructureResourceType)
+				if err != nil {
+					return err
+				}
+			}
 		}
 	}
 
@@ -130,23 +204,39 @@ func (f Files) CreateSourceApps(sas []model.CliResource[model.SourceAppData], is
 		return nil, err
 	}
 
+	existing := indexResourceFiles(f.DataProductsLocation, f.TemplateVars)
+	var res = make(map[string]model.CliResource[model.SourceAppData])
+
 	var idToFileName []idFileName
 	idToSa := make(map[string]model.CliResource[model.SourceAppData])
 	for _, sa := range sas {
+		if fileName, ok := existing.templated[sa.ResourceName]; ok {
+			slog.Debug("download", "msg", "generated by a template, not written", "resource name", sa.ResourceName, "file", fileName)
+			res[fileName] = sa
+			continue
+		}
+		if fileName, ok := existing.byResourceName[sa.ResourceName]; ok {
+			if err := f.updateResource(sa, fileName, isPlain, sa.ResourceType, sa.ResourceName); err != nil {
+				return nil, err
+			}
+			res[fileName] = sa
+			continue
+		}
 		idToSa[sa.ResourceName] = sa
 		idToFileName = append(idToFileName, idFileName{Id: sa.ResourceName, FileName: sa.Data.Name})
 	}
 
 	uniqueNames := createUniqueNames(idToFileName)
 
-	var res = make(map[string]model.CliResource[model.SourceAppData])
-
 	for _, idToName := range uniqueNames {
 		sa := idToSa[idToName.Id]
 		abs, err := WriteResourceToFile(sa, sourceAppsPath, idToName.FileName, f.ExtentionPreference, isPlain, sa.ResourceType)
 		if err != nil {
 			return nil, err
 		}
+		if err := f.recordBase(sa, abs, sa.ResourceName); err != nil {
+			return nil, err
+		}
 		res[abs] = sa
 	}
 
@@ -160,23 +250,41 @@ func (f Files) CreateDataProducts(dps []model.CliResource[model.DataProductCanon
 		return nil, err
 	}
 
+	existing := indexResourceFiles(f.DataProductsLocation, f.TemplateVars)
+	var res = make(map[string]model.CliResource[model.DataProductCanonicalData])
+
 	var idToFileName []idFileName
 	idToDp := make(map[string]model.CliResource[model.DataProductCanonicalData])
 	for _, dp := range dps {
+		if fileName, ok := existing.templated[dp.ResourceName]; ok {
+			slog.Debug("download", "msg", "generated by a template, not written", "resource name", dp.ResourceName, "file", fileName)
+			res[fileName] = dp
+			continue
+		}
+		if fileName, ok := existing.byResourceName[dp.ResourceName]; ok {
+			local := dp
+			local.Data = rebaseRefs(dp.Data, f.DataProductsLocation, filepath.Dir(fileName))
+			if err := f.updateResource(local, fileName, isPlain, dp.ResourceType, dp.ResourceName); err != nil {
+				return nil, err
+			}
+			res[fileName] = dp
+			continue
+		}
 		idToDp[dp.ResourceName] = dp
 		idToFileName = append(idToFileName, idFileName{Id: dp.ResourceName, FileName: dp.Data.Name})
 	}
 
 	uniqueNames := createUniqueNames(idToFileName)
 
-	var res = make(map[string]model.CliResource[model.DataProductCanonicalData])
-
 	for _, idToName := range uniqueNames {
 		dp := idToDp[idToName.Id]
 		abs, err := WriteResourceToFile(dp, f.DataProductsLocation, idToName.FileName, f.ExtentionPreference, isPlain, dp.ResourceType)
 		if err != nil {
 			return nil, err
 		}
+		if err := f.recordBase(dp, abs, dp.ResourceName); err != nil {
+			return nil, err
+		}
 		res[abs] = dp
 	}
 
@@ -235,23 +343,24 @@ func (f Files) WriteImage(name string, dir string, image *model.Image) (string,
 	return relativePath, err
 }
 
-func WriteSerializableToFile(body any, dir string, name string, ext string, yamlPrefix string) (string, error) {
-	var bytes []byte
-	var err error
-
+func marshalSerializable(body any, ext string, yamlPrefix string) ([]byte, error) {
 	if ext == "yaml" {
-		bytes, err = yaml.Marshal(body)
+		bytes, err := yaml.Marshal(body)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		if yamlPrefix != "" {
 			bytes = append([]byte(yamlPrefix+"\n"), bytes...)
 		}
-	} else {
-		bytes, err = json.MarshalIndent(body, "", "  ")
-		if err != nil {
-			return "", err
-		}
+		return bytes, nil
+	}
+	return json.MarshalIndent(body, "", "  ")
+}
+
+func WriteSerializableToFile(body any, dir string, name string, ext string, yamlPrefix string) (string, error) {
+	bytes, err := marshalSerializable(body, ext, yamlPrefix)
+	if err != nil {
+		return "", err
 	}
 
 	filePath := filepath.Join(dir, fmt.Sprintf("%s.%s", name, ext))
@@ -277,6 +386,290 @@ func WriteResourceToFile(body any, dir string, name string, ext string, isPlain
 	}
 }
 
+// MergeResourceIntoFile updates an existing resource file with the fields
+// of body that changed, keeping its comments, key order and location. An
+// unchanged file is not written at all
+func MergeResourceIntoFile(body any, fileName string, isPlain bool, resourceType string) error {
+	ext := fileFormat(fileName)
+
+	prefix := ""
+	if !isPlain {
+		var err error
+		prefix, err = getLspComment(resourceType)
+		if err != nil {
+			return err
+		}
+	}
+
+	fresh, err := marshalSerializable(body, ext, prefix)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+
+	merged, err := amend.MergeResource(file, fresh, fileName)
+	if err != nil {
+		slog.Warn("download", "msg", "could not merge changes into the existing file, rewriting it without its comments and layout", "file", fileName, "error", err)
+		merged = fresh
+	}
+
+	if bytes.Equal(merged, file) {
+		slog.Debug("unchanged", "file", fileName)
+		return nil
+	}
+
+	if err := os.WriteFile(fileName, merged, 0644); err != nil {
+		return err
+	}
+
+	slog.Debug("updated", "file", fileName)
+
+	return nil
+}
+
+func fileFormat(fileName string) string {
+	if e := filepath.Ext(fileName); e == ".yaml" || e == ".yml" {
+		return "yaml"
+	}
+	return "json"
+}
+
+// updateResource writes a remote resource into its existing file, merged
+// three-way when f.Merge is set, and records it as the new base
+func (f Files) updateResource(body any, fileName string, isPlain bool, resourceType string, resourceName string) error {
+	if f.Merge == nil {
+		if err := MergeResourceIntoFile(body, fileName, isPlain, resourceType); err != nil {
+			return err
+		}
+		return f.recordBase(body, fileName, resourceName)
+	}
+
+	fresh, err := marshalSerializable(body, fileFormat(fileName), "")
+	if err != nil {
+		return err
+	}
+	remote, err := amend.Normalize(fresh)
+	if err != nil {
+		return err
+	}
+	file, err := os.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+	local, err := amend.Normalize(file)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", fileName, err)
+	}
+	base, _, err := state.LoadBase(f.StateDir, resourceName)
+	if err != nil {
+		return err
+	}
+
+	merged, conflicts := amend.Merge3(base, local, remote)
+	for _, c := range conflicts {
+		f.Merge.Conflicts = append(f.Merge.Conflicts, FileConflict{fileName, c})
+	}
+
+	mergedFile, err := marshalSerializable(merged, fileFormat(fileName), "")
+	if err != nil {
+		return err
+	}
+	output, err := amend.MergeResource(file, mergedFile, fileName)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(output, file) {
+		if err := os.WriteFile(fileName, output, 0644); err != nil {
+			return err
+		}
+		slog.Debug("merged", "file", fileName, "conflicts", len(conflicts))
+	}
+
+	// a conflicting resource keeps its base so the conflict shows again
+	if len(conflicts) > 0 {
+		return nil
+	}
+	return f.recordBase(body, fileName, resourceName)
+}
+
+// recordBase keeps body as written to fileName as the base of later merges
+func (f Files) recordBase(body any, fileName string, resourceName string) error {
+	if f.StateDir == "" {
+		return nil
+	}
+	b, err := marshalSerializable(body, fileFormat(fileName), "")
+	if err != nil {
+		return err
+	}
+	resource, err := amend.Normalize(b)
+	if err != nil {
+		return err
+	}
+	return state.SaveBase(f.StateDir, resourceName, resource)
+}
+
+// RecordFileBase keeps a resource file as written, before overlays are
+// applied, as the base of later merges, the same form 'pull' compares it
+// in. Templates are skipped as 'pull' does not write them
+func RecordFileBase(stateDir string, fileName string, resourceName string) error {
+	if strings.HasSuffix(fileName, TemplateExt) {
+		return nil
+	}
+	file, err := os.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+	resource, err := amend.Normalize(file)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", fileName, err)
+	}
+	return state.SaveBase(stateDir, resourceName, resource)
+}
+
+// rebaseRefs makes the references of a data product, relative to the
+// directory from, relative to the directory to where its file lives
+func rebaseRefs(dp model.DataProductCanonicalData, from string, to string) model.DataProductCanonicalData {
+	absFrom, errFrom := filepath.Abs(from)
+	absTo, errTo := filepath.Abs(to)
+	if errFrom != nil || errTo != nil || absFrom == absTo {
+		return dp
+	}
+
+	rebase := func(ref string) string {
+		if ref == "" || filepath.IsAbs(ref) {
+			return ref
+		}
+		rel, err := filepath.Rel(absTo, filepath.Join(absFrom, ref))
+		if err != nil {
+			return ref
+		}
+		rel = filepath.ToSlash(rel)
+		if strings.HasPrefix(rel, "../") {
+			return rel
+		}
+		return "./" + rel
+	}
+	rebaseAll := func(refs []model.Ref) []model.Ref {
+		var result []model.Ref
+		for _, r := range refs {
+			result = append(result, model.Ref{Ref: rebase(r.Ref)})
+		}
+		return result
+	}
+
+	dp.SourceApplications = rebaseAll(dp.SourceApplications)
+	ess := make([]model.EventSpecCanonical, len(dp.EventSpecifications))
+	for i, es := range dp.EventSpecifications {
+		es.ExcludedSourceApplications = rebaseAll(es.ExcludedSourceApplications)
+		triggers := slices.Clone(es.Triggers)
+		for j, t := range triggers {
+			if t.Image != nil {
+				triggers[j].Image = &model.Ref{Ref: rebase(t.Image.Ref)}
+			}
+		}
+		es.Triggers = triggers
+		ess[i] = es
+	}
+	dp.EventSpecifications = ess
+
+	return dp
+}
+
+// resourceFiles locates the resource files already present in a directory
+// tree, whatever their names and layout
+type resourceFiles struct {
+	byResourceName map[string]string
+	// template files by the resource names they generate, these are not
+	// written to
+	templated map[string]string
+	// data structure files by vendor/name
+	dataStructures map[string][]dataStructureFile
+}
+
+type dataStructureFile struct {
+	fileName string
+	version  string
+}
+
+func indexResourceFiles(dir string, vars map[string]any) resourceFiles {
+	result := resourceFiles{map[string]string{}, map[string]string{}, map[string][]dataStructureFile{}}
+
+	_ = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if filepath.Ext(path) == TemplateExt {
+			r, err := dataFromFileName(path, vars, nil)
+			if err != nil {
+				slog.Warn("download", "msg", "could not render template", "file", path, "error", err)
+				return nil
+			}
+			if name, ok := r["resourceName"].(string); ok {
+				result.templated[name] = path
+			}
+			return nil
+		}
+		if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" && ext != ".json" {
+			return nil
+		}
+
+		file, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		var resource struct {
+			ResourceType string `yaml:"resourceType"`
+			ResourceName string `yaml:"resourceName"`
+			Data         struct {
+				Self model.DataStructureSelf `yaml:"self"`
+			} `yaml:"data"`
+		}
+		if err := yaml.Unmarshal(file, &resource); err != nil {
+			return nil
+		}
+
+		if resource.ResourceType == DataStructureResourceType {
+			self := resource.Data.Self
+			id := fmt.Sprintf("%s/%s", self.Vendor, self.Name)
+			result.dataStructures[id] = append(result.dataStructures[id], dataStructureFile{path, self.Version})
+		} else if resource.ResourceName != "" {
+			result.byResourceName[resource.ResourceName] = path
+		}
+
+		return nil
+	})
+
+	for _, files := range result.dataStructures {
+		sort.Slice(files, func(i, j int) bool { return files[i].fileName < files[j].fileName })
+	}
+
+	return result
+}
+
+// dataStructure finds the file of a data structure, the file of the same
+// version when versioned otherwise the single file kept for it
+func (r resourceFiles) dataStructure(self model.DataStructureSelf, versioned bool) (string, bool) {
+	files := r.dataStructures[fmt.Sprintf("%s/%s", self.Vendor, self.Name)]
+	for _, f := range files {
+		if f.version == self.Version {
+			return f.fileName, true
+		}
+	}
+	if versioned {
+		return "", false
+	}
+	for _, f := range files {
+		if !IsVersionedLayout(f.fileName, model.DataStructureSelf{Vendor: self.Vendor, Name: self.Name, Version: f.version}) {
+			return f.fileName, true
+		}
+	}
+	return "", false
+}
+
 func getLspComment(resourceType string) (string, error) {
 	if slices.Contains([]string{DataStructureResourceType, DataProductResourceType, SourceApplicationResourceType}, resourceType) {
 		template := "# yaml-language-server: $schema=%s%s.json\n"
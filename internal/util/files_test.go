@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	. "github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/state"
 )
 
 func TestCreatesDataStructuresFolderWithFiles(t *testing.T) {
@@ -487,3 +488,280 @@ func TestCreateSourceAppsPathHandling(t *testing.T) {
 		}
 	})
 }
+
+func TestCreatesDataStructuresVersioned(t *testing.T) {
+	ds := func(version string) DataStructure {
+		return DataStructure{
+			Meta: DataStructureMeta{SchemaType: "entity"},
+			Data: map[string]any{
+				"self": map[string]any{
+					"vendor":  "com.acme",
+					"name":    "checkout",
+					"format":  "jsonschema",
+					"version": version,
+				},
+				"schema": "string"},
+		}
+	}
+
+	dir := t.TempDir()
+	files := Files{DataStructuresLocation: dir, ExtentionPreference: "yaml", VersionedDataStructures: true}
+	err := files.CreateDataStructures([]DataStructure{ds("1-0-0"), ds("1-0-1")}, false)
+	if err != nil {
+		t.Fatalf("CreateDataStructures failed: %s", err)
+	}
+
+	for _, v := range []string{"1-0-0", "1-0-1"} {
+		filePath := filepath.Join(dir, "com.acme", "checkout", v+".yaml")
+		if _, err := os.Stat(filePath); os.IsNotExist(err) {
+			t.Fatalf("%s does not exists", filePath)
+		}
+		data, _ := ds(v).ParseData()
+		if !IsVersionedLayout(filePath, data.Self) {
+			t.Fatalf("%s not recognised as versioned layout", filePath)
+		}
+	}
+}
+
+func TestCreateDataStructuresKeepsExistingFiles(t *testing.T) {
+	ds := func(description string) DataStructure {
+		return DataStructure{
+			ApiVersion:   "v1",
+			ResourceType: "data-structure",
+			Meta:         DataStructureMeta{SchemaType: "event", CustomData: map[string]string{}},
+			Data: map[string]any{
+				"self": map[string]any{
+					"vendor":  "com.acme",
+					"name":    "checkout",
+					"format":  "jsonschema",
+					"version": "1-0-0",
+				},
+				"description": description,
+			},
+		}
+	}
+
+	dir := t.TempDir()
+	files := Files{DataStructuresLocation: dir, ExtentionPreference: "yaml"}
+	if err := files.CreateDataStructures([]DataStructure{ds("checkout")}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// move the file to a custom layout and comment it
+	original := filepath.Join(dir, "com.acme", "checkout.yaml")
+	moved := filepath.Join(dir, "events", "checkout.yaml")
+	content, err := os.ReadFile(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content = append(content, []byte("# reviewed by the data team\n")...)
+	if err := os.MkdirAll(filepath.Dir(moved), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(moved, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(dir, "com.acme")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := files.CreateDataStructures([]DataStructure{ds("checkout")}, false); err != nil {
+		t.Fatal(err)
+	}
+	unchanged, err := os.ReadFile(moved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(unchanged) != string(content) {
+		t.Fatalf("expected an unchanged file to be left as is got\n%s", unchanged)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "com.acme")); !os.IsNotExist(err) {
+		t.Fatal("expected no file to be created for an existing data structure")
+	}
+
+	if err := files.CreateDataStructures([]DataStructure{ds("checkout flow")}, false); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := os.ReadFile(moved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(updated), "description: checkout flow") || !strings.Contains(string(updated), "# reviewed by the data team") {
+		t.Fatalf("expected the description to change and the comment to stay got\n%s", updated)
+	}
+}
+
+func TestCreateDataProductsRebasesRefs(t *testing.T) {
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "checkout", "checkout.yaml")
+	if err := os.MkdirAll(filepath.Dir(nested), 0755); err != nil {
+		t.Fatal(err)
+	}
+	existing := `apiVersion: v1
+resourceType: data-product
+resourceName: dp-id
+data:
+    name: checkout # the checkout funnel
+    sourceApplications:
+        - $ref: ../source-apps/web.yaml
+    eventSpecifications: []
+`
+	if err := os.WriteFile(nested, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files := Files{DataProductsLocation: dir, ExtentionPreference: "yaml"}
+	dp := CliResource[DataProductCanonicalData]{
+		ApiVersion:   "v1",
+		ResourceType: "data-product",
+		ResourceName: "dp-id",
+		Data: DataProductCanonicalData{
+			Name:                "checkout",
+			Owner:               "me@example.com",
+			SourceApplications:  []Ref{{Ref: "./source-apps/web.yaml"}},
+			EventSpecifications: []EventSpecCanonical{},
+		},
+	}
+
+	res, err := files.CreateDataProducts([]CliResource[DataProductCanonicalData]{dp}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := res[nested]; !ok || len(res) != 1 {
+		t.Fatalf("expected the existing file to be used got %v", res)
+	}
+
+	updated, err := os.ReadFile(nested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `apiVersion: v1
+resourceType: data-product
+resourceName: dp-id
+data:
+    name: checkout # the checkout funnel
+    sourceApplications:
+        - $ref: ../source-apps/web.yaml
+    eventSpecifications: []
+    owner: me@example.com
+`
+	if string(updated) != expected {
+		t.Fatalf("unexpected file\n%s", updated)
+	}
+}
+
+func TestCreateDataProductsMerge(t *testing.T) {
+	dir := t.TempDir()
+	stateDir := t.TempDir()
+	dp := func(description string, owner string) CliResource[DataProductCanonicalData] {
+		return CliResource[DataProductCanonicalData]{
+			ApiVersion:   "v1",
+			ResourceType: "data-product",
+			ResourceName: "dp-id",
+			Data: DataProductCanonicalData{
+				Name:                "checkout",
+				Description:         description,
+				Owner:               owner,
+				SourceApplications:  []Ref{},
+				EventSpecifications: []EventSpecCanonical{},
+			},
+		}
+	}
+
+	files := Files{DataProductsLocation: dir, ExtentionPreference: "yaml", StateDir: stateDir}
+	res, err := files.CreateDataProducts([]CliResource[DataProductCanonicalData]{dp("old", "a@example.com")}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fileName string
+	for f := range res {
+		fileName = f
+	}
+
+	// edited in git
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content = []byte(strings.Replace(string(content), "description: old", "description: from git # reviewed", 1))
+	if err := os.WriteFile(fileName, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// and in Console
+	files.Merge = &MergeReport{}
+	if _, err := files.CreateDataProducts([]CliResource[DataProductCanonicalData]{dp("old", "b@example.com")}, true); err != nil {
+		t.Fatal(err)
+	}
+	if len(files.Merge.Conflicts) != 0 {
+		t.Fatalf("unexpected conflicts %v", files.Merge.Conflicts)
+	}
+	merged, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(merged), "description: from git # reviewed") || !strings.Contains(string(merged), "owner: b@example.com") {
+		t.Fatalf("expected both changes got\n%s", merged)
+	}
+
+	// both sides change the description
+	files.Merge = &MergeReport{}
+	if _, err := files.CreateDataProducts([]CliResource[DataProductCanonicalData]{dp("from console", "b@example.com")}, true); err != nil {
+		t.Fatal(err)
+	}
+	if len(files.Merge.Conflicts) != 1 || files.Merge.Conflicts[0].Path != "/data/description" || files.Merge.Conflicts[0].File != fileName {
+		t.Fatalf("expected a description conflict got %v", files.Merge.Conflicts)
+	}
+	kept, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(kept) != string(merged) {
+		t.Fatalf("expected the local value to be kept got\n%s", kept)
+	}
+}
+
+func TestRecordFileBase(t *testing.T) {
+	dir := t.TempDir()
+	stateDir := filepath.Join(dir, state.Dir)
+	fileName := filepath.Join(dir, "base", "sa.yaml")
+	patchName := filepath.Join(dir, "overlays", "prod", "sa.yaml")
+	for f, content := range map[string]string{
+		fileName:  "apiVersion: v1\nresourceType: source-application\nresourceName: sa-1\ndata:\n  name: web\n  appIds: [staging-web]\n",
+		patchName: "apiVersion: v1\nresourceType: source-application\nresourceName: sa-1\ndata:\n  appIds: [prod-web]\n",
+	} {
+		if err := os.MkdirAll(filepath.Dir(f), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(f, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, _, err := MaybeResourcesfromPaths([]string{filepath.Join(dir, "base")}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ApplyOverlay(files, filepath.Dir(patchName), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RecordFileBase(stateDir, fileName, "sa-1"); err != nil {
+		t.Fatal(err)
+	}
+	base, ok, err := state.LoadBase(stateDir, "sa-1")
+	if err != nil || !ok {
+		t.Fatalf("expected a base got %v %v", ok, err)
+	}
+	appIds := base.(map[string]any)["data"].(map[string]any)["appIds"]
+	if !reflect.DeepEqual(appIds, []any{"staging-web"}) {
+		t.Fatalf("expected the base without the overlay got %v", appIds)
+	}
+
+	if err := RecordFileBase(stateDir, fileName+TemplateExt, "sa-2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := state.LoadBase(stateDir, "sa-2"); ok {
+		t.Fatal("expected templates to be skipped")
+	}
+}
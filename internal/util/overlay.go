@@ -0,0 +1,143 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package util
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// OverlayDir is the directory of the named overlay under ./overlays, names
+// holding a path separator are paths already
+func OverlayDir(name string) string {
+	if strings.ContainsAny(name, `/\`) {
+		return name
+	}
+	return filepath.Join(OverlaysFolder, name)
+}
+
+// ApplyOverlay merges the patch files under dir into the resources of files
+// they name with resourceType and resourceName. Maps are merged, a null
+// removes a key, lists of items with a resourceName or an id, eg: event
+// specifications or triggers, are merged item by item and other lists are
+// replaced.
+// Templated patch files are rendered with vars. Returns the patch files
+// applied to each file
+func ApplyOverlay(files map[string]map[string]any, dir string, vars map[string]any) (map[string][]string, error) {
+	patches, _, err := MaybeResourcesfromPaths([]string{dir}, vars)
+	if err != nil {
+		return nil, fmt.Errorf("reading overlay %s: %w", dir, err)
+	}
+
+	type key struct{ resourceType, resourceName string }
+	bases := map[key]string{}
+	for f, r := range files {
+		rType, _ := r["resourceType"].(string)
+		name, _ := r["resourceName"].(string)
+		if name != "" {
+			bases[key{rType, name}] = f
+		}
+	}
+
+	applied := map[string][]string{}
+	for _, pf := range slices.Sorted(maps.Keys(patches)) {
+		patch := patches[pf]
+		if len(patch) == 0 {
+			// not a yaml or json file
+			continue
+		}
+		rType, _ := patch["resourceType"].(string)
+		name, _ := patch["resourceName"].(string)
+		if name == "" {
+			return nil, fmt.Errorf("overlay file %s: missing resourceName", pf)
+		}
+		f, ok := bases[key{rType, name}]
+		if !ok {
+			return nil, fmt.Errorf("overlay file %s: no %s with resourceName %s", pf, rType, name)
+		}
+		data, _ := patch["data"].(map[string]any)
+		base, _ := files[f]["data"].(map[string]any)
+		if base == nil {
+			base = map[string]any{}
+			files[f]["data"] = base
+		}
+		mergePatch(base, data)
+		applied[f] = append(applied[f], pf)
+		slog.Debug("overlay", "msg", "applied", "patch", pf, "file", f)
+	}
+	return applied, nil
+}
+
+func mergePatch(base map[string]any, patch map[string]any) {
+	for k, pv := range patch {
+		if pv == nil {
+			delete(base, k)
+			continue
+		}
+		switch pv := pv.(type) {
+		case map[string]any:
+			if bv, ok := base[k].(map[string]any); ok {
+				mergePatch(bv, pv)
+				continue
+			}
+		case []any:
+			if bv, ok := base[k].([]any); ok {
+				if key := listKey(pv); key != "" {
+					base[k] = mergeKeyedList(bv, pv, key)
+					continue
+				}
+			}
+		}
+		base[k] = pv
+	}
+}
+
+// listKey is the key identifying the items of a list, resourceName or id
+// when every item has one
+func listKey(l []any) string {
+	for _, key := range []string{"resourceName", "id"} {
+		if len(l) > 0 && !slices.ContainsFunc(l, func(item any) bool {
+			m, ok := item.(map[string]any)
+			if !ok {
+				return true
+			}
+			_, ok = m[key].(string)
+			return !ok
+		}) {
+			return key
+		}
+	}
+	return ""
+}
+
+// items of patch are merged into the item of base with the same key, or
+// added
+func mergeKeyedList(base []any, patch []any, key string) []any {
+	for _, pItem := range patch {
+		p := pItem.(map[string]any)
+		merged := false
+		for _, bItem := range base {
+			if b, ok := bItem.(map[string]any); ok && b[key] == p[key] {
+				mergePatch(b, p)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			base = append(base, p)
+		}
+	}
+	return base
+}
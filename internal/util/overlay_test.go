@@ -0,0 +1,135 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_ApplyOverlay(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base")
+	overlay := filepath.Join(dir, "overlays", "prod")
+	for _, d := range []string{base, overlay} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(name string, content string) {
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(filepath.Join(base, "sa.yaml"), `apiVersion: v1
+resourceType: source-application
+resourceName: sa-1
+data:
+  name: web
+  appIds: [staging-web]
+  entities:
+    tracked:
+      - source: iglu:com.example/user/jsonschema/1-0-0
+`)
+	write(filepath.Join(base, "dp.yaml"), `apiVersion: v1
+resourceType: data-product
+resourceName: dp-1
+data:
+  name: checkout
+  description: staging checkout
+  eventSpecifications:
+    - resourceName: es-1
+      name: buy
+      triggers:
+        - id: t-1
+          description: click
+          url: https://staging.example.com
+        - id: t-2
+          description: submit
+          url: https://staging.example.com/checkout
+    - resourceName: es-2
+      name: view
+`)
+	write(filepath.Join(overlay, "sa.yaml"), `apiVersion: v1
+resourceType: source-application
+resourceName: sa-1
+data:
+  appIds: [prod-web]
+`)
+	write(filepath.Join(overlay, "dp.yaml"), `apiVersion: v1
+resourceType: data-product
+resourceName: dp-1
+data:
+  description: null
+  eventSpecifications:
+    - resourceName: es-1
+      triggers:
+        - id: t-1
+          url: https://www.example.com
+`)
+	write(filepath.Join(overlay, "README.md"), "prod overlay\n")
+
+	files, _, err := MaybeResourcesfromPaths([]string{base}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied, err := ApplyOverlay(files, overlay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 2 {
+		t.Fatalf("expected both files patched, got %v", applied)
+	}
+
+	sa := files[filepath.Join(base, "sa.yaml")]["data"].(map[string]any)
+	if !reflect.DeepEqual(sa["appIds"], []any{"prod-web"}) || sa["name"] != "web" || sa["entities"] == nil {
+		t.Fatalf("unexpected source app %v", sa)
+	}
+
+	dp := files[filepath.Join(base, "dp.yaml")]["data"].(map[string]any)
+	if _, ok := dp["description"]; ok || dp["name"] != "checkout" {
+		t.Fatalf("unexpected data product %v", dp)
+	}
+	ess := dp["eventSpecifications"].([]any)
+	if len(ess) != 2 {
+		t.Fatalf("expected event specs merged by resourceName, got %v", ess)
+	}
+	es := ess[0].(map[string]any)
+	triggers := es["triggers"].([]any)
+	if es["name"] != "buy" || len(triggers) != 2 {
+		t.Fatalf("expected triggers merged by id, got %v", es)
+	}
+	click, submit := triggers[0].(map[string]any), triggers[1].(map[string]any)
+	if click["url"] != "https://www.example.com" || click["description"] != "click" || submit["url"] != "https://staging.example.com/checkout" {
+		t.Fatalf("unexpected triggers %v", triggers)
+	}
+
+	write(filepath.Join(overlay, "typo.yaml"), `apiVersion: v1
+resourceType: data-product
+resourceName: dp-2
+data: {}
+`)
+	if _, err := ApplyOverlay(files, overlay, nil); err == nil {
+		t.Fatal("expected an error for a patch without a base resource")
+	}
+}
+
+func Test_OverlayDir(t *testing.T) {
+	if OverlayDir("prod") != filepath.Join("overlays", "prod") {
+		t.Fatalf("unexpected overlay dir %s", OverlayDir("prod"))
+	}
+	if OverlayDir("./envs/prod") != "./envs/prod" {
+		t.Fatalf("unexpected overlay dir %s", OverlayDir("./envs/prod"))
+	}
+}
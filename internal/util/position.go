@@ -0,0 +1,184 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package util
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/token"
+)
+
+type Position struct {
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// PositionMap maps the JSON pointers of a yaml or json document to the
+// position of their key, or of the first key of mapping items in sequences
+type PositionMap map[string]Position
+
+func NewPositionMap(file []byte) (PositionMap, error) {
+	f, err := parser.ParseBytes(file, 0)
+	if err != nil {
+		return nil, err
+	}
+	m := PositionMap{"": {Line: 1, Column: 1}}
+	if len(f.Docs) > 0 && f.Docs[0].Body != nil {
+		m.walk("", f.Docs[0].Body)
+	}
+	return m, nil
+}
+
+func (m PositionMap) walk(pointer string, node ast.Node) {
+	switch n := unwrap(node).(type) {
+	case *ast.MappingNode:
+		for _, v := range n.Values {
+			m.walk(pointer, v)
+		}
+	case *ast.MappingValueNode:
+		key := pointer + "/" + escapePointer(keyString(n.Key))
+		if p, ok := tokenPosition(n.Key.GetToken()); ok {
+			m[key] = p
+		}
+		m.walk(key, n.Value)
+	case *ast.SequenceNode:
+		for i, v := range n.Values {
+			item := fmt.Sprintf("%s/%d", pointer, i)
+			at := unwrap(v).GetToken()
+			if mv, isMapping := unwrap(v).(*ast.MappingNode); isMapping && len(mv.Values) > 0 && !mv.IsFlowStyle {
+				at = mv.Values[0].Key.GetToken()
+			}
+			if p, ok := tokenPosition(at); ok {
+				m[item] = p
+			}
+			m.walk(item, v)
+		}
+	}
+}
+
+// Lookup resolves a JSON pointer, eg: /data/eventSpecifications/0/name, to
+// its position. When the full pointer does not exist the position of its
+// deepest existing parent is returned, the start of the file for the root
+func (m PositionMap) Lookup(pointer string) Position {
+	pointer = strings.TrimSuffix(pointer, "/")
+	for {
+		if p, ok := m[pointer]; ok {
+			return p
+		}
+		i := strings.LastIndex(pointer, "/")
+		if i < 0 {
+			return Position{Line: 1, Column: 1}
+		}
+		pointer = pointer[:i]
+	}
+}
+
+// PointerPosition resolves a JSON pointer of a yaml or json file, see
+// PositionMap.Lookup
+func PointerPosition(file []byte, pointer string) (Position, bool) {
+	m, err := NewPositionMap(file)
+	if err != nil {
+		return Position{}, false
+	}
+	return m.Lookup(pointer), true
+}
+
+// Positions are the position maps of the files read by a loader, keyed by
+// absolute path
+type Positions map[string]PositionMap
+
+func (p Positions) record(fileName string, file []byte) {
+	if p == nil {
+		return
+	}
+	// the positions of a template are not the ones of what it renders
+	switch filepath.Ext(fileName) {
+	case ".yaml", ".yml", ".json":
+	default:
+		return
+	}
+	abs, err := filepath.Abs(fileName)
+	if err != nil {
+		return
+	}
+	m, err := NewPositionMap(file)
+	if err != nil {
+		return
+	}
+	p[abs] = m
+}
+
+// Lookup resolves a JSON pointer of a file to its position as the file was
+// loaded. Files not loaded, or rendered from a template, have none
+func (p Positions) Lookup(fileName string, pointer string) (Position, bool) {
+	abs, err := filepath.Abs(fileName)
+	if err != nil {
+		return Position{}, false
+	}
+	m, ok := p[abs]
+	if !ok {
+		return Position{}, false
+	}
+	return m.Lookup(pointer), true
+}
+
+// Location formats a pointer of a file as displayName:line:col, falling
+// back to displayName when the position is unknown
+func (p Positions) Location(fileName string, displayName string, pointer string) string {
+	if pos, ok := p.Lookup(fileName, pointer); ok {
+		return fmt.Sprintf("%s:%s", displayName, pos)
+	}
+	return displayName
+}
+
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func unwrap(n ast.Node) ast.Node {
+	for {
+		switch v := n.(type) {
+		case *ast.DocumentNode:
+			n = v.Body
+		case *ast.TagNode:
+			n = v.Value
+		case *ast.AnchorNode:
+			n = v.Value
+		case *ast.MappingValueNode:
+			// a single key mapping is represented by its only value node
+			return v
+		default:
+			return n
+		}
+	}
+}
+
+func keyString(n ast.MapKeyNode) string {
+	if s, ok := n.(*ast.StringNode); ok {
+		return s.Value
+	}
+	return n.GetToken().Value
+}
+
+func tokenPosition(t *token.Token) (Position, bool) {
+	if t == nil || t.Position == nil || t.Position.Line < 1 {
+		return Position{}, false
+	}
+	return Position{Line: t.Position.Line, Column: t.Position.Column}, true
+}
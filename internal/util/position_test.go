@@ -0,0 +1,110 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_PointerPositionYaml(t *testing.T) {
+	file := []byte(`apiVersion: v1
+data:
+  name: checkout
+  eventSpecifications:
+    - resourceName: a
+      name: first
+    - name: second
+      entities:
+        tracked:
+          - source: iglu:com.acme/user/jsonschema/1-0-0
+`)
+
+	cases := map[string]Position{
+		"":                                 {1, 1},
+		"/data/name":                       {3, 3},
+		"/data/eventSpecifications/0/name": {6, 7},
+		"/data/eventSpecifications/1":      {7, 7},
+		"/data/eventSpecifications/1/entities/tracked/0/source": {10, 13},
+		// missing keys resolve to their deepest existing parent
+		"/data/eventSpecifications/1/entities/tracked/0/schema": {10, 13},
+		"/data/owner": {2, 1},
+	}
+
+	for pointer, expected := range cases {
+		pos, ok := PointerPosition(file, pointer)
+		if !ok || pos != expected {
+			t.Fatalf("%s: expected %v got %v", pointer, expected, pos)
+		}
+	}
+}
+
+func Test_PointerPositionJson(t *testing.T) {
+	file := []byte(`{
+  "apiVersion": "v1",
+  "data": {
+    "eventSpecifications": [
+      {"name": "a"},
+      {
+        "name": "b"
+      }
+    ]
+  }
+}`)
+
+	cases := map[string]Position{
+		"/data/eventSpecifications/0/name": {5, 8},
+		"/data/eventSpecifications/1/name": {7, 9},
+	}
+
+	for pointer, expected := range cases {
+		pos, ok := PointerPosition(file, pointer)
+		if !ok || pos != expected {
+			t.Fatalf("%s: expected %v got %v", pointer, expected, pos)
+		}
+	}
+
+	if _, ok := PointerPosition([]byte("{ invalid"), "/data"); ok {
+		t.Fatal("expected invalid file to not resolve")
+	}
+}
+
+func Test_PositionsReturnedByLoader(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "web.yaml")
+	if err := os.WriteFile(fileName, []byte("apiVersion: v1\nresourceType: source-application\ndata:\n  name: web\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, positions, err := MaybeResourcesfromPaths([]string{dir}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// positions refer to the file as it was loaded
+	if err := os.WriteFile(fileName, []byte("data:\n  name: web\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pos, ok := positions.Lookup(fileName, "/data/name")
+	if !ok || pos != (Position{4, 3}) {
+		t.Fatalf("unexpected position %v", pos)
+	}
+
+	if loc := positions.Location(fileName, "web.yaml", "/data/appIds"); loc != "web.yaml:3:1" {
+		t.Fatalf("unexpected location %s", loc)
+	}
+
+	if loc := positions.Location(filepath.Join(dir, "other.yaml"), "other.yaml", "/data"); loc != "other.yaml" {
+		t.Fatalf("unexpected location of a file not loaded %s", loc)
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package util
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// TemplateExt marks resource files rendered with text/template before being
+// parsed according to the extension it suffixes, eg: checkout.yaml.tmpl
+const TemplateExt = ".tmpl"
+
+const VarsFile = "vars.yml"
+
+// generated resource names are name based uuids in this namespace
+var templateNamespace = uuid.MustParse("6f3a0b1e-52a4-4c8e-9a47-2d1c5b7e8f90")
+
+// AddTemplateFlags adds the flags setting the variables of resource file
+// templates to a command reading data products, see TemplateVarsFromCmd
+func AddTemplateFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringArray("var", []string{}, "Set a variable of *.tmpl resource files, as name=value, overrides the variables file")
+	cmd.PersistentFlags().String("vars-file", VarsFile, "Variables of *.tmpl resource files")
+}
+
+// TemplateVarsFromCmd loads the variables of the --vars-file and --var
+// flags
+func TemplateVarsFromCmd(cmd *cobra.Command) (map[string]any, error) {
+	varsFile, _ := cmd.Flags().GetString("vars-file")
+	overrides, _ := cmd.Flags().GetStringArray("var")
+	if varsFile == "" {
+		varsFile = VarsFile
+	}
+	return LoadTemplateVars(varsFile, overrides)
+}
+
+// LoadTemplateVars reads variables from fileName, a missing file has none,
+// then sets the name=value ones of overrides
+func LoadTemplateVars(fileName string, overrides []string) (map[string]any, error) {
+	vars := map[string]any{}
+
+	file, err := os.ReadFile(fileName)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(file, &vars); err != nil {
+			return nil, fmt.Errorf("reading variables from %s: %w", fileName, err)
+		}
+		if vars == nil {
+			vars = map[string]any{}
+		}
+	}
+
+	for _, o := range overrides {
+		name, value, found := strings.Cut(o, "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid variable %q, expected name=value", o)
+		}
+		vars[name] = value
+	}
+
+	return vars, nil
+}
+
+// StableUUID derives a uuid from its inputs, the same inputs give the same
+// uuid so generated resources keep their resource name between runs
+func StableUUID(inputs ...any) string {
+	parts := make([]string, len(inputs))
+	for i, in := range inputs {
+		parts[i] = fmt.Sprint(in)
+	}
+	return uuid.NewSHA1(templateNamespace, []byte(strings.Join(parts, "\x00"))).String()
+}
+
+var templateFuncs = template.FuncMap{
+	"uuid":  StableUUID,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"quote": strconv.Quote,
+}
+
+// renderTemplate renders the body of a TemplateExt file with vars, an
+// unknown variable is an error
+func renderTemplate(fileName string, body []byte, vars map[string]any) ([]byte, error) {
+	t, err := template.New(filepath.Base(fileName)).Funcs(templateFuncs).Option("missingkey=error").Parse(string(body))
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err := t.Execute(&out, vars); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// resourceExt is the extension a file is parsed by, the one before
+// TemplateExt for templates
+func resourceExt(fileName string) string {
+	return filepath.Ext(strings.TrimSuffix(fileName, TemplateExt))
+}
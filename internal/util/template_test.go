@@ -0,0 +1,109 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const checkoutTemplate = `apiVersion: v1
+resourceType: data-product
+resourceName: {{ uuid "checkout" }}
+data:
+  name: Checkout
+  description: {{ .description }}
+  eventSpecifications:
+{{- range .markets }}
+    - resourceName: {{ uuid "checkout" . }}
+      name: Checkout {{ upper . }}
+{{- end }}
+`
+
+func Test_LoadTemplateVars(t *testing.T) {
+	dir := t.TempDir()
+	varsFile := filepath.Join(dir, "vars.yml")
+	if err := os.WriteFile(varsFile, []byte("markets: [fr, de]\ndescription: staging\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	vars, err := LoadTemplateVars(varsFile, []string{"description=prod=eu"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]any{"markets": []any{"fr", "de"}, "description": "prod=eu"}
+	if !reflect.DeepEqual(vars, expected) {
+		t.Fatalf("unexpected variables %v", vars)
+	}
+
+	vars, err = LoadTemplateVars(filepath.Join(dir, "missing.yml"), nil)
+	if err != nil || len(vars) != 0 {
+		t.Fatalf("expected no variables for a missing file, got %v %v", vars, err)
+	}
+
+	if _, err := LoadTemplateVars(varsFile, []string{"description"}); err == nil {
+		t.Fatal("expected an error for a variable without a value")
+	}
+}
+
+func Test_MaybeResourcesfromPathsTemplate(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "checkout.yaml.tmpl")
+	if err := os.WriteFile(fileName, []byte(checkoutTemplate), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	vars := map[string]any{"markets": []any{"fr", "de"}, "description": "checkout"}
+	files, positions, err := MaybeResourcesfromPaths([]string{dir}, vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dp := files[fileName]
+	if dp["resourceName"] != StableUUID("checkout") {
+		t.Fatalf("unexpected resource name %v", dp["resourceName"])
+	}
+	ess := dp["data"].(map[string]any)["eventSpecifications"].([]any)
+	if len(ess) != 2 {
+		t.Fatalf("expected an event spec per market, got %v", ess)
+	}
+	fr := ess[0].(map[string]any)
+	if fr["name"] != "Checkout FR" || fr["resourceName"] != StableUUID("checkout", "fr") {
+		t.Fatalf("unexpected event spec %v", fr)
+	}
+	if fr["resourceName"] == ess[1].(map[string]any)["resourceName"] {
+		t.Fatal("expected distinct resource names per market")
+	}
+
+	// lines of the template are not the ones of the rendered resources
+	if _, ok := positions.Lookup(fileName, "/data"); ok {
+		t.Fatal("expected no positions for a template")
+	}
+
+	// generated resources are not written by downloads
+	if existing := indexResourceFiles(dir, vars); existing.templated[StableUUID("checkout")] != fileName {
+		t.Fatalf("expected the template indexed, got %v", existing.templated)
+	}
+
+	if _, _, err := MaybeResourcesfromPaths([]string{dir}, map[string]any{"markets": []any{"fr"}}); err == nil {
+		t.Fatal("expected an error for a missing variable")
+	}
+}
+
+func Test_StableUUID(t *testing.T) {
+	if StableUUID("checkout", "fr") != StableUUID("checkout", "fr") {
+		t.Fatal("expected the same uuid for the same inputs")
+	}
+	if StableUUID("checkout", "fr") == StableUUID("checkoutfr") {
+		t.Fatal("expected inputs to be kept apart")
+	}
+}
@@ -29,7 +29,9 @@ import (
 	kjson "k8s.io/apimachinery/pkg/util/json"
 )
 
-func DataStructuresFromPaths(paths []string) (map[string]model.DataStructure, error) {
+// DataStructuresFromPaths reads the data structures under paths, keyed by
+// file name, and the positions of their content
+func DataStructuresFromPaths(paths []string) (map[string]model.DataStructure, Positions, error) {
 
 	files := map[string]bool{}
 
@@ -44,11 +46,12 @@ func DataStructuresFromPaths(paths []string) (map[string]model.DataStructure, er
 			return nil
 		})
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
 	ds := make(map[string]model.DataStructure)
+	positions := Positions{}
 
 	exts := []string{".yaml", ".yml", ".json"}
 
@@ -56,9 +59,9 @@ func DataStructuresFromPaths(paths []string) (map[string]model.DataStructure, er
 
 	for k := range files {
 		if slices.Index(exts, filepath.Ext(k)) != -1 {
-			d, err := dataStructureFromFileName(k)
+			d, err := dataStructureFromFileName(k, positions)
 			if err != nil {
-				return nil, errors.Join(err, fmt.Errorf("file: %s", k))
+				return nil, nil, errors.Join(err, fmt.Errorf("file: %s", k))
 			} else {
 				ds[k] = *d
 			}
@@ -66,13 +69,13 @@ func DataStructuresFromPaths(paths []string) (map[string]model.DataStructure, er
 	}
 
 	if len(wrongVersions) > 0 {
-		return nil, errors.New(strings.Join(wrongVersions, "\n"))
+		return nil, nil, errors.New(strings.Join(wrongVersions, "\n"))
 	}
 
-	return ds, nil
+	return ds, positions, nil
 }
 
-func dataStructureFromFileName(f string) (*model.DataStructure, error) {
+func dataStructureFromFileName(f string, positions Positions) (*model.DataStructure, error) {
 	file, err := os.Open(f)
 	if err != nil {
 		return nil, err
@@ -96,10 +99,12 @@ func dataStructureFromFileName(f string) (*model.DataStructure, error) {
 		return nil, err
 	}
 
+	positions.record(f, body)
+
 	return &ds, nil
 }
 
-func dataFromFileName(f string) (map[string]any, error) {
+func dataFromFileName(f string, vars map[string]any, positions Positions) (map[string]any, error) {
 	file, err := os.Open(f)
 	if err != nil {
 		return nil, err
@@ -111,8 +116,15 @@ func dataFromFileName(f string) (map[string]any, error) {
 		return nil, err
 	}
 
+	if filepath.Ext(f) == TemplateExt {
+		body, err = renderTemplate(f, body, vars)
+		if err != nil {
+			return nil, fmt.Errorf("rendering template %s: %w", f, err)
+		}
+	}
+
 	ds := map[string]any{}
-	switch filepath.Ext(file.Name()) {
+	switch resourceExt(file.Name()) {
 	case ".json":
 		err = kjson.Unmarshal(body, &ds)
 	case ".yaml", ".yml":
@@ -123,12 +135,18 @@ func dataFromFileName(f string) (map[string]any, error) {
 		return nil, err
 	}
 
+	positions.record(f, body)
+
 	return ds, nil
 }
 
-func MaybeResourcesfromPaths(paths []string) (map[string]map[string]any, error) {
+// MaybeResourcesfromPaths reads every file under paths, keyed by absolute
+// path, and the positions of their content. Templates are rendered with
+// vars, their positions are unknown
+func MaybeResourcesfromPaths(paths []string, vars map[string]any) (map[string]map[string]any, Positions, error) {
 
 	files := map[string]map[string]any{}
+	positions := Positions{}
 
 	for _, path := range paths {
 		err := filepath.WalkDir(path, func(path string, di fs.DirEntry, err error) error {
@@ -140,7 +158,7 @@ func MaybeResourcesfromPaths(paths []string) (map[string]map[string]any, error)
 				if err != nil {
 					return err
 				}
-				files[absPath], err = dataFromFileName(path)
+				files[absPath], err = dataFromFileName(path, vars, positions)
 				if err != nil {
 					return err
 				}
@@ -148,11 +166,11 @@ func MaybeResourcesfromPaths(paths []string) (map[string]map[string]any, error)
 			return nil
 		})
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
-	return files, nil
+	return files, positions, nil
 }
 
 func ResourceNameToFileName(s string) string {
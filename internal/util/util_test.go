@@ -23,7 +23,7 @@ func Test_DataStructuresFromPaths(t *testing.T) {
 	path := strings.Join([]string{"..", "testdata", "util"}, string(os.PathSeparator))
 	paths := []string{path}
 
-	ds, err := DataStructuresFromPaths(paths)
+	ds, _, err := DataStructuresFromPaths(paths)
 
 	if err != nil {
 		t.Fatal(err)
@@ -54,7 +54,7 @@ func Test_DataStructuresFromPathsFailNotASchema(t *testing.T) {
 	path := strings.Join([]string{"testdata", "not-a-schema"}, string(os.PathSeparator))
 	paths := []string{path}
 
-	_, err := DataStructuresFromPaths(paths)
+	_, _, err := DataStructuresFromPaths(paths)
 
 	if err == nil {
 		t.Fatal(err)
@@ -70,7 +70,7 @@ func Test_MaybeResourcesfromPaths(t *testing.T) {
 		filepath.Join("testdata", "data-products"),
 	}
 
-	dps, err := MaybeResourcesfromPaths(paths)
+	dps, _, err := MaybeResourcesfromPaths(paths, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
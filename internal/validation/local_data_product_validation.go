@@ -24,12 +24,13 @@ type result struct {
 	props      map[string]string
 	err        error
 	pathLookup map[string]string
+	// path of the event specification, for errors of the check itself
+	path string
 }
 
 func ValidateDPEventSpecCompat(cc console.CompatChecker, concurrency int, dp model.DataProduct) DPValidations {
 	pathErrors := map[string][]string{}
 	pathWarnings := map[string][]string{}
-	errors := []string{}
 
 	resultsChan := make(chan result)
 
@@ -82,7 +83,7 @@ func ValidateDPEventSpecCompat(cc console.CompatChecker, concurrency int, dp mod
 		}
 
 		wg.Add(1)
-		go func(event console.CompatCheckable, entities []console.CompatCheckable, pathLookup map[string]string) {
+		go func(event console.CompatCheckable, entities []console.CompatCheckable, pathLookup map[string]string, path string) {
 			defer wg.Done()
 
 			semaphore <- struct{}{}        // Acquire semaphore
@@ -91,7 +92,8 @@ func ValidateDPEventSpecCompat(cc console.CompatChecker, concurrency int, dp mod
 			cc_result, err := cc(event, entities)
 			if err != nil {
 				resultsChan <- result{
-					err: fmt.Errorf("unexpected error checking compatibility: %s", err.Error()),
+					err:  fmt.Errorf("unexpected error checking compatibility: %s", err.Error()),
+					path: path,
 				}
 				return
 			}
@@ -104,7 +106,7 @@ func ValidateDPEventSpecCompat(cc console.CompatChecker, concurrency int, dp mod
 					pathLookup: pathLookup,
 				}
 			}
-		}(*event, entities, pathLookup)
+		}(*event, entities, pathLookup, fmt.Sprintf("/data/eventSpecifications/%d", i))
 	}
 
 	go func() {
@@ -114,7 +116,7 @@ func ValidateDPEventSpecCompat(cc console.CompatChecker, concurrency int, dp mod
 
 	for res := range resultsChan {
 		if res.err != nil {
-			errors = append(errors, res.err.Error())
+			pathErrors[res.path] = append(pathErrors[res.path], res.err.Error())
 			continue
 		}
 		if path, ok := res.pathLookup[res.source]; ok {
@@ -148,5 +150,5 @@ func ValidateDPEventSpecCompat(cc console.CompatChecker, concurrency int, dp mod
 		}
 	}
 
-	return DPValidations{Errors: errors, ErrorsWithPaths: pathErrors, WarningsWithPaths: pathWarnings}
+	return DPValidations{ErrorsWithPaths: pathErrors, WarningsWithPaths: pathWarnings}
 }
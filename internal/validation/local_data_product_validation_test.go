@@ -11,6 +11,8 @@
 package validation
 
 import (
+	"errors"
+	"reflect"
 	"slices"
 	"testing"
 
@@ -118,3 +120,46 @@ func Test_ValidateDPEventSpecCompatFail(t *testing.T) {
 		t.Fatalf("missing warning at: %s", expectedWarningsPath)
 	}
 }
+
+func Test_ValidateDPDeprecations(t *testing.T) {
+	deprecated := true
+	dp := model.DataProduct{
+		Data: model.DataProductData{
+			EventSpecifications: []model.EventSpec{{
+				Event: model.SchemaRef{Source: "iglu:vendor/name/format/1-0-0"},
+				Entities: model.EntitiesDef{
+					Enriched: []model.SchemaRef{{Source: "iglu:vendor/name/format/1-0-0"}},
+				},
+			}},
+		},
+	}
+
+	dc := &mockDc{map[string]model.DataStructureMeta{
+		"iglu:vendor/name/format/1-0-0": {Deprecated: &deprecated},
+	}}
+
+	result := ValidateDPDeprecations(dc, dp).WarningsWithPaths
+
+	expected := map[string][]string{
+		"/data/eventSpecifications/0/event/source":               {"iglu:vendor/name/format/1-0-0 is deprecated"},
+		"/data/eventSpecifications/0/entities/enriched/0/source": {"iglu:vendor/name/format/1-0-0 is deprecated"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("unexpected got: %#v want %#v", result, expected)
+	}
+}
+
+func Test_ValidateDPEventSpecCompatError(t *testing.T) {
+	dp := newValidDPForCompatTesting()
+
+	cc := func(event console.CompatCheckable, entities []console.CompatCheckable) (*console.CompatResult, error) {
+		return nil, errors.New("unavailable")
+	}
+
+	result := ValidateDPEventSpecCompat(cc, 1, dp)
+
+	expected := []string{"unexpected error checking compatibility: unavailable"}
+	if !slices.Equal(result.ErrorsWithPaths["/data/eventSpecifications/0"], expected) {
+		t.Fatalf("unexpected errors %#v", result.ErrorsWithPaths)
+	}
+}
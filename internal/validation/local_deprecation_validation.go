@@ -0,0 +1,82 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package validation
+
+import (
+	"fmt"
+
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+func deprecationWarning(dc console.DeprecationChecker, uri string) (string, bool) {
+	meta, deprecated := dc.DeprecatedMeta(uri)
+	if !deprecated {
+		return "", false
+	}
+
+	msg := fmt.Sprintf("%s is deprecated", uri)
+	if meta.ReplacedBy != "" {
+		msg = fmt.Sprintf("%s, replaced by %s", msg, meta.ReplacedBy)
+	}
+	if meta.SunsetDate != "" {
+		msg = fmt.Sprintf("%s, sunset on %s", msg, meta.SunsetDate)
+	}
+	return msg, true
+}
+
+func ValidateDPDeprecations(dc console.DeprecationChecker, dp model.DataProduct) DPValidations {
+	warnings := map[string][]string{}
+
+	check := func(path string, uri string) {
+		if msg, ok := deprecationWarning(dc, uri); ok {
+			warnings[path] = append(warnings[path], msg)
+		}
+	}
+
+	for i, spec := range dp.Data.EventSpecifications {
+		if spec.Event.Source != "" {
+			check(fmt.Sprintf("/data/eventSpecifications/%d/event/source", i), spec.Event.Source)
+		}
+		for j, ent := range spec.Entities.Tracked {
+			check(fmt.Sprintf("/data/eventSpecifications/%d/entities/tracked/%d/source", i, j), ent.Source)
+		}
+		for j, ent := range spec.Entities.Enriched {
+			check(fmt.Sprintf("/data/eventSpecifications/%d/entities/enriched/%d/source", i, j), ent.Source)
+		}
+	}
+
+	return DPValidations{WarningsWithPaths: warnings}
+}
+
+func ValidateSADeprecations(dc console.DeprecationChecker, sa model.SourceApp) DPValidations {
+	warnings := map[string][]string{}
+
+	if sa.Data.Entities == nil {
+		return DPValidations{}
+	}
+
+	for i, e := range sa.Data.Entities.Tracked {
+		if msg, ok := deprecationWarning(dc, e.Source); ok {
+			path := fmt.Sprintf("/data/entities/tracked/%d/source", i)
+			warnings[path] = append(warnings[path], msg)
+		}
+	}
+
+	for i, e := range sa.Data.Entities.Enriched {
+		if msg, ok := deprecationWarning(dc, e.Source); ok {
+			path := fmt.Sprintf("/data/entities/enriched/%d/source", i)
+			warnings[path] = append(warnings[path], msg)
+		}
+	}
+
+	return DPValidations{WarningsWithPaths: warnings}
+}
@@ -19,8 +19,11 @@ import (
 
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/lint"
 	"github.com/snowplow/snowplow-cli/internal/logging"
 	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/policy"
+	"github.com/snowplow/snowplow-cli/internal/util"
 )
 
 type DPLookup struct {
@@ -28,6 +31,8 @@ type DPLookup struct {
 	SourceApps   map[string]model.SourceApp
 
 	Validations map[string]DPValidations
+	// positions of the files as loaded, to locate validations with paths
+	Positions util.Positions
 }
 
 type DPValidations struct {
@@ -106,6 +111,9 @@ func NewDPLookup(cc console.CompatChecker, sdc console.SchemaDeployChecker, dp m
 						}
 					}
 				}
+				if ok && sdc != nil {
+					v.concat(ValidateDPDeprecations(sdc, dp))
+				}
 			case "source-application":
 				var sa model.SourceApp
 				if err := mapstructure.Decode(maybeDp, &sa); err == nil {
@@ -118,6 +126,7 @@ func NewDPLookup(cc console.CompatChecker, sdc console.SchemaDeployChecker, dp m
 				v.concat(ValidateSAEntitiesCardinalities(sa))
 				if ok {
 					v.concat(ValidateSAEntitiesSchemaDeployed(sdc, sa))
+					v.concat(ValidateSADeprecations(sdc, sa))
 				}
 			default:
 				v.Debug = append(v.Debug, fmt.Sprintf("ignoring, unknown resourceType: %s", resourceType))
@@ -129,7 +138,7 @@ func NewDPLookup(cc console.CompatChecker, sdc console.SchemaDeployChecker, dp m
 		validation[f] = v
 	}
 
-	result := &DPLookup{probablyDps, probablySap, validation}
+	result := &DPLookup{probablyDps, probablySap, validation, nil}
 	err := result.resolveRefs()
 	if err != nil {
 		return nil, err
@@ -235,13 +244,13 @@ func (lookup *DPLookup) SlogValidations(ctx context.Context, basePath string) er
 			logger.Warn("validating", "file", rp, "msg", m)
 		}
 		for k, se := range v.WarningsWithPaths {
-			logger.Warn("validating", "file", rp, "path", k, "warnings", strings.Join(se, "\n")+"\n")
+			logger.Warn("validating", "file", lookup.Positions.Location(f, rp, k), "path", k, "warnings", strings.Join(se, "\n")+"\n")
 		}
 		for _, m := range v.Errors {
 			logger.Error("validating", "file", rp, "msg", m)
 		}
 		for k, se := range v.ErrorsWithPaths {
-			logger.Error("validating", "file", rp, "path", k, "errors", strings.Join(se, "\n")+"\n")
+			logger.Error("validating", "file", lookup.Positions.Location(f, rp, k), "path", k, "errors", strings.Join(se, "\n")+"\n")
 		}
 	}
 
@@ -259,22 +268,31 @@ func (lookup *DPLookup) GhAnnotateValidations(basePath string) error {
 			fmt.Printf("::info file=%s::%s\n", rp, strings.Join(v.Info, "%0A"))
 		}
 		if len(v.Warnings) > 0 {
-			fmt.Printf("::warn file=%s::%s\n", rp, strings.Join(v.Warnings, "%0A"))
+			fmt.Printf("::warning file=%s::%s\n", rp, strings.Join(v.Warnings, "%0A"))
 		}
 		for k, se := range v.WarningsWithPaths {
-			fmt.Printf("::warn file=%s::%s%%0A%s\n", rp, k, strings.Join(se, "%0A"))
+			fmt.Printf("::warning %s::%s%%0A%s\n", ghFile(lookup.Positions, f, rp, k), k, strings.Join(se, "%0A"))
 		}
 		if len(v.Errors) > 0 {
 			fmt.Printf("::error file=%s::%s\n", rp, strings.Join(v.Errors, "%0A"))
 		}
 		for k, se := range v.ErrorsWithPaths {
-			fmt.Printf("::error file=%s::%s%%0A%s\n", rp, k, strings.Join(se, "%0A"))
+			fmt.Printf("::error %s::%s%%0A%s\n", ghFile(lookup.Positions, f, rp, k), k, strings.Join(se, "%0A"))
 		}
 	}
 
 	return nil
 }
 
+// ghFile formats the file parameters of a github workflow annotation,
+// with the line and column of pointer when it can be resolved
+func ghFile(positions util.Positions, fileName string, displayName string, pointer string) string {
+	if p, ok := positions.Lookup(fileName, pointer); ok {
+		return fmt.Sprintf("file=%s,line=%d,col=%d", displayName, p.Line, p.Column)
+	}
+	return fmt.Sprintf("file=%s", displayName)
+}
+
 func (lookup *DPLookup) ValidationErrorCount() int {
 	count := 0
 	for _, v := range lookup.Validations {
@@ -282,3 +300,60 @@ func (lookup *DPLookup) ValidationErrorCount() int {
 	}
 	return count
 }
+
+// ValidateGovernance runs the enabled data product and source application
+// lint rules, findings are added to the validations of their file
+func (lookup *DPLookup) ValidateGovernance(c lint.Config) {
+	var findings []lint.Finding
+	for f, dp := range lookup.DataProducts {
+		findings = append(findings, c.RunDataProduct(f, dp, lookup.DataProducts)...)
+	}
+	for f, sa := range lookup.SourceApps {
+		findings = append(findings, c.RunSourceApp(f, sa)...)
+	}
+
+	for _, finding := range findings {
+		v := lookup.Validations[finding.File]
+		msg := fmt.Sprintf("[%s] %s", finding.Rule, finding.Message)
+		switch finding.Severity {
+		case lint.SeverityError:
+			v.concat(DPValidations{ErrorsWithPaths: map[string][]string{finding.Path: {msg}}})
+		case lint.SeverityWarning:
+			v.concat(DPValidations{WarningsWithPaths: map[string][]string{finding.Path: {msg}}})
+		default:
+			v.Info = append(v.Info, finding.String())
+		}
+		lookup.Validations[finding.File] = v
+	}
+}
+
+// ValidatePolicies evaluates the policies against data products and
+// source applications as written in their files
+func (lookup *DPLookup) ValidatePolicies(policies []policy.Policy, files map[string]map[string]any) {
+	if len(policies) == 0 {
+		return
+	}
+
+	for f, resource := range files {
+		var resourceType string
+		if _, ok := lookup.DataProducts[f]; ok {
+			resourceType = policy.DataProduct
+		} else if _, ok := lookup.SourceApps[f]; ok {
+			resourceType = policy.SourceApplication
+		} else {
+			continue
+		}
+
+		v := lookup.Validations[f]
+		for _, violation := range policy.CheckAll(policies, resourceType, resource) {
+			msg := fmt.Sprintf("[%s] %s", violation.Policy, violation.Message)
+			switch violation.Severity {
+			case policy.SeverityWarning:
+				v.concat(DPValidations{WarningsWithPaths: map[string][]string{violation.Path: {msg}}})
+			default:
+				v.concat(DPValidations{ErrorsWithPaths: map[string][]string{violation.Path: {msg}}})
+			}
+		}
+		lookup.Validations[f] = v
+	}
+}
@@ -11,7 +11,16 @@
 package validation
 
 import (
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/lint"
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/policy"
+	"github.com/snowplow/snowplow-cli/internal/report"
+	"github.com/snowplow/snowplow-cli/internal/util"
 )
 
 func Test_DPLookup_RelativePaths(t *testing.T) {
@@ -164,3 +173,151 @@ func Test_DPLookup_Ignored(t *testing.T) {
 		t.Fatal("no debug?")
 	}
 }
+
+func Test_DPLookup_ValidateGovernance(t *testing.T) {
+	input := map[string]map[string]any{
+		"/base/source-apps/web.yml": {
+			"apiVersion":   "v1",
+			"resourceType": "source-application",
+			"data": map[string]any{
+				"name": "web",
+			},
+		},
+	}
+
+	lookup, err := NewDPLookup(nil, nil, input, nil, true, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := lint.ParseConfig([]byte("rules:\n  sa-app-ids-required: error\n  sa-description-length: warning\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lookup.ValidateGovernance(config)
+
+	v := lookup.Validations["/base/source-apps/web.yml"]
+	if !slices.Equal(v.ErrorsWithPaths["/data/appIds"], []string{"[sa-app-ids-required] at least one app id is required"}) {
+		t.Fatalf("unexpected errors %#v", v.ErrorsWithPaths)
+	}
+	if !slices.Equal(v.WarningsWithPaths["/data/description"], []string{"[sa-description-length] description is required"}) {
+		t.Fatalf("unexpected warnings %#v", v.WarningsWithPaths)
+	}
+}
+
+func Test_DPLookup_Deprecations(t *testing.T) {
+	deprecated := true
+	input := map[string]map[string]any{
+		"/base/source-apps/web.yml": {
+			"apiVersion":   "v1",
+			"resourceType": "source-application",
+			"resourceName": "3e7f5b5c-1c6a-4d6e-9d3e-2a1b4c5d6e7f",
+			"data": map[string]any{
+				"name":   "web",
+				"appIds": []any{"web"},
+				"entities": map[string]any{
+					"tracked":  []any{map[string]any{"source": "iglu:vendor/name/format/1-0-0"}},
+					"enriched": []any{},
+				},
+			},
+		},
+	}
+	sdc := &mockSdc{
+		deployed:   []string{"iglu:vendor/name/format/1-0-0"},
+		deprecated: map[string]model.DataStructureMeta{"iglu:vendor/name/format/1-0-0": {Deprecated: &deprecated}},
+	}
+
+	lookup, err := NewDPLookup(nil, sdc, input, nil, false, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := lookup.Validations["/base/source-apps/web.yml"]
+	if !slices.Equal(v.WarningsWithPaths["/data/entities/tracked/0/source"], []string{"iglu:vendor/name/format/1-0-0 is deprecated"}) {
+		t.Fatalf("expected a deprecation warning got %#v", v)
+	}
+}
+
+func Test_DPLookup_ValidatePolicies(t *testing.T) {
+	input := map[string]map[string]any{
+		"/base/data-products/checkout.yml": {
+			"apiVersion":   "v1",
+			"resourceType": "data-product",
+			"data": map[string]any{
+				"name": "Checkout",
+			},
+		},
+		"/base/source-apps/web.yml": {
+			"apiVersion":   "v1",
+			"resourceType": "source-application",
+			"data": map[string]any{
+				"name": "web",
+			},
+		},
+	}
+
+	lookup, err := NewDPLookup(nil, nil, input, nil, true, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	owner, err := policy.Load([]byte("resources: [data-product]\nexpression: has(data.owner)\nmessage: owner is required"), "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	named, err := policy.Load([]byte("severity: warning\nexpression: data.name.size() > 3\nmessage: name too short"), "named")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lookup.ValidatePolicies([]policy.Policy{owner, named}, input)
+
+	dp := lookup.Validations["/base/data-products/checkout.yml"]
+	if !slices.Equal(dp.ErrorsWithPaths[policy.Path], []string{"[owner] owner is required"}) || len(dp.WarningsWithPaths) != 0 {
+		t.Fatalf("unexpected data product validations %#v", dp)
+	}
+
+	sa := lookup.Validations["/base/source-apps/web.yml"]
+	if slices.Contains(sa.ErrorsWithPaths[policy.Path], "[named] name too short") || !slices.Equal(sa.WarningsWithPaths[policy.Path], []string{"[named] name too short"}) {
+		t.Fatalf("unexpected source app validations %#v", sa)
+	}
+}
+
+func Test_DPLookup_Report(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "source-apps", "web.yml")
+	if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fileName, []byte("apiVersion: v1\nresourceType: source-application\ndata:\n  name: web\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, positions, err := util.MaybeResourcesfromPaths([]string{dir}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lookup := &DPLookup{Validations: map[string]DPValidations{
+		fileName: {
+			Errors:            []string{"something is wrong"},
+			ErrorsWithPaths:   map[string][]string{"/data/appIds": {"[sa-app-ids-required] at least one app id is required"}},
+			WarningsWithPaths: map[string][]string{"/data/name": {"name is short"}},
+		},
+	}, Positions: positions}
+
+	r, err := lookup.Report(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []report.Issue{
+		{File: "source-apps/web.yml", Severity: report.SeverityError, Message: "something is wrong"},
+		{File: "source-apps/web.yml", Line: 3, Column: 1, Severity: report.SeverityError, Rule: "sa-app-ids-required", Message: "/data/appIds: at least one app id is required"},
+		{File: "source-apps/web.yml", Line: 4, Column: 3, Severity: report.SeverityWarning, Message: "/data/name: name is short"},
+	}
+	if !slices.Equal(r.Issues, expected) || !slices.Equal(r.Files, []string{"source-apps/web.yml"}) {
+		t.Fatalf("unexpected report %#v", r)
+	}
+}
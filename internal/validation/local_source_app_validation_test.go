@@ -11,6 +11,7 @@
 package validation
 
 import (
+	"reflect"
 	"slices"
 	"testing"
 
@@ -169,13 +170,19 @@ func Test_ValidateSAEntitesCardinalities(t *testing.T) {
 }
 
 type mockSdc struct {
-	deployed []string
+	deployed   []string
+	deprecated map[string]model.DataStructureMeta
 }
 
 func (mi *mockSdc) IsDSDeployed(uri string) (bool, []string, error) {
 	return slices.Contains(mi.deployed, uri), nil, nil
 }
 
+func (mi *mockSdc) DeprecatedMeta(uri string) (model.DataStructureMeta, bool) {
+	meta, ok := mi.deprecated[uri]
+	return meta, ok
+}
+
 func Test_ValidateSAEntitiesSchemaDeployed(t *testing.T) {
 	sa := model.SourceApp{
 		Data: model.SourceAppData{
@@ -192,7 +199,7 @@ func Test_ValidateSAEntitiesSchemaDeployed(t *testing.T) {
 		},
 	}
 
-	sdc := &mockSdc{[]string{"iglu:vendor/name/format/1-0-0"}}
+	sdc := &mockSdc{deployed: []string{"iglu:vendor/name/format/1-0-0"}}
 
 	result := ValidateSAEntitiesSchemaDeployed(sdc, sa).ErrorsWithPaths
 
@@ -207,3 +214,39 @@ func Test_ValidateSAEntitiesSchemaDeployed(t *testing.T) {
 		}
 	}
 }
+
+type mockDc struct {
+	deprecated map[string]model.DataStructureMeta
+}
+
+func (m *mockDc) DeprecatedMeta(uri string) (model.DataStructureMeta, bool) {
+	meta, ok := m.deprecated[uri]
+	return meta, ok
+}
+
+func Test_ValidateSADeprecations(t *testing.T) {
+	deprecated := true
+	sa := model.SourceApp{
+		Data: model.SourceAppData{
+			Entities: &model.EntitiesDef{
+				Tracked: []model.SchemaRef{
+					{Source: "iglu:vendor/name/format/1-0-0"},
+					{Source: "iglu:vendor/other/format/1-0-0"},
+				},
+			},
+		},
+	}
+
+	dc := &mockDc{map[string]model.DataStructureMeta{
+		"iglu:vendor/name/format/1-0-0": {Deprecated: &deprecated, ReplacedBy: "iglu:vendor/name/format/2-0-0", SunsetDate: "2027-01-01"},
+	}}
+
+	result := ValidateSADeprecations(dc, sa).WarningsWithPaths
+
+	expected := map[string][]string{
+		"/data/entities/tracked/0/source": {"iglu:vendor/name/format/1-0-0 is deprecated, replaced by iglu:vendor/name/format/2-0-0, sunset on 2027-01-01"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("unexpected got: %#v want %#v", result, expected)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/util"
 	"reflect"
 	"strings"
 
@@ -92,6 +93,7 @@ func ValidateLocalDs(dss map[string]model.DataStructure) []error {
 	})
 	allErrors := []error{}
 	counts := make(map[string][]string)
+	versioned := make(map[string]map[string][]string)
 	for fileName, ds := range dss {
 		errs := validateDs(validate, ds)
 		if errs != nil {
@@ -103,13 +105,31 @@ func ValidateLocalDs(dss map[string]model.DataStructure) []error {
 			allErrors = append(allErrors, err)
 		}
 		key := fmt.Sprintf("%s/%s", data.Self.Vendor, data.Self.Name)
-		counts[key] = append(counts[key], fileName)
+		if util.IsVersionedLayout(fileName, data.Self) {
+			if versioned[key] == nil {
+				versioned[key] = map[string][]string{}
+			}
+			versioned[key][data.Self.Version] = append(versioned[key][data.Self.Version], fileName)
+		} else {
+			counts[key] = append(counts[key], fileName)
+		}
 	}
 	for key, files := range counts {
+		// versions side by side are only allowed in the vendor/name/version layout
+		for _, vFiles := range versioned[key] {
+			files = append(files, vFiles...)
+		}
 		if len(files) > 1 {
 			allErrors = append(allErrors, fmt.Errorf("the mapping between data structures and files should be unique. Files %s describe the same data structure %s", files, key))
 		}
 	}
+	for key, versions := range versioned {
+		for version, files := range versions {
+			if len(files) > 1 {
+				allErrors = append(allErrors, fmt.Errorf("the mapping between data structure versions and files should be unique. Files %s describe the same data structure %s version %s", files, key, version))
+			}
+		}
+	}
 
 	return allErrors
 }
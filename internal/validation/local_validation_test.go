@@ -12,10 +12,17 @@ package validation
 
 import (
 	"errors"
-	. "github.com/snowplow/snowplow-cli/internal/model"
-	kjson "k8s.io/apimachinery/pkg/util/json"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/amend"
+	"github.com/snowplow/snowplow-cli/internal/changes"
+	. "github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	kjson "k8s.io/apimachinery/pkg/util/json"
 )
 
 func Test_Correct(t *testing.T) {
@@ -278,3 +285,101 @@ func Test_DuplicateFile(t *testing.T) {
 	}
 
 }
+
+func TestValidateLocalDsVersionedLayout(t *testing.T) {
+	ds := func(version string) DataStructure {
+		return DataStructure{
+			ApiVersion:   "v1",
+			ResourceType: "data-structure",
+			Meta:         DataStructureMeta{SchemaType: "event", CustomData: map[string]string{}},
+			Data: map[string]any{
+				"self":    map[string]any{"vendor": "example", "name": "example", "format": "jsonschema", "version": version},
+				"$schema": "string",
+			},
+		}
+	}
+
+	errs := ValidateLocalDs(map[string]DataStructure{
+		"ds/example/example/1-0-0.yaml": ds("1-0-0"),
+		"ds/example/example/1-0-1.yaml": ds("1-0-1"),
+	})
+	if len(errs) != 0 {
+		t.Fatalf("Unexpected errors for versioned layout %v", errs)
+	}
+
+	errs = ValidateLocalDs(map[string]DataStructure{
+		"ds/example/example/1-0-0.yaml": ds("1-0-0"),
+		"ds/example/example.yaml":       ds("1-0-1"),
+	})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "unique") {
+		t.Fatalf("Expected mixing layouts to fail, got %v", errs)
+	}
+
+	errs = ValidateLocalDs(map[string]DataStructure{
+		"ds/example/example/1-0-0.yaml": ds("1-0-0"),
+		"ds/example/example/1-0-0.json": ds("1-0-0"),
+	})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "version 1-0-0") {
+		t.Fatalf("Expected duplicate versions to fail, got %v", errs)
+	}
+}
+
+func TestValidateLocalDsAfterVersionedBump(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "com.acme", "checkout")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, version := range []string{"1-0-0", "1-0-1"} {
+		file := fmt.Sprintf(`apiVersion: v1
+resourceType: data-structure
+meta:
+  hidden: false
+  schemaType: event
+  customData: {}
+data:
+  $schema: http://iglucentral.com/schemas/com.snowplowanalytics.self-desc/schema/jsonschema/1-0-0#
+  self:
+    vendor: com.acme
+    name: checkout
+    format: jsonschema
+    version: %s
+  type: object
+`, version)
+		if err := os.WriteFile(filepath.Join(dir, version+".yaml"), []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dss, _, err := util.DataStructuresFromPaths([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var changed []DSChangeContext
+	for f, ds := range dss {
+		changed = append(changed, DSChangeContext{DS: ds, FileName: f, RemoteVersion: "1-0-1"})
+	}
+
+	bumps, err := changes.ExplicitBumps(changes.Changes{ToUpdatePatch: changed}, "minor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bumps) != 1 {
+		t.Fatalf("expected a single bump, got %+v", bumps)
+	}
+	for _, b := range bumps {
+		if err := amend.SetDataStructureVersionInFile(b.FileName, b.NewFileName, b.NewVersion); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dss, _, err = util.DataStructuresFromPaths([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dss) != 3 {
+		t.Fatalf("expected the new version next to the previous ones, got %v", dss)
+	}
+	if errs := ValidateLocalDs(dss); len(errs) != 0 {
+		t.Fatalf("unexpected errors after bump %v", errs)
+	}
+}
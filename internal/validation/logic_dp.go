@@ -16,9 +16,17 @@ import (
 
 	"github.com/snowplow/snowplow-cli/internal/console"
 	"github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/util"
 )
 
-func Validate(ctx context.Context, c *console.ApiClient, files map[string]map[string]any, searchPaths []string, basePath string, ghOut bool, validateAll bool, changedIdToFile map[string]string, concurrency int) error {
+// Validate validates files and checks compatibility of the files in
+// changedIdToFile, or of every file with opts.Full. A non nil affected
+// limits both to the affected files, positions locate their validations.
+// opts.ChangedSince and opts.Overlay are
+// left to the caller, which already applied them to files and affected
+func Validate(ctx context.Context, c *console.ApiClient, files map[string]map[string]any, positions util.Positions, searchPaths []string, changedIdToFile map[string]string, affected map[string]bool, opts DataProductsOptions) error {
+	basePath := opts.BasePath
+	validateAll := opts.Full
 
 	logger := logging.LoggerFromContext(ctx)
 
@@ -36,10 +44,36 @@ func Validate(ctx context.Context, c *console.ApiClient, files map[string]map[st
 		return console.CompatCheck(ctx, c, event, entities)
 	}
 
-	lookup, err := NewDPLookup(compatChecker, schemaResolver, files, changedIdToFile, validateAll, concurrency)
+	if affected != nil {
+		compatFiles := map[string]string{}
+		for id, f := range changedIdToFile {
+			if affected[f] {
+				compatFiles[id] = f
+			}
+		}
+		if validateAll {
+			for f := range affected {
+				compatFiles[f] = f
+			}
+		}
+		changedIdToFile, validateAll = compatFiles, false
+		logger.Debug("validation", "msg", "only validating changed files", "files", len(affected))
+	}
+
+	lookup, err := NewDPLookup(compatChecker, schemaResolver, files, changedIdToFile, validateAll, opts.Concurrency)
 	if err != nil {
 		return err
 	}
+	lookup.Positions = positions
+	lookup.ValidateGovernance(opts.LintConfig)
+	lookup.ValidatePolicies(opts.LintConfig.Policies, files)
+	if affected != nil {
+		for f := range lookup.Validations {
+			if !affected[f] {
+				delete(lookup.Validations, f)
+			}
+		}
+	}
 
 	logger.Debug("validation", "msg", "from", "paths", searchPaths, "files", possibleFiles)
 
@@ -48,13 +82,23 @@ func Validate(ctx context.Context, c *console.ApiClient, files map[string]map[st
 		return err
 	}
 
-	if ghOut {
+	if opts.GhOut {
 		err := lookup.GhAnnotateValidations(basePath)
 		if err != nil {
 			logging.LogFatal(err)
 		}
 	}
 
+	if opts.Report.Format != "" {
+		r, err := lookup.Report(basePath)
+		if err != nil {
+			return err
+		}
+		if err := opts.Report.write(r); err != nil {
+			return err
+		}
+	}
+
 	numErrors := lookup.ValidationErrorCount()
 
 	if numErrors > 0 {
@@ -18,6 +18,7 @@ import (
 	"github.com/snowplow/snowplow-cli/internal/changes"
 	"github.com/snowplow/snowplow-cli/internal/console"
 	"github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/util"
 )
 
 type igluValidationLevel uint
@@ -48,15 +49,15 @@ type ValidationResults struct {
 	Iglu      []igluValidation
 }
 
-func (vr *ValidationResults) GithubAnnotate() {
+func (vr *ValidationResults) GithubAnnotate(positions util.Positions) {
 	for _, iglu := range vr.Iglu {
 		switch iglu.Level {
 		case igluValidationError:
-			fmt.Printf("::error file=%s::%s\n", iglu.File, strings.Join(iglu.Messages, "%0A"))
+			fmt.Printf("::error %s::%s\n", ghFile(positions, iglu.File, iglu.File, "/data"), strings.Join(iglu.Messages, "%0A"))
 		case igluValidationWarn:
-			fmt.Printf("::warning file=%s::%s\n", iglu.File, strings.Join(iglu.Messages, "%0A"))
+			fmt.Printf("::warning %s::%s\n", ghFile(positions, iglu.File, iglu.File, "/data"), strings.Join(iglu.Messages, "%0A"))
 		case igluValidationInfo:
-			fmt.Printf("::notice file=%s::%s\n", iglu.File, strings.Join(iglu.Messages, "%0A"))
+			fmt.Printf("::notice %s::%s\n", ghFile(positions, iglu.File, iglu.File, "/data"), strings.Join(iglu.Messages, "%0A"))
 		}
 	}
 
@@ -66,7 +67,7 @@ func (vr *ValidationResults) GithubAnnotate() {
 	}
 
 	for f, ms := range byFile {
-		fmt.Printf("::error file=%s::", f)
+		fmt.Printf("::error %s::", ghFile(positions, f, f, "/data/self/version"))
 		for _, m := range ms {
 			fmt.Printf("%%0ASuggested version %s for %s%%0A%s", m.Suggested, m.Destination, strings.Join(m.Messages, "%0A"))
 		}
@@ -74,21 +75,21 @@ func (vr *ValidationResults) GithubAnnotate() {
 	}
 }
 
-func (vr *ValidationResults) Slog(ctx context.Context) {
+func (vr *ValidationResults) Slog(ctx context.Context, positions util.Positions) {
 	logger := logging.LoggerFromContext(ctx)
 	for _, iglu := range vr.Iglu {
 		switch iglu.Level {
 		case igluValidationError:
-			logger.Error("validation", "file", iglu.File, "messages", strings.Join(iglu.Messages, "\n"))
+			logger.Error("validation", "file", positions.Location(iglu.File, iglu.File, "/data"), "messages", strings.Join(iglu.Messages, "\n"))
 		case igluValidationWarn:
-			logger.Warn("validation", "file", iglu.File, "messages", strings.Join(iglu.Messages, "\n"))
+			logger.Warn("validation", "file", positions.Location(iglu.File, iglu.File, "/data"), "messages", strings.Join(iglu.Messages, "\n"))
 		case igluValidationInfo:
-			logger.Warn("validation", "file", iglu.File, "messages", strings.Join(iglu.Messages, "\n"))
+			logger.Warn("validation", "file", positions.Location(iglu.File, iglu.File, "/data"), "messages", strings.Join(iglu.Messages, "\n"))
 		}
 	}
 
 	for _, migration := range vr.Migration {
-		logger.Error("validation", "file", migration.File, "destination", migration.Destination,
+		logger.Error("validation", "file", positions.Location(migration.File, migration.File, "/data/self/version"), "destination", migration.Destination,
 			"suggestedVersion", migration.Suggested, "messages", strings.Join(migration.Messages, "\n"))
 	}
 }
@@ -0,0 +1,193 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package validation
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/snowplow/snowplow-cli/internal/lint"
+	"github.com/snowplow/snowplow-cli/internal/report"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// ReportOptions selects a machine readable report of the validation, no
+// report is written when Format is empty
+type ReportOptions struct {
+	Format report.Format
+	File   string
+}
+
+func ReportOptionsFromCmd(cmd *cobra.Command) (ReportOptions, error) {
+	format, _ := cmd.Flags().GetString("report-format")
+	file, _ := cmd.Flags().GetString("report-file")
+
+	if format == "" {
+		if file != "" {
+			return ReportOptions{}, fmt.Errorf("--report-file requires --report-format")
+		}
+		return ReportOptions{}, nil
+	}
+
+	f, err := report.ParseFormat(format)
+	if err != nil {
+		return ReportOptions{}, err
+	}
+	return ReportOptions{f, file}, nil
+}
+
+func (o ReportOptions) write(r report.Report) error {
+	if o.Format == "" {
+		return nil
+	}
+	return report.WriteFile(o.File, o.Format, r)
+}
+
+var rulePrefix = regexp.MustCompile(`^\[([^\]]+)\] `)
+
+// splitRule separates the rule or policy id validations are prefixed with
+func splitRule(msg string) (string, string) {
+	if m := rulePrefix.FindStringSubmatch(msg); m != nil {
+		return m[1], strings.TrimPrefix(msg, m[0])
+	}
+	return "", msg
+}
+
+func positionOf(positions util.Positions, fileName string, pointer string) (int, int) {
+	pos, ok := positions.Lookup(fileName, pointer)
+	if !ok {
+		return 0, 0
+	}
+	return pos.Line, pos.Column
+}
+
+// Report converts the validations to report issues, file names are
+// relative to basePath and pointers are resolved to line and column
+func (lookup *DPLookup) Report(basePath string) (report.Report, error) {
+	r := report.Report{Tool: "snowplow-cli dp validate"}
+
+	var files []string
+	for f := range lookup.Validations {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+
+	for _, f := range files {
+		v := lookup.Validations[f]
+		rp, err := filepath.Rel(basePath, f)
+		if err != nil {
+			return r, err
+		}
+		r.Files = append(r.Files, rp)
+
+		add := func(severity report.Severity, pointer string, msg string) {
+			line, col := 0, 0
+			if pointer != "" {
+				line, col = positionOf(lookup.Positions, f, pointer)
+			}
+			rule, msg := splitRule(msg)
+			if pointer != "" {
+				msg = fmt.Sprintf("%s: %s", pointer, msg)
+			}
+			r.Issues = append(r.Issues, report.Issue{File: rp, Line: line, Column: col, Severity: severity, Rule: rule, Message: msg})
+		}
+
+		for _, m := range v.Errors {
+			add(report.SeverityError, "", m)
+		}
+		for _, m := range v.Warnings {
+			add(report.SeverityWarning, "", m)
+		}
+		for _, m := range v.Info {
+			add(report.SeverityNote, "", m)
+		}
+		for _, k := range sortedKeys(v.ErrorsWithPaths) {
+			for _, m := range v.ErrorsWithPaths[k] {
+				add(report.SeverityError, k, m)
+			}
+		}
+		for _, k := range sortedKeys(v.WarningsWithPaths) {
+			for _, m := range v.WarningsWithPaths[k] {
+				add(report.SeverityWarning, k, m)
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// dsReport converts lint findings and console validation results of data
+// structures to report issues
+func dsReport(fileNames []string, positions util.Positions, findings []lint.Finding, vr *ValidationResults) report.Report {
+	r := report.Report{Tool: "snowplow-cli ds validate", Files: fileNames}
+
+	for _, f := range findings {
+		severity := report.SeverityNote
+		switch f.Severity {
+		case lint.SeverityError:
+			severity = report.SeverityError
+		case lint.SeverityWarning:
+			severity = report.SeverityWarning
+		}
+		line, col := 0, 0
+		msg := f.Message
+		if f.Path != "" {
+			line, col = positionOf(positions, f.File, f.Path)
+			msg = fmt.Sprintf("%s: %s", f.Path, f.Message)
+		}
+		r.Issues = append(r.Issues, report.Issue{File: f.File, Line: line, Column: col, Severity: severity, Rule: f.Rule, Message: msg})
+	}
+
+	if vr == nil {
+		return r
+	}
+
+	for _, iglu := range vr.Iglu {
+		severity := report.SeverityNote
+		switch iglu.Level {
+		case igluValidationError:
+			severity = report.SeverityError
+		case igluValidationWarn:
+			severity = report.SeverityWarning
+		}
+		line, col := positionOf(positions, iglu.File, "/data")
+		for _, m := range iglu.Messages {
+			r.Issues = append(r.Issues, report.Issue{File: iglu.File, Line: line, Column: col, Severity: severity, Rule: "iglu", Message: m})
+		}
+	}
+
+	for _, m := range vr.Migration {
+		line, col := positionOf(positions, m.File, "/data/self/version")
+		r.Issues = append(r.Issues, report.Issue{
+			File:     m.File,
+			Line:     line,
+			Column:   col,
+			Severity: report.SeverityError,
+			Rule:     "migration",
+			Message:  fmt.Sprintf("suggested version %s for %s\n%s", m.Suggested, m.Destination, strings.Join(m.Messages, "\n")),
+		})
+	}
+
+	return r
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -12,8 +12,10 @@ package validation
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/lint"
 	"github.com/snowplow/snowplow-cli/internal/logging"
 	"github.com/snowplow/snowplow-cli/internal/release"
 	"github.com/snowplow/snowplow-cli/internal/util"
@@ -28,19 +30,84 @@ func ValidateDataProductsFromCmd(ctx context.Context, cmd *cobra.Command, paths
 	ghOut, _ := cmd.Flags().GetBool("gh-annotate")
 	full, _ := cmd.Flags().GetBool("full")
 	concurrentReq, _ := cmd.Flags().GetInt("concurrency")
+	changedSince, _ := cmd.Flags().GetString("changed-since")
+	overlay, _ := cmd.Flags().GetString("overlay")
+
+	reportOptions, err := ReportOptionsFromCmd(cmd)
+	if err != nil {
+		return err
+	}
+
+	vars, err := util.TemplateVarsFromCmd(cmd)
+	if err != nil {
+		return err
+	}
 
 	c, err := console.NewApiClient(ctx, host, apiKeyId, apiKeySecret, org)
 	if err != nil {
 		return err
 	}
 
-	return ValidateDataProductsWithClient(ctx, c, paths, basePath, ghOut, full, concurrentReq)
+	lintConfig, err := LintConfigFromCmd(cmd)
+	if err != nil {
+		return err
+	}
+
+	watch := WatchOptionsFromCmd(cmd)
+	if watch.Enabled {
+		if changedSince != "" {
+			return fmt.Errorf("--changed-since can not be used with --watch")
+		}
+		if overlay != "" {
+			return fmt.Errorf("--overlay can not be used with --watch")
+		}
+	}
+
+	opts := DataProductsOptions{
+		BasePath:     basePath,
+		GhOut:        ghOut,
+		Full:         full,
+		Concurrency:  concurrentReq,
+		LintConfig:   lintConfig,
+		Report:       reportOptions,
+		ChangedSince: changedSince,
+		Overlay:      overlay,
+		TemplateVars: vars,
+	}
+
+	if watch.Enabled {
+		return WatchDataProducts(ctx, c, paths, opts, watch.Interval)
+	}
+
+	return ValidateDataProductsWithClient(ctx, c, paths, opts)
 }
 
-func ValidateDataProductsWithClient(ctx context.Context, client *console.ApiClient, paths []string, basePath string, ghOut bool, full bool, concurrentReq int) error {
+// DataProductsOptions are the settings of a data product validation
+type DataProductsOptions struct {
+	// file names are reported relative to BasePath
+	BasePath string
+	// print github workflow annotations
+	GhOut bool
+	// check the compatibility of every data product, not only the changed ones
+	Full        bool
+	Concurrency int
+	LintConfig  lint.Config
+	Report      ReportOptions
+	// only validate the files changed in Git since this ref and the data
+	// products depending on them
+	ChangedSince string
+	// merge this overlay into the files first, see util.ApplyOverlay
+	Overlay string
+	// variables of the templated files
+	TemplateVars map[string]any
+}
+
+// ValidateDataProductsWithClient validates the files under paths
+func ValidateDataProductsWithClient(ctx context.Context, client *console.ApiClient, paths []string, opts DataProductsOptions) error {
 	logger := logging.LoggerFromContext(ctx)
 
 	searchPaths := paths
+	concurrentReq := opts.Concurrency
 
 	if concurrentReq > 10 {
 		concurrentReq = 10
@@ -52,15 +119,32 @@ func ValidateDataProductsWithClient(ctx context.Context, client *console.ApiClie
 		logger.Debug("validation", "msg", "concurrency set to < 1, increased to 1")
 	}
 
-	files, err := util.MaybeResourcesfromPaths(searchPaths)
+	files, positions, err := util.MaybeResourcesfromPaths(searchPaths, opts.TemplateVars)
 	if err != nil {
 		return err
 	}
 
-	changes, err := release.FindChanges(ctx, client, files)
+	var patches map[string][]string
+	if opts.Overlay != "" {
+		patches, err = util.ApplyOverlay(files, util.OverlayDir(opts.Overlay), opts.TemplateVars)
+		if err != nil {
+			return err
+		}
+	}
+
+	var affected map[string]bool
+	if opts.ChangedSince != "" {
+		affected, err = release.ChangedSince(files, opts.ChangedSince, patches)
+		if err != nil {
+			return err
+		}
+	}
+
+	changes, err := release.FindChanges(ctx, client, files, release.Filter{Files: affected})
 	if err != nil {
 		return err
 	}
 
-	return Validate(ctx, client, files, searchPaths, basePath, ghOut, full, changes.IdToFileName, concurrentReq)
+	opts.Concurrency = concurrentReq
+	return Validate(ctx, client, files, positions, searchPaths, changes.IdToFileName, affected, opts)
 }
@@ -39,7 +39,7 @@ func TestValidateDataProductsWithClient_HappyPath(t *testing.T) {
 
 	mockClient := createMockDataProductClient(&MockDataProductSuccessTransport{})
 
-	err := ValidateDataProductsWithClient(ctx, mockClient, []string{tmpDir}, tmpDir, false, false, 1)
+	err := ValidateDataProductsWithClient(ctx, mockClient, []string{tmpDir}, DataProductsOptions{BasePath: tmpDir, Concurrency: 1})
 
 	if err != nil {
 		t.Errorf("Expected validation to succeed, but got error: %v", err)
@@ -63,7 +63,7 @@ func TestValidateDataProductsWithClient_LocalValidationFailure(t *testing.T) {
 
 	mockClient := createMockDataProductClient(&MockDataProductSuccessTransport{})
 
-	err := ValidateDataProductsWithClient(ctx, mockClient, []string{tmpDir}, tmpDir, false, false, 1)
+	err := ValidateDataProductsWithClient(ctx, mockClient, []string{tmpDir}, DataProductsOptions{BasePath: tmpDir, Concurrency: 1})
 
 	if err == nil {
 		t.Error("Expected validation to fail for invalid data product")
@@ -92,7 +92,7 @@ func TestValidateDataProductsWithClient_NetworkFailure(t *testing.T) {
 
 	mockClient := createMockDataProductClient(&MockDataProductNetworkFailureTransport{})
 
-	err := ValidateDataProductsWithClient(ctx, mockClient, []string{tmpDir}, tmpDir, false, false, 1)
+	err := ValidateDataProductsWithClient(ctx, mockClient, []string{tmpDir}, DataProductsOptions{BasePath: tmpDir, Concurrency: 1})
 
 	if err == nil {
 		t.Error("Expected validation to fail due to network error")
@@ -115,7 +115,7 @@ func TestValidateDataProductsWithClient_CompatibilityCheckFailure(t *testing.T)
 
 	mockClient := createMockDataProductClient(&MockDataProductCompatFailureTransport{})
 
-	err := ValidateDataProductsWithClient(ctx, mockClient, []string{tmpDir}, tmpDir, false, true, 1)
+	err := ValidateDataProductsWithClient(ctx, mockClient, []string{tmpDir}, DataProductsOptions{BasePath: tmpDir, Full: true, Concurrency: 1})
 
 	if err == nil {
 		logStr := logOutput.String()
@@ -136,7 +136,7 @@ func TestValidateDataProductsWithClient_GitHubAnnotations(t *testing.T) {
 
 	mockClient := createMockDataProductClient(&MockDataProductWarningTransport{})
 
-	err := ValidateDataProductsWithClient(ctx, mockClient, []string{tmpDir}, tmpDir, true, false, 1)
+	err := ValidateDataProductsWithClient(ctx, mockClient, []string{tmpDir}, DataProductsOptions{BasePath: tmpDir, GhOut: true, Concurrency: 1})
 
 	if err != nil {
 		t.Errorf("Expected validation to succeed with warnings, but got error: %v", err)
@@ -156,7 +156,7 @@ func TestValidateDataProductsWithClient_ConcurrencyLimits(t *testing.T) {
 
 	mockClient := createMockDataProductClient(&MockDataProductSuccessTransport{})
 
-	err := ValidateDataProductsWithClient(ctx, mockClient, []string{tmpDir}, tmpDir, false, false, 20)
+	err := ValidateDataProductsWithClient(ctx, mockClient, []string{tmpDir}, DataProductsOptions{BasePath: tmpDir, Concurrency: 20})
 
 	if err != nil {
 		t.Errorf("Expected validation to succeed, but got error: %v", err)
@@ -168,7 +168,7 @@ func TestValidateDataProductsWithClient_ConcurrencyLimits(t *testing.T) {
 	}
 
 	logOutput.Reset()
-	err = ValidateDataProductsWithClient(ctx, mockClient, []string{tmpDir}, tmpDir, false, false, 0)
+	err = ValidateDataProductsWithClient(ctx, mockClient, []string{tmpDir}, DataProductsOptions{BasePath: tmpDir, Concurrency: 0})
 
 	if err != nil {
 		t.Errorf("Expected validation to succeed, but got error: %v", err)
@@ -189,7 +189,7 @@ func TestValidateDataProductsWithClient_NoLoggerInContext(t *testing.T) {
 
 	mockClient := createMockDataProductClient(&MockDataProductSuccessTransport{})
 
-	err := ValidateDataProductsWithClient(ctx, mockClient, []string{tmpDir}, tmpDir, false, false, 1)
+	err := ValidateDataProductsWithClient(ctx, mockClient, []string{tmpDir}, DataProductsOptions{BasePath: tmpDir, Concurrency: 1})
 
 	t.Logf("Function completed with error: %v", err)
 }
@@ -13,10 +13,15 @@ package validation
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
 
 	"github.com/snowplow/snowplow-cli/internal/changes"
 	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/lint"
 	"github.com/snowplow/snowplow-cli/internal/logging"
+	"github.com/snowplow/snowplow-cli/internal/policy"
+	"github.com/snowplow/snowplow-cli/internal/report"
 	"github.com/snowplow/snowplow-cli/internal/util"
 	"github.com/spf13/cobra"
 )
@@ -28,15 +33,63 @@ func ValidateDataStructuresFromCmd(ctx context.Context, cmd *cobra.Command, path
 	org, _ := cmd.Flags().GetString("org-id")
 	ghOut, _ := cmd.Flags().GetBool("gh-annotate")
 
+	reportOptions, err := ReportOptionsFromCmd(cmd)
+	if err != nil {
+		return err
+	}
+
+	filter, err := DsFilterFromCmd(cmd)
+	if err != nil {
+		return err
+	}
+
 	c, err := console.NewApiClient(ctx, host, apiKeyId, apiKeySecret, org)
 	if err != nil {
 		return err
 	}
 
-	return ValidateDataStructuresWithClient(ctx, c, paths, ghOut)
+	lintConfig, err := LintConfigFromCmd(cmd)
+	if err != nil {
+		return err
+	}
+
+	opts := DataStructuresOptions{
+		GhOut:      ghOut,
+		LintConfig: lintConfig,
+		Report:     reportOptions,
+		Filter:     filter,
+	}
+
+	if watch := WatchOptionsFromCmd(cmd); watch.Enabled {
+		if len(paths) == 0 {
+			paths = []string{util.DataStructuresFolder}
+		}
+		return WatchDataStructures(ctx, c, paths, opts, watch.Interval)
+	}
+
+	return ValidateDataStructuresWithClient(ctx, c, paths, opts)
+}
+
+// DataStructuresOptions are the settings of a data structure validation
+type DataStructuresOptions struct {
+	// print github workflow annotations
+	GhOut      bool
+	LintConfig lint.Config
+	Report     ReportOptions
+	// the data structures to validate, the others are still read
+	Filter changes.Filter
 }
 
-func ValidateDataStructuresWithClient(ctx context.Context, client *console.ApiClient, paths []string, ghOut bool) error {
+// DsFilterFromCmd reads the data structure selection of the --match,
+// --exclude and --changed-since flags
+func DsFilterFromCmd(cmd *cobra.Command) (changes.Filter, error) {
+	match, _ := cmd.Flags().GetStringArray("match")
+	exclude, _ := cmd.Flags().GetStringArray("exclude")
+	changedSince, _ := cmd.Flags().GetString("changed-since")
+	return changes.NewFilter(match, exclude, changedSince)
+}
+
+func ValidateDataStructuresWithClient(ctx context.Context, client *console.ApiClient, paths []string, opts DataStructuresOptions) error {
 	logger := logging.LoggerFromContext(ctx)
 
 	dataStructureFolders := []string{util.DataStructuresFolder}
@@ -44,18 +97,42 @@ func ValidateDataStructuresWithClient(ctx context.Context, client *console.ApiCl
 		dataStructureFolders = paths
 	}
 
-	dataStructuresLocal, err := util.DataStructuresFromPaths(dataStructureFolders)
+	dataStructuresLocal, positions, err := util.DataStructuresFromPaths(dataStructureFolders)
 	logger.Info("validating from", "paths", dataStructureFolders)
 	if err != nil {
 		return err
 	}
 
+	var fileNames []string
+	for f := range dataStructuresLocal {
+		fileNames = append(fileNames, f)
+	}
+	sort.Strings(fileNames)
+
 	errs := ValidateLocalDs(dataStructuresLocal)
 	if len(errs) > 0 {
 		logger.Error("validation", "error", errs)
+		r := dsReport(fileNames, positions, nil, nil)
+		for _, err := range errs {
+			r.Issues = append(r.Issues, report.Issue{Severity: report.SeverityError, Message: err.Error()})
+		}
+		if err := opts.Report.write(r); err != nil {
+			return err
+		}
 		return errors.Join(errs...)
 	}
 
+	// unselected data structures are still needed to compute the changes
+	selected, err := opts.Filter.Locals(dataStructuresLocal)
+	if err != nil {
+		return err
+	}
+	findings, err := opts.LintConfig.Run(selected)
+	if err != nil {
+		return err
+	}
+	lintErrors := LogLintFindings(ctx, findings, positions, opts.GhOut)
+
 	remotesListing, err := console.GetDataStructureListing(ctx, client)
 	if err != nil {
 		return err
@@ -65,6 +142,10 @@ func ValidateDataStructuresWithClient(ctx context.Context, client *console.ApiCl
 	if err != nil {
 		return err
 	}
+	changed, err = opts.Filter.Apply(changed)
+	if err != nil {
+		return err
+	}
 
 	err = changes.PrintChangeset(ctx, changed)
 	if err != nil {
@@ -76,15 +157,83 @@ func ValidateDataStructuresWithClient(ctx context.Context, client *console.ApiCl
 		return err
 	}
 
-	vr.Slog(ctx)
+	vr.Slog(ctx, positions)
+
+	if opts.GhOut {
+		vr.GithubAnnotate(positions)
+	}
 
-	if ghOut {
-		vr.GithubAnnotate()
+	if err := opts.Report.write(dsReport(fileNames, positions, findings, vr)); err != nil {
+		return err
 	}
 
 	if !vr.Valid {
 		return errors.New(vr.Message)
 	}
 
+	if lintErrors > 0 {
+		return fmt.Errorf("%d lint errors", lintErrors)
+	}
+
 	return nil
 }
+
+// LintConfigFromCmd loads the lint configuration of the --lint-config flag,
+// lint.ConfigFile when empty, with the policies of --policies when set
+func LintConfigFromCmd(cmd *cobra.Command) (lint.Config, error) {
+	lintConfigFile, _ := cmd.Flags().GetString("lint-config")
+	policiesDir, _ := cmd.Flags().GetString("policies")
+
+	if lintConfigFile == "" {
+		lintConfigFile = lint.ConfigFile
+	}
+	lintConfig, err := lint.LoadConfig(lintConfigFile)
+	if err != nil {
+		return lintConfig, err
+	}
+	if policiesDir != "" {
+		lintConfig.Policies, err = policy.LoadDir(policiesDir)
+		if err != nil {
+			return lintConfig, err
+		}
+	}
+	return lintConfig, nil
+}
+
+// LogLintFindings logs every finding at the level of its severity and
+// returns the number of errors
+func LogLintFindings(ctx context.Context, findings []lint.Finding, positions util.Positions, ghOut bool) int {
+	logger := logging.LoggerFromContext(ctx)
+	errorCount := 0
+	for _, f := range findings {
+		file := f.File
+		if f.Path != "" {
+			file = positions.Location(f.File, f.File, f.Path)
+		}
+		switch f.Severity {
+		case lint.SeverityError:
+			errorCount++
+			logger.Error("lint", "file", file, "rule", f.Rule, "path", f.Path, "msg", f.Message)
+		case lint.SeverityWarning:
+			logger.Warn("lint", "file", file, "rule", f.Rule, "path", f.Path, "msg", f.Message)
+		default:
+			logger.Info("lint", "file", file, "rule", f.Rule, "path", f.Path, "msg", f.Message)
+		}
+
+		if ghOut {
+			level := "notice"
+			switch f.Severity {
+			case lint.SeverityError:
+				level = "error"
+			case lint.SeverityWarning:
+				level = "warning"
+			}
+			ghf := fmt.Sprintf("file=%s", f.File)
+			if f.Path != "" {
+				ghf = ghFile(positions, f.File, f.File, f.Path)
+			}
+			fmt.Printf("::%s %s::%s\n", level, ghf, f.String())
+		}
+	}
+	return errorCount
+}
@@ -38,7 +38,7 @@ func TestValidateDataStructuresWithClient_HappyPath(t *testing.T) {
 
 	mockClient := createMockClient(&MockSuccessfulTransport{})
 
-	err := ValidateDataStructuresWithClient(ctx, mockClient, []string{tmpDir}, false)
+	err := ValidateDataStructuresWithClient(ctx, mockClient, []string{tmpDir}, DataStructuresOptions{})
 
 	if err != nil {
 		t.Errorf("Expected validation to succeed, but got error: %v", err)
@@ -65,7 +65,7 @@ func TestValidateDataStructuresWithClient_LocalValidationFailure(t *testing.T) {
 
 	mockClient := createMockClient(&MockClientThatShouldNotBeCalledTransport{t: t})
 
-	err := ValidateDataStructuresWithClient(ctx, mockClient, []string{tmpDir}, false)
+	err := ValidateDataStructuresWithClient(ctx, mockClient, []string{tmpDir}, DataStructuresOptions{})
 
 	if err == nil {
 		t.Error("Expected validation to fail for invalid data structure")
@@ -93,7 +93,7 @@ func TestValidateDataStructuresWithClient_NetworkFailure(t *testing.T) {
 
 	mockClient := createMockClient(&MockNetworkFailureTransport{})
 
-	err := ValidateDataStructuresWithClient(ctx, mockClient, []string{tmpDir}, false)
+	err := ValidateDataStructuresWithClient(ctx, mockClient, []string{tmpDir}, DataStructuresOptions{})
 
 	if err == nil {
 		t.Error("Expected validation to fail due to network error")
@@ -120,7 +120,7 @@ func TestValidateDataStructuresWithClient_RemoteValidationFailure(t *testing.T)
 
 	mockClient := createMockClient(&MockRemoteValidationFailureTransport{})
 
-	err := ValidateDataStructuresWithClient(ctx, mockClient, []string{tmpDir}, false)
+	err := ValidateDataStructuresWithClient(ctx, mockClient, []string{tmpDir}, DataStructuresOptions{})
 
 	if err == nil {
 		t.Error("Expected validation to fail due to remote validation error")
@@ -143,7 +143,7 @@ func TestValidateDataStructuresWithClient_GitHubAnnotations(t *testing.T) {
 
 	mockClient := createMockClient(&MockSuccessfulTransport{})
 
-	err := ValidateDataStructuresWithClient(ctx, mockClient, []string{tmpDir}, true)
+	err := ValidateDataStructuresWithClient(ctx, mockClient, []string{tmpDir}, DataStructuresOptions{GhOut: true})
 
 	if err != nil {
 		t.Errorf("Expected validation to succeed, but got error: %v", err)
@@ -159,7 +159,7 @@ func TestValidateDataStructuresWithClient_EmptyPaths(t *testing.T) {
 
 	mockClient := createMockClient(&MockNetworkFailureTransport{})
 
-	err := ValidateDataStructuresWithClient(ctx, mockClient, []string{}, false)
+	err := ValidateDataStructuresWithClient(ctx, mockClient, []string{}, DataStructuresOptions{})
 
 	if err == nil {
 		t.Log("Unexpectedly succeeded - default data-structures folder must exist")
@@ -182,7 +182,7 @@ func TestValidateDataStructuresWithClient_NoLoggerInContext(t *testing.T) {
 
 	mockClient := createMockClient(&MockSuccessfulTransport{})
 
-	err := ValidateDataStructuresWithClient(ctx, mockClient, []string{tmpDir}, false)
+	err := ValidateDataStructuresWithClient(ctx, mockClient, []string{tmpDir}, DataStructuresOptions{})
 
 	t.Logf("Function completed with error: %v", err)
 }
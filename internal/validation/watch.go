@@ -0,0 +1,474 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/snowplow/snowplow-cli/internal/changes"
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/release"
+	"github.com/snowplow/snowplow-cli/internal/report"
+	"github.com/snowplow/snowplow-cli/internal/util"
+	"github.com/snowplow/snowplow-cli/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+// WatchOptions enable validating again whenever the validated files change
+type WatchOptions struct {
+	Enabled  bool
+	Interval time.Duration
+}
+
+func WatchOptionsFromCmd(cmd *cobra.Command) WatchOptions {
+	enabled, _ := cmd.Flags().GetBool("watch")
+	interval, _ := cmd.Flags().GetDuration("watch-interval")
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return WatchOptions{enabled, interval}
+}
+
+// schemaResolverTTL is how long a watch session relies on the deployed data
+// structures it fetched, publishing meanwhile is seen by the next run after
+const schemaResolverTTL = time.Minute
+
+// dpWatch keeps the console lookups and the validations of previous runs
+// of a data product watch session
+type dpWatch struct {
+	out   io.Writer
+	paths []string
+	opts  DataProductsOptions
+
+	compat            console.CompatChecker
+	newSchemaResolver func() (console.SchemaDeployChecker, error)
+	schemaResolver    console.SchemaDeployChecker
+	schemaResolvedAt  time.Time
+	// files changed compared to the console when the session started
+	remoteChanged map[string]bool
+	// files changed during the session
+	edited  map[string]bool
+	results map[string]DPValidations
+}
+
+// WatchDataProducts validates the data products and source applications
+// under paths, then again whenever their files change until interrupted.
+// Compatibility checks are cached and deployed data structures fetched
+// again after schemaResolverTTL, a change only validates the changed files
+// and the data products referencing them
+func WatchDataProducts(ctx context.Context, client *console.ApiClient, paths []string, opts DataProductsOptions, interval time.Duration) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	files, _, err := util.MaybeResourcesfromPaths(paths, opts.TemplateVars)
+	if err != nil {
+		return err
+	}
+	pollPaths := paths
+	if opts.Overlay != "" {
+		if _, err := util.ApplyOverlay(files, util.OverlayDir(opts.Overlay), opts.TemplateVars); err != nil {
+			return err
+		}
+		pollPaths = append(slices.Clone(paths), util.OverlayDir(opts.Overlay))
+	}
+
+	changes, err := release.FindChanges(ctx, client, files, release.Filter{})
+	if err != nil {
+		return err
+	}
+
+	opts.Concurrency = min(max(opts.Concurrency, 1), 10)
+	w := &dpWatch{
+		out:   os.Stdout,
+		paths: paths,
+		opts:  opts,
+		compat: cachedCompatChecker(func(event console.CompatCheckable, entities []console.CompatCheckable) (*console.CompatResult, error) {
+			return console.CompatCheck(ctx, client, event, entities)
+		}),
+		newSchemaResolver: func() (console.SchemaDeployChecker, error) {
+			return console.NewSchemaDeployChecker(ctx, client)
+		},
+		remoteChanged: map[string]bool{},
+		edited:        map[string]bool{},
+		results:       map[string]DPValidations{},
+	}
+	if _, err := w.resolver(); err != nil {
+		return err
+	}
+	for _, f := range changes.IdToFileName {
+		w.remoteChanged[f] = true
+	}
+
+	w.run(nil)
+	watch.Poll(ctx, pollPaths, interval, w.run)
+
+	return nil
+}
+
+// run validates the files affected by changed, every file when nil
+func (w *dpWatch) run(changed []string) {
+	files, positions, err := util.MaybeResourcesfromPaths(w.paths, w.opts.TemplateVars)
+	if err != nil {
+		renderWatch(w.out, "dp validate", w.paths, report.Report{}, 0, err)
+		return
+	}
+	if w.opts.Overlay != "" {
+		patches, err := util.ApplyOverlay(files, util.OverlayDir(w.opts.Overlay), w.opts.TemplateVars)
+		if err != nil {
+			renderWatch(w.out, "dp validate", w.paths, report.Report{}, 0, err)
+			return
+		}
+		changed = withPatchedFiles(changed, patches)
+	}
+
+	for _, f := range changed {
+		w.edited[f] = true
+	}
+	affected := affectedDpFiles(files, changed)
+
+	compatFiles := map[string]string{}
+	for f := range affected {
+		if w.opts.Full || w.edited[f] || w.remoteChanged[f] {
+			compatFiles[f] = f
+		}
+	}
+
+	schemaResolver, err := w.resolver()
+	if err != nil {
+		renderWatch(w.out, "dp validate", w.paths, report.Report{}, 0, err)
+		return
+	}
+
+	lookup, err := NewDPLookup(w.compat, schemaResolver, files, compatFiles, false, w.opts.Concurrency)
+	if err != nil {
+		renderWatch(w.out, "dp validate", w.paths, report.Report{}, 0, err)
+		return
+	}
+	lookup.Positions = positions
+	lookup.ValidateGovernance(w.opts.LintConfig)
+	lookup.ValidatePolicies(w.opts.LintConfig.Policies, files)
+
+	for f := range w.results {
+		if _, ok := files[f]; !ok {
+			delete(w.results, f)
+		}
+	}
+	validated := 0
+	for f := range affected {
+		if v, ok := lookup.Validations[f]; ok {
+			w.results[f] = v
+			validated++
+		} else {
+			delete(w.results, f)
+		}
+	}
+	lookup.Validations = w.results
+
+	r, err := lookup.Report(w.opts.BasePath)
+	if err == nil {
+		err = w.opts.Report.write(r)
+	}
+	renderWatch(w.out, "dp validate", w.paths, r, validated, err)
+}
+
+// resolver is the schema deploy checker of a run, fetched again once older
+// than schemaResolverTTL
+func (w *dpWatch) resolver() (console.SchemaDeployChecker, error) {
+	if w.schemaResolver == nil || time.Since(w.schemaResolvedAt) > schemaResolverTTL {
+		r, err := w.newSchemaResolver()
+		if err != nil {
+			return nil, err
+		}
+		w.schemaResolver, w.schemaResolvedAt = r, time.Now()
+	}
+	return w.schemaResolver, nil
+}
+
+// affectedDpFiles are the changed files and the data products referencing
+// them, every file when changed is nil
+func affectedDpFiles(files map[string]map[string]any, changed []string) map[string]bool {
+	if changed == nil {
+		return release.AffectedFiles(files, func(string) bool { return true })
+	}
+	changedFiles := map[string]bool{}
+	for _, f := range changed {
+		changedFiles[f] = true
+	}
+	return release.AffectedFiles(files, func(fileName string) bool { return changedFiles[fileName] })
+}
+
+// withPatchedFiles adds the files patched by a changed overlay patch file
+// to changed
+func withPatchedFiles(changed []string, patches map[string][]string) []string {
+	if changed == nil {
+		return nil
+	}
+	for f, patchFiles := range patches {
+		if slices.ContainsFunc(patchFiles, func(pf string) bool {
+			abs, err := filepath.Abs(pf)
+			return err == nil && slices.Contains(changed, abs)
+		}) {
+			changed = append(changed, f)
+		}
+	}
+	return changed
+}
+
+// cachedCompatChecker remembers the result of every compatibility check, a
+// data product only needs checking again once its definitions change
+func cachedCompatChecker(cc console.CompatChecker) console.CompatChecker {
+	var mu sync.Mutex
+	cache := map[string]*console.CompatResult{}
+
+	return func(event console.CompatCheckable, entities []console.CompatCheckable) (*console.CompatResult, error) {
+		key, err := json.Marshal([]any{event, entities})
+		if err != nil {
+			return cc(event, entities)
+		}
+
+		mu.Lock()
+		result, ok := cache[string(key)]
+		mu.Unlock()
+		if ok {
+			return result, nil
+		}
+
+		result, err = cc(event, entities)
+		if err != nil {
+			return nil, err
+		}
+
+		mu.Lock()
+		cache[string(key)] = result
+		mu.Unlock()
+
+		return result, nil
+	}
+}
+
+type dsRemoteResults struct {
+	iglu      []igluValidation
+	migration []migrationValidation
+}
+
+// dsWatch keeps the console listing and the validations of previous runs
+// of a data structure watch session
+type dsWatch struct {
+	ctx    context.Context
+	client *console.ApiClient
+	out    io.Writer
+	paths  []string
+	opts   DataStructuresOptions
+
+	listing []console.ListResponse
+	results map[string]dsRemoteResults
+}
+
+// WatchDataStructures validates the data structures under paths, then
+// again whenever their files change until interrupted. The console listing
+// is fetched once, a change only sends the changed files for validation
+func WatchDataStructures(ctx context.Context, client *console.ApiClient, paths []string, opts DataStructuresOptions, interval time.Duration) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	listing, err := console.GetDataStructureListing(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	w := &dsWatch{
+		ctx:     ctx,
+		client:  client,
+		out:     os.Stdout,
+		paths:   paths,
+		opts:    opts,
+		listing: listing,
+		results: map[string]dsRemoteResults{},
+	}
+
+	w.run(nil)
+	watch.Poll(ctx, paths, interval, w.run)
+
+	return nil
+}
+
+// run validates the changed data structures, every one when nil
+func (w *dsWatch) run(changed []string) {
+	dss, positions, err := util.DataStructuresFromPaths(w.paths)
+	if err != nil {
+		renderWatch(w.out, "ds validate", w.paths, report.Report{}, 0, err)
+		return
+	}
+
+	var fileNames []string
+	for f := range dss {
+		fileNames = append(fileNames, f)
+	}
+	sort.Strings(fileNames)
+
+	if errs := ValidateLocalDs(dss); len(errs) > 0 {
+		r := dsReport(fileNames, positions, nil, nil)
+		for _, err := range errs {
+			r.Issues = append(r.Issues, report.Issue{Severity: report.SeverityError, Message: err.Error()})
+		}
+		renderWatch(w.out, "ds validate", w.paths, r, len(fileNames), w.opts.Report.write(r))
+		return
+	}
+
+	findings, err := w.opts.LintConfig.Run(dss)
+	if err != nil {
+		renderWatch(w.out, "ds validate", w.paths, report.Report{}, 0, err)
+		return
+	}
+
+	changedFiles := map[string]bool{}
+	for _, f := range changed {
+		changedFiles[f] = true
+	}
+	affected := func(fileName string) bool {
+		abs, err := filepath.Abs(fileName)
+		return changed == nil || err != nil || changedFiles[abs]
+	}
+
+	all, err := changes.GetChanges(dss, w.listing, "DEV")
+	if err == nil {
+		all, err = w.opts.Filter.Apply(all)
+	}
+	if err != nil {
+		renderWatch(w.out, "ds validate", w.paths, report.Report{}, 0, err)
+		return
+	}
+	vr, err := ValidateChanges(w.ctx, w.client, all.Keep(func(ds model.DSChangeContext) bool { return affected(ds.FileName) }))
+	if err != nil {
+		renderWatch(w.out, "ds validate", w.paths, report.Report{}, 0, err)
+		return
+	}
+
+	validated := 0
+	for f := range w.results {
+		if _, ok := dss[f]; !ok || affected(f) {
+			delete(w.results, f)
+		}
+	}
+	for _, f := range fileNames {
+		if affected(f) {
+			validated++
+		}
+	}
+	for _, i := range vr.Iglu {
+		r := w.results[i.File]
+		r.iglu = append(r.iglu, i)
+		w.results[i.File] = r
+	}
+	for _, m := range vr.Migration {
+		r := w.results[m.File]
+		r.migration = append(r.migration, m)
+		w.results[m.File] = r
+	}
+
+	combined := &ValidationResults{Valid: true}
+	for _, f := range fileNames {
+		combined.Iglu = append(combined.Iglu, w.results[f].iglu...)
+		combined.Migration = append(combined.Migration, w.results[f].migration...)
+	}
+
+	r := dsReport(fileNames, positions, findings, combined)
+	renderWatch(w.out, "ds validate", w.paths, r, validated, w.opts.Report.write(r))
+}
+
+// renderWatch clears the terminal and prints the issues of a run, one line
+// per issue grouped by file
+func renderWatch(out io.Writer, title string, paths []string, r report.Report, validated int, err error) {
+	if !color.NoColor {
+		fmt.Fprint(out, "\033[H\033[2J")
+	}
+
+	red := color.New(color.FgRed)
+	yellow := color.New(color.FgYellow)
+	cyan := color.New(color.FgCyan)
+	green := color.New(color.FgGreen)
+	bold := color.New(color.Bold)
+
+	fmt.Fprintf(out, "%s %s, validated %d of %d files\n\n", bold.Sprint(title), time.Now().Format(time.Kitchen), validated, len(r.Files))
+
+	byFile := map[string][]report.Issue{}
+	var files []string
+	for _, i := range r.Issues {
+		if _, ok := byFile[i.File]; !ok {
+			files = append(files, i.File)
+		}
+		byFile[i.File] = append(byFile[i.File], i)
+	}
+	sort.Strings(files)
+
+	errors, warnings := 0, 0
+	for _, f := range files {
+		issues := byFile[f]
+		sort.SliceStable(issues, func(a, b int) bool {
+			return issues[a].Line < issues[b].Line
+		})
+		if f != "" {
+			fmt.Fprintln(out, bold.Sprint(f))
+		}
+		for _, i := range issues {
+			pos := "-"
+			if i.Line > 0 {
+				pos = fmt.Sprintf("%d:%d", i.Line, i.Column)
+			}
+			var severity string
+			switch i.Severity {
+			case report.SeverityError:
+				errors++
+				severity = red.Sprintf("%-7s", "error")
+			case report.SeverityWarning:
+				warnings++
+				severity = yellow.Sprintf("%-7s", "warning")
+			default:
+				severity = cyan.Sprintf("%-7s", "info")
+			}
+			msg := i.Message
+			if i.Rule != "" {
+				msg = fmt.Sprintf("[%s] %s", i.Rule, msg)
+			}
+			msg = strings.ReplaceAll(strings.TrimSpace(msg), "\n", "\n"+strings.Repeat(" ", 20))
+			fmt.Fprintf(out, "  %8s  %s  %s\n", pos, severity, msg)
+		}
+		fmt.Fprintln(out)
+	}
+
+	switch {
+	case err != nil:
+		fmt.Fprintln(out, red.Sprintf("error: %s", err))
+	case errors > 0 || warnings > 0:
+		summary := fmt.Sprintf("%d errors, %d warnings", errors, warnings)
+		if errors > 0 {
+			fmt.Fprintln(out, red.Sprint(summary))
+		} else {
+			fmt.Fprintln(out, yellow.Sprint(summary))
+		}
+	default:
+		fmt.Fprintln(out, green.Sprint("no problems found"))
+	}
+	fmt.Fprintf(out, "watching %s for changes, ctrl-c to stop\n", strings.Join(paths, ", "))
+}
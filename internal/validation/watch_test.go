@@ -0,0 +1,230 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package validation
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/snowplow/snowplow-cli/internal/console"
+	"github.com/snowplow/snowplow-cli/internal/lint"
+	"github.com/snowplow/snowplow-cli/internal/model"
+	"github.com/snowplow/snowplow-cli/internal/policy"
+	"github.com/snowplow/snowplow-cli/internal/report"
+)
+
+func Test_CachedCompatChecker(t *testing.T) {
+	calls := 0
+	cc := cachedCompatChecker(func(event console.CompatCheckable, entities []console.CompatCheckable) (*console.CompatResult, error) {
+		calls++
+		if event.Source == "broken" {
+			return nil, errors.New("unavailable")
+		}
+		return &console.CompatResult{Status: "compatible"}, nil
+	})
+
+	event := console.CompatCheckable{Source: "iglu:com.acme/checkout/jsonschema/1-0-0"}
+	for range 2 {
+		if res, err := cc(event, nil); err != nil || res.Status != "compatible" {
+			t.Fatalf("unexpected result %v %v", res, err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected a single check got %d", calls)
+	}
+
+	if _, err := cc(event, []console.CompatCheckable{{Source: "iglu:com.acme/user/jsonschema/1-0-0"}}); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected entities to be checked separately got %d calls", calls)
+	}
+
+	broken := console.CompatCheckable{Source: "broken"}
+	cc(broken, nil)
+	cc(broken, nil)
+	if calls != 4 {
+		t.Fatalf("expected failed checks not to be cached got %d calls", calls)
+	}
+}
+
+type stubResolver struct{}
+
+func (stubResolver) IsDSDeployed(uri string) (bool, []string, error) {
+	return true, nil, nil
+}
+
+func (stubResolver) DeprecatedMeta(uri string) (model.DataStructureMeta, bool) {
+	return model.DataStructureMeta{}, false
+}
+
+func Test_DpWatchResolver(t *testing.T) {
+	fetches := 0
+	fail := false
+	w := &dpWatch{newSchemaResolver: func() (console.SchemaDeployChecker, error) {
+		fetches++
+		if fail {
+			return nil, errors.New("unavailable")
+		}
+		return stubResolver{}, nil
+	}}
+
+	for range 2 {
+		if _, err := w.resolver(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fetches != 1 {
+		t.Fatalf("expected deployments fetched once got %d", fetches)
+	}
+
+	w.schemaResolvedAt = time.Now().Add(-2 * schemaResolverTTL)
+	if _, err := w.resolver(); err != nil || fetches != 2 {
+		t.Fatalf("expected deployments fetched again once expired got %d %v", fetches, err)
+	}
+
+	w.schemaResolvedAt = time.Now().Add(-2 * schemaResolverTTL)
+	fail = true
+	if _, err := w.resolver(); err == nil {
+		t.Fatal("expected the fetch error")
+	}
+}
+
+func Test_DpWatchOverlay(t *testing.T) {
+	dir := t.TempDir()
+	sa := filepath.Join(dir, "source-apps", "web.yaml")
+	patch := filepath.Join(dir, "overlays", "prod", "web.yaml")
+	for f, content := range map[string]string{
+		sa: `apiVersion: v1
+resourceType: source-application
+resourceName: 3e7f5b5c-1c6a-4d6e-9d3e-2a1b4c5d6e7f
+data:
+  name: web
+  appIds: [web]
+  entities:
+    tracked: []
+    enriched: []
+`,
+		patch: `apiVersion: v1
+resourceType: source-application
+resourceName: 3e7f5b5c-1c6a-4d6e-9d3e-2a1b4c5d6e7f
+data:
+  owner: team@acme.com
+`,
+	} {
+		if err := os.MkdirAll(filepath.Dir(f), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(f, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	owner, err := policy.Load([]byte("expression: has(data.owner)\nmessage: owner is required"), "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	violations := func(overlay string) []string {
+		w := &dpWatch{
+			out:   &bytes.Buffer{},
+			paths: []string{filepath.Join(dir, "source-apps")},
+			opts:  DataProductsOptions{Overlay: overlay, LintConfig: lint.Config{Policies: []policy.Policy{owner}}},
+			newSchemaResolver: func() (console.SchemaDeployChecker, error) {
+				return stubResolver{}, nil
+			},
+			remoteChanged: map[string]bool{},
+			edited:        map[string]bool{},
+			results:       map[string]DPValidations{},
+		}
+		w.run(nil)
+		return w.results[sa].ErrorsWithPaths[policy.Path]
+	}
+
+	if !slices.Contains(violations(""), "[owner] owner is required") {
+		t.Fatal("expected the policy violation without the overlay")
+	}
+	if slices.Contains(violations(filepath.Join(dir, "overlays", "prod")), "[owner] owner is required") {
+		t.Fatal("expected the overlay to be applied")
+	}
+
+	changed := withPatchedFiles([]string{patch}, map[string][]string{sa: {patch}})
+	if !slices.Contains(changed, sa) {
+		t.Fatalf("expected a changed patch file to change the file it patches got %v", changed)
+	}
+}
+
+func Test_AffectedDpFiles(t *testing.T) {
+	dir := t.TempDir()
+	sa := filepath.Join(dir, "source-apps", "web.yaml")
+	dp := filepath.Join(dir, "data-products", "checkout.yaml")
+	other := filepath.Join(dir, "data-products", "signup.yaml")
+
+	files := map[string]map[string]any{
+		sa: {"resourceType": "source-application"},
+		dp: {"resourceType": "data-product", "data": map[string]any{
+			"sourceApplications": []any{map[string]any{"$ref": "../source-apps/web.yaml"}},
+		}},
+		other: {"resourceType": "data-product", "data": map[string]any{}},
+	}
+
+	affected := affectedDpFiles(files, []string{sa})
+	if !affected[sa] || !affected[dp] || affected[other] || len(affected) != 2 {
+		t.Fatalf("unexpected affected files %v", affected)
+	}
+
+	affected = affectedDpFiles(files, []string{other})
+	if !affected[other] || len(affected) != 1 {
+		t.Fatalf("unexpected affected files %v", affected)
+	}
+
+	if affected := affectedDpFiles(files, nil); len(affected) != 3 {
+		t.Fatalf("expected every file on the first run got %v", affected)
+	}
+}
+
+func Test_RenderWatch(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	r := report.Report{
+		Files: []string{"data-products/checkout.yaml", "data-products/signup.yaml"},
+		Issues: []report.Issue{
+			{File: "data-products/checkout.yaml", Line: 12, Column: 7, Severity: report.SeverityWarning, Rule: "dp-owner-required", Message: "/data/owner: owner is required"},
+			{File: "data-products/checkout.yaml", Severity: report.SeverityError, Message: "something is wrong"},
+		},
+	}
+
+	var out bytes.Buffer
+	renderWatch(&out, "dp validate", []string{"data-products"}, r, 1, nil)
+
+	for _, expected := range []string{
+		"validated 1 of 2 files",
+		"data-products/checkout.yaml\n         -  error    something is wrong\n      12:7  warning  [dp-owner-required] /data/owner: owner is required\n",
+		"1 errors, 1 warnings",
+		"watching data-products for changes",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Fatalf("expected %q in\n%s", expected, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "\033[H") {
+		t.Fatal("expected no clearing without colors")
+	}
+}
@@ -0,0 +1,360 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package warehouse
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+type Dialect string
+
+const (
+	Snowflake  Dialect = "snowflake"
+	BigQuery   Dialect = "bigquery"
+	Databricks Dialect = "databricks"
+	Redshift   Dialect = "redshift"
+	Postgres   Dialect = "postgres"
+)
+
+var Dialects = []Dialect{Snowflake, BigQuery, Databricks, Redshift, Postgres}
+
+func ParseDialect(s string) (Dialect, error) {
+	d := Dialect(strings.ToLower(s))
+	if !slices.Contains(Dialects, d) {
+		return "", fmt.Errorf("unsupported dialect %s. Supported are snowflake, bigquery, databricks, redshift, postgres", s)
+	}
+	return d, nil
+}
+
+// isShredded reports whether the loader for the dialect writes each data
+// structure to its own table instead of a column of the events table.
+func (d Dialect) isShredded() bool {
+	return d == Redshift || d == Postgres
+}
+
+type Field struct {
+	// Key is the property name as defined in the schema
+	Key      string
+	Name     string
+	Schema   map[string]any
+	Required bool
+	Children []Field
+}
+
+type Structure struct {
+	Self     model.DataStructureSelf
+	IsEntity bool
+	Fields   []Field
+}
+
+var (
+	camelBoundary = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+	nonAlnum      = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+)
+
+// SnakeCase normalizes names the same way the Snowplow loaders do
+func SnakeCase(s string) string {
+	s = camelBoundary.ReplaceAllString(s, "${1}_${2}")
+	s = nonAlnum.ReplaceAllString(s, "_")
+	return strings.ToLower(s)
+}
+
+func major(version string) string {
+	return strings.SplitN(version, "-", 2)[0]
+}
+
+// ColumnName follows the loader conventions for the wide row format,
+// eg. unstruct_event_com_acme_checkout_1 and contexts_com_acme_user_1
+func (s Structure) ColumnName() string {
+	prefix := "unstruct_event"
+	if s.IsEntity {
+		prefix = "contexts"
+	}
+	return fmt.Sprintf("%s_%s_%s_%s", prefix, SnakeCase(s.Self.Vendor), SnakeCase(s.Self.Name), major(s.Self.Version))
+}
+
+// TableName is the name of the shredded table used by the redshift and
+// postgres loaders, eg. com_acme_checkout_1
+func (s Structure) TableName() string {
+	return fmt.Sprintf("%s_%s_%s", SnakeCase(s.Self.Vendor), SnakeCase(s.Self.Name), major(s.Self.Version))
+}
+
+func NewStructure(ds model.DataStructure) (Structure, error) {
+	data, err := ds.ParseData()
+	if err != nil {
+		return Structure{}, err
+	}
+	return Structure{
+		Self:     data.Self,
+		IsEntity: ds.Meta.SchemaType == "entity",
+		Fields:   fieldsOf(ds.Data),
+	}, nil
+}
+
+// StructuresFromLocal keeps the latest version of each data structure per
+// major version, as tables and columns are per major version. Structures
+// are ordered by their iglu uri so output is stable
+func StructuresFromLocal(dss map[string]model.DataStructure) ([]Structure, error) {
+	latest := map[string]Structure{}
+	versions := map[string]model.SemVersion{}
+	for f, ds := range dss {
+		s, err := NewStructure(ds)
+		if err != nil {
+			return nil, fmt.Errorf("file: %s %w", f, err)
+		}
+		version, err := model.ParseSemVer(s.Self.Version)
+		if err != nil {
+			return nil, fmt.Errorf("file: %s %w", f, err)
+		}
+		key := fmt.Sprintf("%s/%s/%s", s.Self.Vendor, s.Self.Name, major(s.Self.Version))
+		if v, ok := versions[key]; ok && model.SemVerCmp(*version, v) != 1 {
+			continue
+		}
+		latest[key] = s
+		versions[key] = *version
+	}
+
+	var res []Structure
+	for _, s := range latest {
+		res = append(res, s)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Self.IgluUri() < res[j].Self.IgluUri()
+	})
+	return res, nil
+}
+
+func fieldsOf(schema map[string]any) []Field {
+	props, _ := schema["properties"].(map[string]any)
+
+	required := map[string]bool{}
+	if req, ok := schema["required"].([]any); ok {
+		for _, r := range req {
+			if s, ok := r.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var fields []Field
+	for _, k := range keys {
+		prop, _ := props[k].(map[string]any)
+		f := Field{Key: k, Name: SnakeCase(k), Schema: prop, Required: required[k] && !isNullable(prop)}
+		if jsonType(prop) == "object" {
+			f.Children = fieldsOf(prop)
+		}
+		fields = append(fields, f)
+	}
+
+	return fields
+}
+
+func schemaTypes(schema map[string]any) []string {
+	switch t := schema["type"].(type) {
+	case string:
+		return []string{t}
+	case []any:
+		var res []string
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+	if _, ok := schema["properties"]; ok {
+		return []string{"object"}
+	}
+	return nil
+}
+
+func isNullable(schema map[string]any) bool {
+	return slices.Contains(schemaTypes(schema), "null")
+}
+
+// jsonType is the single non null type of a property or "" when the
+// property allows several incompatible types
+func jsonType(schema map[string]any) string {
+	var types []string
+	for _, t := range schemaTypes(schema) {
+		if t != "null" {
+			types = append(types, t)
+		}
+	}
+	if len(types) == 2 && slices.Contains(types, "integer") && slices.Contains(types, "number") {
+		return "number"
+	}
+	if len(types) != 1 {
+		return ""
+	}
+	return types[0]
+}
+
+func maxLength(schema map[string]any) int {
+	switch v := schema["maxLength"].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case uint64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+// ScalarType maps a json schema property to a warehouse column type.
+// Objects, arrays and mixed types fall back to the semi structured type of the dialect.
+func ScalarType(d Dialect, schema map[string]any) string {
+	format, _ := schema["format"].(string)
+	t := jsonType(schema)
+
+	switch t {
+	case "string":
+		switch format {
+		case "date-time":
+			return "TIMESTAMP"
+		case "date":
+			return "DATE"
+		}
+		l := maxLength(schema)
+		switch d {
+		case Snowflake:
+			if l > 0 {
+				return fmt.Sprintf("VARCHAR(%d)", l)
+			}
+			return "VARCHAR"
+		case BigQuery, Databricks:
+			return "STRING"
+		case Redshift:
+			if l > 0 && l <= 65535 {
+				return fmt.Sprintf("VARCHAR(%d)", l)
+			}
+			return "VARCHAR(4096)"
+		case Postgres:
+			if l > 0 {
+				return fmt.Sprintf("VARCHAR(%d)", l)
+			}
+			return "TEXT"
+		}
+	case "integer":
+		return map[Dialect]string{Snowflake: "NUMBER", BigQuery: "INT64", Databricks: "BIGINT", Redshift: "BIGINT", Postgres: "BIGINT"}[d]
+	case "number":
+		return map[Dialect]string{Snowflake: "FLOAT", BigQuery: "FLOAT64", Databricks: "DOUBLE", Redshift: "DOUBLE PRECISION", Postgres: "DOUBLE PRECISION"}[d]
+	case "boolean":
+		return "BOOLEAN"
+	}
+
+	return map[Dialect]string{Snowflake: "VARIANT", BigQuery: "JSON", Databricks: "STRING", Redshift: "VARCHAR(65535)", Postgres: "JSONB"}[d]
+}
+
+// nestedType renders struct and array types for the dialects which load
+// data structures into a single column of the events table
+func nestedType(d Dialect, f Field) string {
+	switch jsonType(f.Schema) {
+	case "object":
+		if len(f.Children) == 0 {
+			return ScalarType(d, f.Schema)
+		}
+		return structType(d, f.Children)
+	case "array":
+		items, _ := f.Schema["items"].(map[string]any)
+		if items == nil {
+			return ScalarType(d, f.Schema)
+		}
+		item := Field{Schema: items}
+		if jsonType(items) == "object" {
+			item.Children = fieldsOf(items)
+		}
+		return fmt.Sprintf("ARRAY<%s>", nestedType(d, item))
+	}
+	return ScalarType(d, f.Schema)
+}
+
+func structType(d Dialect, fields []Field) string {
+	var parts []string
+	for _, f := range fields {
+		switch d {
+		case BigQuery:
+			parts = append(parts, fmt.Sprintf("%s %s", f.Name, nestedType(d, f)))
+		default:
+			parts = append(parts, fmt.Sprintf("%s: %s", f.Name, nestedType(d, f)))
+		}
+	}
+	return fmt.Sprintf("STRUCT<%s>", strings.Join(parts, ", "))
+}
+
+// ColumnType is the type of the events table column holding the data structure
+func (s Structure) ColumnType(d Dialect) string {
+	if d == Snowflake {
+		if s.IsEntity {
+			return "ARRAY"
+		}
+		return "OBJECT"
+	}
+
+	t := ScalarType(d, nil)
+	if len(s.Fields) > 0 {
+		t = structType(d, s.Fields)
+	}
+	if s.IsEntity {
+		return fmt.Sprintf("ARRAY<%s>", t)
+	}
+	return t
+}
+
+type FlatColumn struct {
+	Name     string
+	Path     []Field
+	Type     string
+	Required bool
+}
+
+// Flatten expands nested objects into individual columns named by joining
+// the normalized property names with underscores
+func (s Structure) Flatten(d Dialect) []FlatColumn {
+	var res []FlatColumn
+	var walk func(prefix []Field, fields []Field, required bool)
+	walk = func(prefix []Field, fields []Field, required bool) {
+		for _, f := range fields {
+			path := append(slices.Clone(prefix), f)
+			if len(f.Children) > 0 {
+				walk(path, f.Children, required && f.Required)
+				continue
+			}
+			names := []string{}
+			for _, p := range path {
+				names = append(names, p.Name)
+			}
+			res = append(res, FlatColumn{
+				Name:     strings.Join(names, "_"),
+				Path:     path,
+				Type:     ScalarType(d, f.Schema),
+				Required: required && f.Required,
+			})
+		}
+	}
+	walk(nil, s.Fields, true)
+	return res
+}
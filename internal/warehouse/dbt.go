@@ -0,0 +1,178 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package warehouse
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type dbtColumn struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+}
+
+type dbtTable struct {
+	Name        string      `yaml:"name"`
+	Description string      `yaml:"description,omitempty"`
+	Columns     []dbtColumn `yaml:"columns,omitempty"`
+}
+
+type dbtSource struct {
+	Name   string     `yaml:"name"`
+	Schema string     `yaml:"schema"`
+	Tables []dbtTable `yaml:"tables"`
+}
+
+type dbtSources struct {
+	Version int         `yaml:"version"`
+	Sources []dbtSource `yaml:"sources"`
+}
+
+func DbtSources(t Target, structures []Structure) ([]byte, error) {
+	events := dbtTable{Name: t.Table, Description: "Snowplow enriched events"}
+	tables := []dbtTable{}
+
+	for _, s := range structures {
+		if t.Dialect.isShredded() {
+			var columns []dbtColumn
+			for _, c := range s.Flatten(t.Dialect) {
+				columns = append(columns, dbtColumn{Name: c.Name, Description: description(c.Path[len(c.Path)-1].Schema)})
+			}
+			tables = append(tables, dbtTable{Name: s.TableName(), Description: s.Self.IgluUri(), Columns: columns})
+		} else {
+			events.Columns = append(events.Columns, dbtColumn{Name: s.ColumnName(), Description: s.Self.IgluUri()})
+		}
+	}
+
+	source := dbtSources{
+		Version: 2,
+		Sources: []dbtSource{{
+			Name:   t.Schema,
+			Schema: t.Schema,
+			Tables: append([]dbtTable{events}, tables...),
+		}},
+	}
+
+	return yaml.Marshal(source)
+}
+
+func description(schema map[string]any) string {
+	d, _ := schema["description"].(string)
+	return d
+}
+
+func StagingModelName(s Structure) string {
+	return "stg_" + s.TableName()
+}
+
+// StagingModel selects one row per event, or one row per entity for
+// entities, with every property of the data structure as its own column
+func StagingModel(t Target, s Structure) string {
+	source := fmt.Sprintf("{{ source('%s', '%s') }}", t.Schema, t.Table)
+	columns := []string{"e.event_id", "e.collector_tstamp", "e.app_id"}
+
+	var from string
+	switch t.Dialect {
+	case Snowflake:
+		if s.IsEntity {
+			columns = append(columns, "c.index as entity_index")
+			from = fmt.Sprintf("from %s e,\n  lateral flatten(input => e.%s) c", source, s.ColumnName())
+		} else {
+			from = fmt.Sprintf("from %s e\nwhere e.%s is not null", source, s.ColumnName())
+		}
+	case BigQuery:
+		if s.IsEntity {
+			columns = append(columns, "entity_index")
+			from = fmt.Sprintf("from %s e\ncross join unnest(e.%s) as c with offset as entity_index", source, s.ColumnName())
+		} else {
+			from = fmt.Sprintf("from %s e\nwhere e.%s is not null", source, s.ColumnName())
+		}
+	case Databricks:
+		if s.IsEntity {
+			columns = append(columns, "entity_index")
+			from = fmt.Sprintf("from %s e\nlateral view posexplode(e.%s) c_exploded as entity_index, c", source, s.ColumnName())
+		} else {
+			from = fmt.Sprintf("from %s e\nwhere e.%s is not null", source, s.ColumnName())
+		}
+	case Redshift, Postgres:
+		from = fmt.Sprintf(
+			"from {{ source('%s', '%s') }} t\njoin %s e\n  on e.event_id = t.root_id\n  and e.collector_tstamp = t.root_tstamp",
+			t.Schema, s.TableName(), source,
+		)
+	}
+
+	for _, c := range s.Flatten(t.Dialect) {
+		columns = append(columns, fmt.Sprintf("%s as %s", accessor(t.Dialect, s, c), c.Name))
+	}
+
+	return fmt.Sprintf("-- %s\nselect\n  %s\n%s\n", s.Self.IgluUri(), strings.Join(columns, ",\n  "), from)
+}
+
+func accessor(d Dialect, s Structure, c FlatColumn) string {
+	switch d {
+	case Snowflake:
+		keys := []string{}
+		for _, p := range c.Path {
+			keys = append(keys, fmt.Sprintf(`"%s"`, p.Key))
+		}
+		root := "e." + s.ColumnName()
+		if s.IsEntity {
+			root = "c.value"
+		}
+		return fmt.Sprintf("%s:%s::%s", root, strings.Join(keys, "."), c.Type)
+	case BigQuery, Databricks:
+		names := []string{}
+		for _, p := range c.Path {
+			names = append(names, p.Name)
+		}
+		root := "e." + s.ColumnName()
+		if s.IsEntity {
+			root = "c"
+		}
+		return fmt.Sprintf("%s.%s", root, strings.Join(names, "."))
+	default:
+		return "t." + c.Name
+	}
+}
+
+func WriteDbtProject(dir string, t Target, structures []Structure) error {
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	sources, err := DbtSources(t, structures)
+	if err != nil {
+		return err
+	}
+
+	sourcesFile := filepath.Join(dir, "sources.yml")
+	if err := os.WriteFile(sourcesFile, sources, 0644); err != nil {
+		return err
+	}
+	slog.Debug("wrote", "file", sourcesFile)
+
+	for _, s := range structures {
+		modelFile := filepath.Join(dir, StagingModelName(s)+".sql")
+		if err := os.WriteFile(modelFile, []byte(StagingModel(t, s)), 0644); err != nil {
+			return err
+		}
+		slog.Debug("wrote", "file", modelFile)
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package warehouse
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Target struct {
+	Dialect Dialect
+	Schema  string
+	Table   string
+}
+
+func (t Target) eventsTable() string {
+	return fmt.Sprintf("%s.%s", t.Schema, t.Table)
+}
+
+var shreddedColumns = []string{
+	"schema_vendor VARCHAR(128) NOT NULL",
+	"schema_name VARCHAR(128) NOT NULL",
+	"schema_format VARCHAR(128) NOT NULL",
+	"schema_version VARCHAR(128) NOT NULL",
+	"root_id CHAR(36) NOT NULL",
+	"root_tstamp TIMESTAMP NOT NULL",
+	"ref_root VARCHAR(255) NOT NULL",
+	"ref_tree VARCHAR(1500) NOT NULL",
+	"ref_parent VARCHAR(255) NOT NULL",
+}
+
+func DDL(t Target, structures []Structure) string {
+	var b strings.Builder
+	for i, s := range structures {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "-- %s\n", s.Self.IgluUri())
+
+		switch t.Dialect {
+		case Snowflake, BigQuery:
+			fmt.Fprintf(&b, "ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s;\n", t.eventsTable(), s.ColumnName(), s.ColumnType(t.Dialect))
+		case Databricks:
+			fmt.Fprintf(&b, "ALTER TABLE %s ADD COLUMNS (%s %s);\n", t.eventsTable(), s.ColumnName(), s.ColumnType(t.Dialect))
+		case Redshift, Postgres:
+			columns := append([]string{}, shreddedColumns...)
+			for _, c := range s.Flatten(t.Dialect) {
+				col := fmt.Sprintf("%s %s", c.Name, c.Type)
+				if c.Required {
+					col += " NOT NULL"
+				}
+				columns = append(columns, col)
+			}
+			fmt.Fprintf(&b, "CREATE TABLE IF NOT EXISTS %s.%s (\n  %s\n)", t.Schema, s.TableName(), strings.Join(columns, ",\n  "))
+			if t.Dialect == Redshift {
+				b.WriteString("\nDISTSTYLE KEY\nDISTKEY (root_id)\nSORTKEY (root_tstamp)")
+			}
+			b.WriteString(";\n")
+			fmt.Fprintf(&b, "COMMENT ON TABLE %s.%s IS '%s';\n", t.Schema, s.TableName(), s.Self.IgluUri())
+		}
+	}
+	return b.String()
+}
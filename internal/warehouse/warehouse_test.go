@@ -0,0 +1,160 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package warehouse
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/snowplow/snowplow-cli/internal/model"
+)
+
+func testStructures(t *testing.T) []Structure {
+	dss := map[string]model.DataStructure{
+		"checkout.yaml": {
+			Meta: model.DataStructureMeta{SchemaType: "event"},
+			Data: map[string]any{
+				"self":     map[string]any{"vendor": "com.acme", "name": "checkoutStarted", "format": "jsonschema", "version": "1-0-2"},
+				"type":     "object",
+				"required": []any{"orderId"},
+				"properties": map[string]any{
+					"orderId": map[string]any{"type": "string", "maxLength": 36},
+					"total":   map[string]any{"type": []any{"number", "null"}},
+					"address": map[string]any{
+						"type":       "object",
+						"properties": map[string]any{"city": map[string]any{"type": "string"}},
+					},
+				},
+			},
+		},
+		"user.yaml": {
+			Meta: model.DataStructureMeta{SchemaType: "entity"},
+			Data: map[string]any{
+				"self":       map[string]any{"vendor": "com.acme", "name": "user", "format": "jsonschema", "version": "2-0-0"},
+				"type":       "object",
+				"properties": map[string]any{"id": map[string]any{"type": "integer"}},
+			},
+		},
+	}
+
+	structures, err := StructuresFromLocal(dss)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return structures
+}
+
+func Test_ColumnNames(t *testing.T) {
+	s := testStructures(t)
+
+	if s[0].ColumnName() != "unstruct_event_com_acme_checkout_started_1" {
+		t.Fatalf("unexpected column name %s", s[0].ColumnName())
+	}
+	if s[1].ColumnName() != "contexts_com_acme_user_2" {
+		t.Fatalf("unexpected column name %s", s[1].ColumnName())
+	}
+	if s[0].TableName() != "com_acme_checkout_started_1" {
+		t.Fatalf("unexpected table name %s", s[0].TableName())
+	}
+}
+
+func Test_DDLBigQuery(t *testing.T) {
+	ddl := DDL(Target{Dialect: BigQuery, Schema: "atomic", Table: "events"}, testStructures(t))
+
+	expected := []string{
+		"ALTER TABLE atomic.events ADD COLUMN IF NOT EXISTS unstruct_event_com_acme_checkout_started_1 STRUCT<address STRUCT<city STRING>, order_id STRING, total FLOAT64>;",
+		"ALTER TABLE atomic.events ADD COLUMN IF NOT EXISTS contexts_com_acme_user_2 ARRAY<STRUCT<id INT64>>;",
+	}
+	for _, e := range expected {
+		if !strings.Contains(ddl, e) {
+			t.Fatalf("expected ddl to contain %q\n%s", e, ddl)
+		}
+	}
+}
+
+func Test_DDLRedshift(t *testing.T) {
+	ddl := DDL(Target{Dialect: Redshift, Schema: "atomic", Table: "events"}, testStructures(t))
+
+	expected := []string{
+		"CREATE TABLE IF NOT EXISTS atomic.com_acme_checkout_started_1 (",
+		"  address_city VARCHAR(4096),",
+		"  order_id VARCHAR(36) NOT NULL,",
+		"  total DOUBLE PRECISION\n)",
+		"COMMENT ON TABLE atomic.com_acme_user_2 IS 'iglu:com.acme/user/jsonschema/2-0-0';",
+	}
+	for _, e := range expected {
+		if !strings.Contains(ddl, e) {
+			t.Fatalf("expected ddl to contain %q\n%s", e, ddl)
+		}
+	}
+}
+
+func Test_StagingModelSnowflake(t *testing.T) {
+	target := Target{Dialect: Snowflake, Schema: "atomic", Table: "events"}
+	s := testStructures(t)
+
+	event := StagingModel(target, s[0])
+	if !strings.Contains(event, `e.unstruct_event_com_acme_checkout_started_1:"address"."city"::VARCHAR as address_city`) {
+		t.Fatalf("unexpected model\n%s", event)
+	}
+
+	entity := StagingModel(target, s[1])
+	if !strings.Contains(entity, `lateral flatten(input => e.contexts_com_acme_user_2) c`) || !strings.Contains(entity, `c.value:"id"::NUMBER as id`) {
+		t.Fatalf("unexpected model\n%s", entity)
+	}
+}
+
+func Test_DbtSources(t *testing.T) {
+	out, err := DbtSources(Target{Dialect: Postgres, Schema: "atomic", Table: "events"}, testStructures(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, e := range []string{"name: events", "name: com_acme_checkout_started_1", "name: order_id"} {
+		if !strings.Contains(string(out), e) {
+			t.Fatalf("expected sources to contain %q\n%s", e, out)
+		}
+	}
+}
+
+func Test_StructuresFromLocalLatestPerMajor(t *testing.T) {
+	ds := func(version string, property string) model.DataStructure {
+		return model.DataStructure{
+			Meta: model.DataStructureMeta{SchemaType: "event"},
+			Data: map[string]any{
+				"self":       map[string]any{"vendor": "com.acme", "name": "checkout", "format": "jsonschema", "version": version},
+				"type":       "object",
+				"properties": map[string]any{property: map[string]any{"type": "string"}},
+			},
+		}
+	}
+	structures, err := StructuresFromLocal(map[string]model.DataStructure{
+		"com.acme/checkout/1-0-0.yaml": ds("1-0-0", "first"),
+		"com.acme/checkout/1-1-0.yaml": ds("1-1-0", "second"),
+		"com.acme/checkout/2-0-0.yaml": ds("2-0-0", "third"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(structures) != 2 || structures[0].Self.Version != "1-1-0" || structures[1].Self.Version != "2-0-0" {
+		t.Fatalf("expected the latest version per major, got %+v", structures)
+	}
+
+	ddl := DDL(Target{Dialect: Postgres, Schema: "atomic", Table: "events"}, structures)
+	if strings.Count(ddl, "CREATE TABLE IF NOT EXISTS atomic.com_acme_checkout_1 (") != 1 || strings.Contains(ddl, "first") {
+		t.Fatalf("expected a single table for major 1 from 1-1-0\n%s", ddl)
+	}
+
+	if StagingModelName(structures[0]) == StagingModelName(structures[1]) {
+		t.Fatalf("expected a staging model per major, got %s", StagingModelName(structures[0]))
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package watch
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Snapshot of the modification time and size of every file under a set of
+// paths, keyed by absolute path
+type Snapshot map[string]fileState
+
+// Take walks paths, missing paths and unreadable files are skipped
+func Take(paths []string) Snapshot {
+	s := Snapshot{}
+	for _, p := range paths {
+		_ = filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				return nil
+			}
+			s[abs] = fileState{info.ModTime(), info.Size()}
+			return nil
+		})
+	}
+	return s
+}
+
+// Changed lists the files added, modified or removed in next
+func (s Snapshot) Changed(next Snapshot) []string {
+	var changed []string
+	for f, state := range next {
+		if prev, ok := s[f]; !ok || !prev.modTime.Equal(state.modTime) || prev.size != state.size {
+			changed = append(changed, f)
+		}
+	}
+	for f := range s {
+		if _, ok := next[f]; !ok {
+			changed = append(changed, f)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Poll checks paths every interval and calls onChange with the files that
+// changed since the previous call, until ctx is done. Polling rather than
+// file system events also picks up editors replacing files on save
+func Poll(ctx context.Context, paths []string, interval time.Duration, onChange func(changed []string)) {
+	prev := Take(paths)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			next := Take(paths)
+			if changed := prev.Changed(next); len(changed) > 0 {
+				prev = next
+				onChange(changed)
+			}
+		}
+	}
+}
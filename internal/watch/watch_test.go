@@ -0,0 +1,80 @@
+/*
+Copyright (c) 2013-present Snowplow Analytics Ltd.
+All rights reserved.
+This software is made available by Snowplow Analytics, Ltd.,
+under the terms of the Snowplow Limited Use License Agreement, Version 1.0
+located at https://docs.snowplow.io/limited-use-license-1.0
+BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+*/
+
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func Test_SnapshotChanged(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.yaml")
+	b := filepath.Join(dir, "b.yaml")
+	c := filepath.Join(dir, "sub", "c.yaml")
+	for _, f := range []string{a, b} {
+		if err := os.WriteFile(f, []byte("a: 1\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	before := Take([]string{dir})
+
+	if err := os.WriteFile(a, []byte("a: 12\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(c, []byte("c: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := before.Changed(Take([]string{dir}))
+	if !slices.Equal(changed, []string{a, b, c}) {
+		t.Fatalf("unexpected changes %v", changed)
+	}
+}
+
+func Test_Poll(t *testing.T) {
+	dir := t.TempDir()
+	f := filepath.Join(dir, "a.yaml")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var got []string
+	done := make(chan struct{})
+	go func() {
+		Poll(ctx, []string{dir}, 10*time.Millisecond, func(changed []string) {
+			got = changed
+			cancel()
+		})
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(f, []byte("a: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+
+	if !slices.Equal(got, []string{f}) {
+		t.Fatalf("unexpected changes %v", got)
+	}
+}